@@ -0,0 +1,56 @@
+// A standalone fake of Mojang's authentication and session servers,
+// for running bots against local online-mode servers.
+//
+// Accounts are given as username:password:profile, eg -
+//
+//   fakemojang -addr :8080 -account john@example.com:secret:john
+//
+// Point the clients' YggdrasilClient.URL to http://<addr> and the
+// SessionClient.URL to http://<addr>/game/joinserver.jsp.
+package main
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"httphandlers"
+	"log"
+	"net/http"
+	"strings"
+)
+
+type accountList []httphandlers.MojangAccount
+
+func (l *accountList) String() string {
+	return fmt.Sprintf("%d accounts", len(*l))
+}
+
+func (l *accountList) Set(value string) error {
+	parts := strings.SplitN(value, ":", 3)
+	if len(parts) != 3 {
+		return fmt.Errorf("Expected username:password:profile, got %#v", value)
+	}
+	// profile ids only need to be stable between runs
+	hasher := md5.New()
+	hasher.Write([]byte(parts[2]))
+	*l = append(*l, httphandlers.MojangAccount{
+		Username: parts[0],
+		Password: parts[1],
+		Profiles: []httphandlers.MojangProfile{{
+			Id:   hex.EncodeToString(hasher.Sum(nil)),
+			Name: parts[2],
+		}},
+	})
+	return nil
+}
+
+func main() {
+	var accounts accountList
+	addr := flag.String("addr", "localhost:8080", "The address to listen on")
+	flag.Var(&accounts, "account", "An account as username:password:profile. Can be repeated")
+	flag.Parse()
+
+	log.Printf("Serving %s on %s", accounts.String(), *addr)
+	log.Fatal(http.ListenAndServe(*addr, httphandlers.NewMojangHandler(accounts...)))
+}
@@ -0,0 +1,318 @@
+package httphandlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// A game profile owned by a MojangAccount. The profile's name is the
+// player's in-game name.
+type MojangProfile struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// An account known to the MojangHandler.
+type MojangAccount struct {
+	Username string // the login name, usually an email
+	Password string
+	Profiles []MojangProfile // the first profile is selected by default
+}
+
+type mojangToken struct {
+	ClientToken string
+	Account     *MojangAccount
+	Profile     MojangProfile
+}
+
+// A stand-in for Mojang's authentication (Yggdrasil) and session
+// servers. Use it with net/http/httptest or run it standalone with the
+// fakemojang command.
+//
+// The following endpoints are supported:
+//
+//   POST /authenticate
+//   POST /refresh
+//   POST /validate
+//   POST /invalidate
+//   POST /signout
+//   GET  /game/joinserver.jsp
+//   GET  /game/checkserver.jsp
+//
+// Access tokens are random and only live as long as the handler.
+type MojangHandler struct {
+	mutex    sync.Mutex
+	accounts map[string]*MojangAccount
+	tokens   map[string]*mojangToken // by access token
+	joins    map[string]string       // server hashes, by profile name
+}
+
+// Creates a new fake Mojang service that accepts the given accounts.
+func NewMojangHandler(accounts ...MojangAccount) *MojangHandler {
+	h := &MojangHandler{
+		accounts: make(map[string]*MojangAccount),
+		tokens:   make(map[string]*mojangToken),
+		joins:    make(map[string]string),
+	}
+	for _, a := range accounts {
+		h.AddAccount(a)
+	}
+	return h
+}
+
+// Adds or replaces an account, keyed by its username.
+func (h *MojangHandler) AddAccount(a MojangAccount) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.accounts[a.Username] = &a
+}
+
+// Returns true if the given player has joined the server with the
+// given server hash through joinserver.jsp.
+func (h *MojangHandler) HasJoined(name, serverHash string) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	hash, ok := h.joins[name]
+	return ok && hash == serverHash
+}
+
+func (h *MojangHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	// we can't use URLHandler, since it matches the query string
+	switch fmt.Sprintf("%s %s", r.Method, r.URL.Path) {
+	case "POST /authenticate":
+		h.authenticate(w, r)
+	case "POST /refresh":
+		h.refresh(w, r)
+	case "POST /validate":
+		h.validate(w, r)
+	case "POST /invalidate":
+		h.invalidate(w, r)
+	case "POST /signout":
+		h.signout(w, r)
+	case "GET /game/joinserver.jsp":
+		h.joinServer(w, r)
+	case "GET /game/checkserver.jsp":
+		h.checkServer(w, r)
+	default:
+		writeMojangError(w, http.StatusNotFound, "Not Found", "The server has not found anything matching the request URI")
+	}
+}
+
+//////////////////////////////////////////////////////////
+
+type mojangRequest struct {
+	Username        string         `json:"username"`
+	Password        string         `json:"password"`
+	AccessToken     string         `json:"accessToken"`
+	ClientToken     string         `json:"clientToken"`
+	SelectedProfile *MojangProfile `json:"selectedProfile"`
+}
+
+type mojangResponse struct {
+	AccessToken       string          `json:"accessToken"`
+	ClientToken       string          `json:"clientToken"`
+	AvailableProfiles []MojangProfile `json:"availableProfiles,omitempty"`
+	SelectedProfile   *MojangProfile  `json:"selectedProfile,omitempty"`
+}
+
+type mojangError struct {
+	Error        string `json:"error"`
+	ErrorMessage string `json:"errorMessage"`
+	Cause        string `json:"cause,omitempty"`
+}
+
+func writeMojangError(w http.ResponseWriter, status int, err, message string) {
+	writeJSON(w, status, &mojangError{Error: err, ErrorMessage: message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func readMojangRequest(w http.ResponseWriter, r *http.Request) (*mojangRequest, bool) {
+	req := &mojangRequest{}
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		writeMojangError(w, http.StatusBadRequest, "IllegalArgumentException", "Invalid JSON")
+		return nil, false
+	}
+	return req, true
+}
+
+func randomToken() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func (h *MojangHandler) login(username, password string) *MojangAccount {
+	a, ok := h.accounts[username]
+	if !ok || a.Password != password {
+		return nil
+	}
+	return a
+}
+
+func (h *MojangHandler) issue(t *mojangToken) *mojangResponse {
+	accessToken := randomToken()
+	h.tokens[accessToken] = t
+	resp := &mojangResponse{
+		AccessToken:       accessToken,
+		ClientToken:       t.ClientToken,
+		AvailableProfiles: t.Account.Profiles,
+	}
+	if t.Profile.Id != "" {
+		profile := t.Profile
+		resp.SelectedProfile = &profile
+	}
+	return resp
+}
+
+func (h *MojangHandler) authenticate(w http.ResponseWriter, r *http.Request) {
+	req, ok := readMojangRequest(w, r)
+	if !ok {
+		return
+	}
+	a := h.login(req.Username, req.Password)
+	if a == nil {
+		writeMojangError(w, http.StatusForbidden, "ForbiddenOperationException", "Invalid credentials. Invalid username or password.")
+		return
+	}
+	t := &mojangToken{ClientToken: req.ClientToken, Account: a}
+	if t.ClientToken == "" {
+		t.ClientToken = randomToken()
+	}
+	if len(a.Profiles) > 0 {
+		t.Profile = a.Profiles[0]
+	}
+	writeJSON(w, http.StatusOK, h.issue(t))
+}
+
+func (h *MojangHandler) refresh(w http.ResponseWriter, r *http.Request) {
+	req, ok := readMojangRequest(w, r)
+	if !ok {
+		return
+	}
+	t, ok := h.tokens[req.AccessToken]
+	if !ok || t.ClientToken != req.ClientToken {
+		writeMojangError(w, http.StatusForbidden, "ForbiddenOperationException", "Invalid token.")
+		return
+	}
+	if req.SelectedProfile != nil {
+		found := false
+		for _, p := range t.Account.Profiles {
+			if p.Id == req.SelectedProfile.Id {
+				t.Profile = p
+				found = true
+			}
+		}
+		if !found {
+			writeMojangError(w, http.StatusBadRequest, "IllegalArgumentException", "Invalid profile.")
+			return
+		}
+	}
+	delete(h.tokens, req.AccessToken)
+	writeJSON(w, http.StatusOK, h.issue(t))
+}
+
+func (h *MojangHandler) validate(w http.ResponseWriter, r *http.Request) {
+	req, ok := readMojangRequest(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := h.tokens[req.AccessToken]; !ok {
+		writeMojangError(w, http.StatusForbidden, "ForbiddenOperationException", "Invalid token.")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *MojangHandler) invalidate(w http.ResponseWriter, r *http.Request) {
+	req, ok := readMojangRequest(w, r)
+	if !ok {
+		return
+	}
+	if t, ok := h.tokens[req.AccessToken]; ok && t.ClientToken == req.ClientToken {
+		delete(h.tokens, req.AccessToken)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *MojangHandler) signout(w http.ResponseWriter, r *http.Request) {
+	req, ok := readMojangRequest(w, r)
+	if !ok {
+		return
+	}
+	a := h.login(req.Username, req.Password)
+	if a == nil {
+		writeMojangError(w, http.StatusForbidden, "ForbiddenOperationException", "Invalid credentials. Invalid username or password.")
+		return
+	}
+	for accessToken, t := range h.tokens {
+		if t.Account == a {
+			delete(h.tokens, accessToken)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+//////////////////////////////////////////////////////////
+
+// Session IDs are in the form of "token:<accessToken>:<profileID>"
+//
+// Only the format of the server hash is checked here. The hash is
+// compared with the server's in checkServer.
+func (h *MojangHandler) joinServer(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	user, sessionID, serverHash := query.Get("user"), query.Get("sessionId"), query.Get("serverId")
+
+	parts := strings.Split(sessionID, ":")
+	if len(parts) != 3 || parts[0] != "token" || !isServerHash(serverHash) {
+		fmt.Fprint(w, "Bad login")
+		return
+	}
+	t, ok := h.tokens[parts[1]]
+	if !ok || t.Profile.Id != parts[2] || t.Profile.Name != user {
+		fmt.Fprint(w, "Bad login")
+		return
+	}
+	h.joins[user] = serverHash
+	fmt.Fprint(w, "OK")
+}
+
+func (h *MojangHandler) checkServer(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	hash, ok := h.joins[query.Get("user")]
+	if ok && hash == query.Get("serverId") {
+		fmt.Fprint(w, "YES")
+	} else {
+		fmt.Fprint(w, "NO")
+	}
+}
+
+// Server hashes are minecraft's signed hex digests.
+func isServerHash(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	if s == "" || len(s) > 40 {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,157 @@
+package httphandlers
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/jeffh/goexpect"
+	"io/ioutil"
+	"mc/protocol/session"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func fakeMojang() (*MojangHandler, *httptest.Server) {
+	h := NewMojangHandler(MojangAccount{
+		Username: "john@example.com",
+		Password: "secret",
+		Profiles: []MojangProfile{{Id: "badfeed", Name: "john"}, {Id: "cafe", Name: "johnny"}},
+	})
+	return h, httptest.NewServer(h)
+}
+
+func postJSON(server *httptest.Server, path string, v interface{}) (*http.Response, *mojangResponse) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	resp, err := http.Post(server.URL+path, "application/json", bytes.NewBuffer(b))
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+	body := &mojangResponse{}
+	json.NewDecoder(resp.Body).Decode(body)
+	return resp, body
+}
+
+func get(server *httptest.Server, path string, query url.Values) string {
+	resp, err := http.Get(server.URL + path + "?" + query.Encode())
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
+
+func TestMojangHandlerAuthenticatesAccounts(t *testing.T) {
+	it := NewIt(t)
+	_, server := fakeMojang()
+	defer server.Close()
+
+	resp, body := postJSON(server, "/authenticate", map[string]string{
+		"username":    "john@example.com",
+		"password":    "secret",
+		"clientToken": "client",
+	})
+	it.Expects(resp.StatusCode, ToEqual, http.StatusOK)
+	it.Expects(body.AccessToken, Not(ToEqual), "")
+	it.Expects(body.ClientToken, ToEqual, "client")
+	it.Expects(body.SelectedProfile, ToEqual, &MojangProfile{Id: "badfeed", Name: "john"})
+	it.Expects(body.AvailableProfiles, ToBeLengthOf, 2)
+
+	resp, _ = postJSON(server, "/authenticate", map[string]string{
+		"username": "john@example.com",
+		"password": "wrong",
+	})
+	it.Expects(resp.StatusCode, ToEqual, http.StatusForbidden)
+}
+
+func TestMojangHandlerRefreshesAndInvalidatesTokens(t *testing.T) {
+	it := NewIt(t)
+	_, server := fakeMojang()
+	defer server.Close()
+
+	_, auth := postJSON(server, "/authenticate", map[string]string{
+		"username": "john@example.com",
+		"password": "secret",
+	})
+	token := map[string]string{"accessToken": auth.AccessToken, "clientToken": auth.ClientToken}
+
+	resp, _ := postJSON(server, "/validate", token)
+	it.Expects(resp.StatusCode, ToEqual, http.StatusOK)
+
+	resp, refreshed := postJSON(server, "/refresh", map[string]interface{}{
+		"accessToken":     auth.AccessToken,
+		"clientToken":     auth.ClientToken,
+		"selectedProfile": MojangProfile{Id: "cafe", Name: "johnny"},
+	})
+	it.Expects(resp.StatusCode, ToEqual, http.StatusOK)
+	it.Expects(refreshed.AccessToken, Not(ToEqual), auth.AccessToken)
+	it.Expects(refreshed.SelectedProfile, ToEqual, &MojangProfile{Id: "cafe", Name: "johnny"})
+
+	// the old token is no longer valid
+	resp, _ = postJSON(server, "/validate", token)
+	it.Expects(resp.StatusCode, ToEqual, http.StatusForbidden)
+
+	token["accessToken"] = refreshed.AccessToken
+	resp, _ = postJSON(server, "/invalidate", token)
+	it.Expects(resp.StatusCode, ToEqual, http.StatusOK)
+	resp, _ = postJSON(server, "/validate", token)
+	it.Expects(resp.StatusCode, ToEqual, http.StatusForbidden)
+}
+
+func TestMojangHandlerSignsOutAllTokens(t *testing.T) {
+	it := NewIt(t)
+	_, server := fakeMojang()
+	defer server.Close()
+
+	credentials := map[string]string{"username": "john@example.com", "password": "secret"}
+	_, first := postJSON(server, "/authenticate", credentials)
+	_, second := postJSON(server, "/authenticate", credentials)
+
+	resp, _ := postJSON(server, "/signout", credentials)
+	it.Expects(resp.StatusCode, ToEqual, http.StatusOK)
+	resp, _ = postJSON(server, "/validate", map[string]string{"accessToken": first.AccessToken})
+	it.Expects(resp.StatusCode, ToEqual, http.StatusForbidden)
+	resp, _ = postJSON(server, "/validate", map[string]string{"accessToken": second.AccessToken})
+	it.Expects(resp.StatusCode, ToEqual, http.StatusForbidden)
+}
+
+func TestMojangHandlerVerifiesServerJoins(t *testing.T) {
+	it := NewIt(t)
+	h, server := fakeMojang()
+	defer server.Close()
+
+	_, auth := postJSON(server, "/authenticate", map[string]string{
+		"username": "john@example.com",
+		"password": "secret",
+	})
+	hash := session.ServerHash("myServer", []byte("secret"), []byte("publicKey"))
+
+	it.Expects(get(server, "/game/joinserver.jsp", url.Values{
+		"user":      {"john"},
+		"sessionId": {"token:" + auth.AccessToken + ":badfeed"},
+		"serverId":  {hash},
+	}), ToEqual, "OK")
+	it.Expects(get(server, "/game/joinserver.jsp", url.Values{
+		"user":      {"john"},
+		"sessionId": {"token:invalid:badfeed"},
+		"serverId":  {hash},
+	}), ToEqual, "Bad login")
+
+	it.Expects(h.HasJoined("john", hash), ToBeTrue)
+	it.Expects(get(server, "/game/checkserver.jsp", url.Values{
+		"user":     {"john"},
+		"serverId": {hash},
+	}), ToEqual, "YES")
+	it.Expects(get(server, "/game/checkserver.jsp", url.Values{
+		"user":     {"john"},
+		"serverId": {"abc"},
+	}), ToEqual, "NO")
+}
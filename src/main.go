@@ -7,6 +7,7 @@ import (
 	"mc/protocol"
 	"mc/simulator"
 	"net"
+	"os"
 	"time"
 )
 
@@ -29,9 +30,16 @@ func main() {
 	logger = &ax.StdoutLogger{}
 	logger = ax.Wrap(logger, ax.NewTimestampLogger(), ax.NewLockedLogger())
 	c := mc.NewClient(conn, 20, logger)
-	c.LogTraffic = true
-	//err = c.ConnectUnencrypted(host, port, "MCBot")
-	err = c.ConnectEncrypted("localhost", 1337, "MCBot")
+	c.Use(mc.NewTrafficLogger(logger))
+	//login, err := c.ConnectUnencrypted(host, port, "MCBot")
+	username := os.Getenv("MC_USERNAME")
+	if username == "" {
+		username = "MCBot"
+	}
+	login, err := c.ConnectEncrypted("localhost", 1337, &mc.Credentials{
+		Username: username,
+		Password: os.Getenv("MC_PASSWORD"),
+	})
 	panicIfError(err)
 
 	go c.ProcessInbox()
@@ -39,28 +47,26 @@ func main() {
 
 	go func() {
 		sim := simulator.NewSimulator(logger)
-		var position *protocol.PlayerPositionLookForClient
-		c.Outbox <- &protocol.ClientStatus{}
+		sim.Outbox = c.Outbox
+		sim.AutoRespawn = true
+		sim.World.CurrentPlayer.Name = c.Username
+		sim.ProcessMessage(login)
+		ticker := time.NewTicker(50 * time.Millisecond)
+		defer ticker.Stop()
 
 		for {
-			timeout := time.After(50 * time.Millisecond)
 			select {
 			case pck := <-c.Inbox:
 				switch t := pck.(type) {
 				case *protocol.KeepAlive:
 					c.Outbox <- t
-				case *protocol.PlayerPositionLookForClient:
-					position = t
-					c.Outbox <- t.PacketForServer()
 				case *protocol.Disconnect:
 					c.Exit <- true
 					return
 				}
 				sim.ProcessMessage(pck)
-			case <-timeout:
-				if position != nil {
-					c.Outbox <- position.PacketForServer()
-				}
+			case <-ticker.C:
+				sim.Tick()
 			}
 		}
 	}()
@@ -0,0 +1,66 @@
+package mc
+
+import (
+	"mc/protocol/session"
+)
+
+// The account used to join online-mode servers.
+//
+// A stored or given Session is preferred over the Password. If neither
+// is available, the client connects without authenticating, which only
+// works on offline-mode servers.
+type Credentials struct {
+	Username string // the account's username or email
+	Password string
+	Session  *session.YggdrasilSession
+}
+
+// Returns a session for the given credentials that the session server
+// will accept, or nil if the credentials have no way of getting one.
+//
+// Existing sessions are validated first and refreshed if they have
+// expired. New or refreshed sessions are saved to the TokenStore.
+//
+// Errors from yggdrasil are returned as is, so callers can check them
+// with session.YggdrasilErrorKindOf.
+func (c *Client) Authenticate(creds *Credentials) (*session.YggdrasilSession, error) {
+	s := creds.Session
+	if s == nil && c.TokenStore != nil {
+		stored, err := c.TokenStore.LoadSession(creds.Username)
+		if err != nil {
+			return nil, err
+		}
+		s = stored
+	}
+
+	if s != nil {
+		if err := c.Yggdrasil.Validate(s); err == nil {
+			return s, nil
+		}
+		err := c.Yggdrasil.Refresh(s)
+		if err == nil {
+			return s, c.saveSession(creds.Username, s)
+		}
+		if creds.Password == "" {
+			return nil, err
+		}
+		c.Logger.Printf("Failed to refresh session, logging in again: %s", err)
+	}
+
+	if creds.Password == "" {
+		return nil, nil
+	}
+
+	s, err := c.Yggdrasil.Authenticate(creds.Username, creds.Password)
+	if err != nil {
+		return nil, err
+	}
+	return s, c.saveSession(creds.Username, s)
+}
+
+func (c *Client) saveSession(username string, s *session.YggdrasilSession) error {
+	if c.TokenStore == nil {
+		return nil
+	}
+	return c.TokenStore.SaveSession(username, s)
+}
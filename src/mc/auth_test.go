@@ -0,0 +1,114 @@
+package mc
+
+import (
+	. "github.com/jeffh/goexpect"
+	"httphandlers"
+	"mc/protocol/session"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var yggdrasilResponse = map[string]interface{}{
+	"accessToken": "deadbeef",
+	"clientToken": "clientID",
+	"selectedProfile": map[string]string{
+		"id":   "badfeed",
+		"name": "john",
+	},
+}
+
+func createAuthClient(validateStatus int) (*Client, *httptest.Server, *httphandlers.RequestRecorderHandler) {
+	response := httphandlers.Required(httphandlers.NewFixtureAsJSON(yggdrasilResponse))
+	routes := httphandlers.NewURLHandler(map[string]http.Handler{
+		"POST /authenticate": response,
+		"POST /refresh":      response,
+		"POST /validate":     httphandlers.NewFixtureFromString("").WithStatusCode(validateStatus),
+	})
+	recorder := httphandlers.NewRequestRecorderHandler(routes)
+	server := httptest.NewServer(recorder)
+	c, _ := createClient()
+	c.Yggdrasil.URL = server.URL
+	c.TokenStore = session.NewMemoryTokenStore()
+	return c, server, recorder
+}
+
+func TestAuthenticateUsesValidSessions(t *testing.T) {
+	it := NewIt(t)
+	c, server, recorder := createAuthClient(http.StatusOK)
+	defer server.Close()
+
+	given := &session.YggdrasilSession{AccessToken: "token", ProfileName: "jane"}
+	s, err := c.Authenticate(&Credentials{Username: "jane@example.com", Session: given})
+	it.Must(err)
+	it.Expects(s, ToBe, given)
+	it.Expects(recorder.RequestsByPath("/refresh"), ToBeEmpty)
+	it.Expects(recorder.RequestsByPath("/authenticate"), ToBeEmpty)
+}
+
+func TestAuthenticateRefreshesAndSavesExpiredSessions(t *testing.T) {
+	it := NewIt(t)
+	c, server, recorder := createAuthClient(http.StatusForbidden)
+	defer server.Close()
+	c.TokenStore.SaveSession("john@example.com", &session.YggdrasilSession{
+		AccessToken: "expired",
+		ClientToken: "clientID",
+	})
+
+	s, err := c.Authenticate(&Credentials{Username: "john@example.com"})
+	it.Must(err)
+	expected := &session.YggdrasilSession{
+		AccessToken: "deadbeef",
+		ClientToken: "clientID",
+		ProfileID:   "badfeed",
+		ProfileName: "john",
+	}
+	it.Expects(s, ToEqual, expected)
+	it.Expects(recorder.RequestsByPath("/refresh"), ToBeLengthOf, 1)
+
+	stored, err := c.TokenStore.LoadSession("john@example.com")
+	it.Must(err)
+	it.Expects(stored, ToEqual, expected)
+}
+
+func TestAuthenticateLogsInWithPassword(t *testing.T) {
+	it := NewIt(t)
+	c, server, recorder := createAuthClient(http.StatusOK)
+	defer server.Close()
+
+	s, err := c.Authenticate(&Credentials{Username: "john@example.com", Password: "secret"})
+	it.Must(err)
+	it.Expects(s.SessionID(), ToEqual, "token:deadbeef:badfeed")
+	it.Expects(recorder.RequestsByPath("/authenticate"), ToBeLengthOf, 1)
+
+	stored, err := c.TokenStore.LoadSession("john@example.com")
+	it.Must(err)
+	it.Expects(stored, ToEqual, s)
+}
+
+func TestAuthenticateWithoutPasswordOrSessionIsOffline(t *testing.T) {
+	it := NewIt(t)
+	c, server, recorder := createAuthClient(http.StatusOK)
+	defer server.Close()
+
+	s, err := c.Authenticate(&Credentials{Username: "MCBot"})
+	it.Must(err)
+	it.Expects(s, ToBeNil)
+	it.Expects(recorder.Requests, ToBeEmpty)
+}
+
+func TestConnectEncryptedReturnsYggdrasilErrors(t *testing.T) {
+	it := NewIt(t)
+	server := httptest.NewServer(httphandlers.NewMojangHandler(httphandlers.MojangAccount{
+		Username: "john@example.com",
+		Password: "secret",
+		Profiles: []httphandlers.MojangProfile{{Id: "badfeed", Name: "john"}},
+	}))
+	defer server.Close()
+	c, buf := createClient()
+	c.Yggdrasil.URL = server.URL
+
+	_, err := c.ConnectEncrypted("localhost", 25565, &Credentials{Username: "john@example.com", Password: "wrong"})
+	it.Expects(session.YggdrasilErrorKindOf(err), ToEqual, session.InvalidCredentialsError)
+	it.Expects(buf.Len(), ToEqual, 0)
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"mc/protocol"
 	"mc/protocol/session"
+	"sync"
 	//"unicode/utf16"
 )
 
@@ -15,112 +16,210 @@ type Client struct {
 	Inbox         chan interface{}
 	Logger        ax.Logger
 	Exit          chan bool
-	LogTraffic    bool
 	AutoKeepAlive bool
+	Options       ClientOptions // sent to the server after connecting
+
+	// Used by ConnectEncrypted to authenticate and join servers.
+	Yggdrasil     *session.YggdrasilClient
+	SessionClient session.Client
+	TokenStore    session.TokenStore        // optional, persists sessions between runs
+	Session       *session.YggdrasilSession // set after authenticating
+	Username      string                    // the name the client logged in with
+	LoginRequest  *protocol.LoginRequest    // the server's reply to logging in
+
+	stream      io.ReadWriteCloser
+	closed      chan bool
+	closeOnce   sync.Once
+	middlewares []Middleware
+	reader      PacketReader
+	writer      PacketWriter
 }
 
 func NewClient(stream io.ReadWriteCloser, msgBuffer int, l ax.Logger) *Client {
 	reader := protocol.NewReader(stream, protocol.ClientPacketMapper, nil, l)
 	writer := protocol.NewWriter(stream, protocol.ClientPacketMapper, nil, l)
-	return &Client{
+	c := &Client{
 		Connection:    protocol.NewConnection(reader, writer),
 		Outbox:        make(chan interface{}, msgBuffer),
 		Inbox:         make(chan interface{}, msgBuffer),
 		Logger:        ax.Wrap(ax.Use(l), ax.NewPrefixLogger("[client] ")),
-		Exit:          make(chan bool),
+		Exit:          make(chan bool, 1),
 		AutoKeepAlive: true,
+		Options:       DefaultClientOptions(),
+		Yggdrasil:     session.NewYggdrasilClient(),
+		SessionClient: session.NewSessionClient(),
+		stream:        stream,
+		closed:        make(chan bool),
 	}
+	c.buildPipeline()
+	return c
 }
 
-func (c *Client) performConnect(hostname string, port int32, username string, useEncryption bool) (err error) {
+// Adds middlewares to the packet pipeline used by ReadPacket and
+// WritePacket. Logging in also goes through the pipeline.
+//
+// The first middleware added is the outermost one: it sees outgoing
+// packets first and incoming packets last. Use NewTrafficLogger to log
+// every packet.
+//
+// Use should be called before connecting.
+func (c *Client) Use(m ...Middleware) {
+	c.middlewares = append(c.middlewares, m...)
+	c.buildPipeline()
+}
+
+func (c *Client) buildPipeline() {
+	reader := PacketReader(c.Connection.ReadPacket)
+	writer := PacketWriter(c.Connection.WritePacket)
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		reader = c.middlewares[i].WrapReader(reader)
+		writer = c.middlewares[i].WrapWriter(writer)
+	}
+	c.reader = reader
+	c.writer = writer
+}
+
+func (c *Client) performConnect(hostname string, port int32, username, sessionID string, useEncryption bool) (*protocol.LoginRequest, error) {
+	c.Username = username
 	handshake := &protocol.Handshake{
 		Version:  protocol.Version,
 		Username: username,
 		Hostname: hostname,
 		Port:     port,
 	}
-	if c.LogTraffic {
-		if useEncryption {
-			c.Logger.Printf("[mc/Client] Logging in with encryption %v", handshake)
-		} else {
-			c.Logger.Printf("[mc/Client] Logging in with NO encryption %v", handshake)
-		}
-	}
-
+	var encrypt protocol.Encrypter
 	if useEncryption {
-		secret, err := protocol.GenerateSecretKey()
-		if err != nil {
-			return ax.WrapError(err)
+		encrypt = func(conn *protocol.Connection, h *protocol.Handshake, req *protocol.EncryptionKeyRequest) error {
+			secret, err := protocol.GenerateSecretKey()
+			if err != nil {
+				return err
+			}
+			return protocol.NegotiateEncryptionThrough(c, conn, h, req, secret, sessionID, c.SessionClient)
 		}
+	}
 
-		sessionClient := session.NewSessionClient()
-		err = protocol.EstablishEncryptedConnection(c.Connection, handshake, secret, sessionClient)
-		if err != nil {
-			c.Logger.Printf("Failed to connect: %s", err)
-			return ax.WrapError(err)
-		}
-		protocol.EncryptConnection(c.Connection)
-	} else {
-		err = protocol.EstablishPlaintextConnection(c.Connection, handshake)
-		if err != nil {
-			c.Logger.Printf("Failed to connect: %s", err)
-			return ax.WrapError(err)
+	l := protocol.NewLogin(c.Connection, handshake, encrypt)
+	l.Packets = c
+	login, err := l.Run()
+	if err != nil {
+		c.Logger.Printf("Failed to connect: %s", err)
+		if _, ok := err.(*protocol.DisconnectError); ok {
+			return nil, err
 		}
+		return nil, ax.WrapError(err)
 	}
+	c.LoginRequest = login
 
 	// spawn!
-	return err
+	return login, c.applyOptions()
 }
 
-func (c *Client) ConnectUnencrypted(hostname string, port int32, username string) error {
-	return c.performConnect(hostname, port, username, false)
+// Logs in without encryption. This works with offline mode servers,
+// which may skip the encryption handshake entirely.
+//
+// Returns the server's LoginRequest, which should be passed on to the
+// simulator. A *protocol.DisconnectError is returned if the server
+// kicks the client while logging in.
+func (c *Client) ConnectUnencrypted(hostname string, port int32, username string) (*protocol.LoginRequest, error) {
+	return c.performConnect(hostname, port, username, "", false)
 }
 
-func (c *Client) ConnectEncrypted(hostname string, port int32, username string) error {
-	return c.performConnect(hostname, port, username, true)
+// Authenticates with the given credentials and then joins the server
+// using the selected profile's name.
+//
+// Returns the same values as ConnectUnencrypted.
+func (c *Client) ConnectEncrypted(hostname string, port int32, creds *Credentials) (*protocol.LoginRequest, error) {
+	s, err := c.Authenticate(creds)
+	if err != nil {
+		c.Logger.Printf("Failed to authenticate: %s", err)
+		return nil, err
+	}
+	c.Session = s
+	username, sessionID := creds.Username, ""
+	if s != nil {
+		sessionID = s.SessionID()
+		if s.ProfileName != "" {
+			username = s.ProfileName
+		}
+	}
+	return c.performConnect(hostname, port, username, sessionID, true)
+}
+
+// Closes the underlying stream and stops ProcessInbox and
+// ProcessOutbox. It is safe to call Close more than once.
+func (c *Client) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		err = c.stream.Close()
+	})
+	return err
 }
 
+// Notifies the Exit channel without blocking. Only the first
+// notification is kept until someone receives it.
+func (c *Client) signalExit() {
+	select {
+	case c.Exit <- true:
+	default:
+	}
+}
+
+// Reads packets from the connection into the Inbox until reading fails
+// or the client is closed.
 func (c *Client) ProcessInbox() {
 	for {
 		p, err := c.ReadPacket()
-		if err == nil {
-			c.Inbox <- p
-		} else {
-			c.Logger.Printf("Failed to read packet: %s", ax.WrapError(err))
-			panic(err)
-			if err == io.EOF {
-				c.Exit <- true
-				return
+		if err != nil {
+			select {
+			case <-c.closed:
+			default:
+				c.Logger.Printf("Failed to read packet: %s", ax.WrapError(err))
 			}
+			c.signalExit()
+			return
+		}
+		select {
+		case c.Inbox <- p:
+		case <-c.closed:
+			return
 		}
 	}
 }
 
+// Writes packets from the Outbox to the connection until the Outbox
+// is closed, writing hits EOF or the client is closed.
 func (c *Client) ProcessOutbox() {
 	for {
-		p, ok := <-c.Outbox
+		var p interface{}
+		var ok bool
+		select {
+		case p, ok = <-c.Outbox:
+		case <-c.closed:
+			return
+		}
 		if !ok {
 			c.Logger.Printf("Outbox closed")
-			c.Exit <- true
+			c.signalExit()
 			return
 		}
 		err := c.WritePacket(p)
 		if err != nil {
 			c.Logger.Printf("Failed to write struct (%#v): %s", p, err)
 			if err == io.EOF {
-				c.Exit <- true
+				c.signalExit()
 				return
 			}
 		}
 	}
 }
 
+// Writes a packet through the middleware pipeline.
 func (c *Client) WritePacket(v interface{}) error {
-	err := c.Connection.WritePacket(v)
-	return err
+	return c.writer(v)
 }
 
+// Reads a packet through the middleware pipeline.
 func (c *Client) ReadPacket() (interface{}, error) {
-	p, err := c.Connection.ReadPacket()
-	return p, err
+	return c.reader()
 }
@@ -0,0 +1,59 @@
+package geometry
+
+import (
+	"math"
+)
+
+// Yaws are 0 when facing south (+z) and turn clockwise when seen from
+// above, so 90 faces west (-x). Pitches are 90 when looking straight down.
+
+func DegreesToRadians(d float64) float64 {
+	return d * math.Pi / 180
+}
+
+func RadiansToDegrees(r float64) float64 {
+	return r * 180 / math.Pi
+}
+
+// Converts the protocol's byte angles (1/256 of a turn) into degrees,
+// from 0 to 360.
+func FromByteAngle(a int8) float32 {
+	return float32(uint8(a)) * 360 / 256
+}
+
+// Converts the protocol's byte pitches into degrees, from -180 to 180,
+// so looking up stays negative.
+func FromBytePitch(a int8) float32 {
+	return float32(a) * 360 / 256
+}
+
+// Converts degrees into the protocol's byte angles (1/256 of a turn),
+// rounding to the nearest one.
+func ToByteAngle(d float32) int8 {
+	return int8(uint8(int64(math.Floor(float64(d)*256/360 + 0.5))))
+}
+
+// Returns the same angle between -180 (inclusive) and 180 degrees.
+func WrapDegrees(d float32) float32 {
+	w := math.Mod(float64(d)+180, 360)
+	if w < 0 {
+		w += 360
+	}
+	return float32(w - 180)
+}
+
+// Returns the yaw and pitch, in degrees, to face the target from the
+// given point.
+func LookAt(from, to Vector) (yaw, pitch float32) {
+	d := to.Sub(from)
+	yaw = float32(RadiansToDegrees(math.Atan2(-d.X, d.Z)))
+	pitch = float32(-RadiansToDegrees(math.Atan2(d.Y, math.Sqrt(d.X*d.X+d.Z*d.Z))))
+	return
+}
+
+// Returns the unit vector pointing where the yaw and pitch, in degrees,
+// face.
+func Direction(yaw, pitch float32) Vector {
+	y, p := DegreesToRadians(float64(yaw)), DegreesToRadians(float64(pitch))
+	return Vector{-math.Sin(y) * math.Cos(p), -math.Sin(p), math.Cos(y) * math.Cos(p)}
+}
@@ -0,0 +1,60 @@
+package geometry
+
+import (
+	. "github.com/jeffh/goexpect"
+	"math"
+	"testing"
+)
+
+func round(v Vector) Vector {
+	r := func(f float64) float64 { return math.Floor(f*1000+0.5) / 1000 }
+	return Vector{r(v.X), r(v.Y), r(v.Z)}
+}
+
+func TestByteAngles(t *testing.T) {
+	it := NewIt(t)
+	it.Expects(FromByteAngle(0), ToEqual, float32(0))
+	it.Expects(FromByteAngle(64), ToEqual, float32(90))
+	it.Expects(FromByteAngle(-128), ToEqual, float32(180))
+	it.Expects(FromByteAngle(-64), ToEqual, float32(270))
+	it.Expects(FromBytePitch(64), ToEqual, float32(90))
+	it.Expects(FromBytePitch(-64), ToEqual, float32(-90))
+	it.Expects(FromBytePitch(-7), ToEqual, float32(-7)*360/256)
+	it.Expects(ToByteAngle(90), ToEqual, int8(64))
+	it.Expects(ToByteAngle(270), ToEqual, int8(-64))
+	it.Expects(ToByteAngle(-90), ToEqual, int8(-64))
+	it.Expects(ToByteAngle(360), ToEqual, int8(0))
+	it.Expects(ToByteAngle(1), ToEqual, int8(1))
+}
+
+func TestWrapDegrees(t *testing.T) {
+	it := NewIt(t)
+	it.Expects(WrapDegrees(90), ToEqual, float32(90))
+	it.Expects(WrapDegrees(270), ToEqual, float32(-90))
+	it.Expects(WrapDegrees(-540), ToEqual, float32(-180))
+	it.Expects(WrapDegrees(180), ToEqual, float32(-180))
+	it.Expects(WrapDegrees(-190), ToEqual, float32(170))
+}
+
+func TestLookAtFacesTheTarget(t *testing.T) {
+	it := NewIt(t)
+	origin := Vector{0, 0, 0}
+	yaw, pitch := LookAt(origin, Vector{0, 0, 1})
+	it.Expects([]float32{yaw, pitch}, ToEqual, []float32{0, 0})
+	yaw, pitch = LookAt(origin, Vector{-1, 0, 0})
+	it.Expects([]float32{yaw, pitch}, ToEqual, []float32{90, 0})
+	yaw, pitch = LookAt(origin, Vector{1, -1, 0})
+	it.Expects([]float32{yaw, pitch}, ToEqual, []float32{-90, 45})
+	yaw, pitch = LookAt(origin, Vector{0, 2, 0})
+	it.Expects(pitch, ToEqual, float32(-90))
+}
+
+func TestDirectionIsTheInverseOfLookAt(t *testing.T) {
+	it := NewIt(t)
+	it.Expects(round(Direction(0, 0)), ToEqual, Vector{0, 0, 1})
+	it.Expects(round(Direction(90, 0)), ToEqual, Vector{-1, 0, 0})
+	it.Expects(round(Direction(0, 90)), ToEqual, Vector{0, -1, 0})
+	target := Vector{3, -2, -5}
+	yaw, pitch := LookAt(Vector{}, target)
+	it.Expects(round(Direction(yaw, pitch)), ToEqual, round(target.Normalize()))
+}
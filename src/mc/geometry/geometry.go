@@ -0,0 +1,60 @@
+// Package geometry converts between the angles used by the protocol and
+// finds what a line of sight passes through.
+package geometry
+
+import (
+	"math"
+)
+
+// A point or direction, in blocks.
+type Vector struct {
+	X, Y, Z float64
+}
+
+func (v Vector) Add(o Vector) Vector {
+	return Vector{v.X + o.X, v.Y + o.Y, v.Z + o.Z}
+}
+
+func (v Vector) Sub(o Vector) Vector {
+	return Vector{v.X - o.X, v.Y - o.Y, v.Z - o.Z}
+}
+
+func (v Vector) Scale(k float64) Vector {
+	return Vector{v.X * k, v.Y * k, v.Z * k}
+}
+
+func (v Vector) Length() float64 {
+	return math.Sqrt(v.X*v.X + v.Y*v.Y + v.Z*v.Z)
+}
+
+func (v Vector) Distance(o Vector) float64 {
+	return v.Sub(o).Length()
+}
+
+// Returns the vector scaled to a length of 1. The zero vector is
+// returned as is.
+func (v Vector) Normalize() Vector {
+	if l := v.Length(); l > 0 {
+		return v.Scale(1 / l)
+	}
+	return v
+}
+
+// The position of a block.
+type Point struct {
+	X, Y, Z int32
+}
+
+// Returns the block the point is in.
+func PointAt(v Vector) Point {
+	return Point{int32(math.Floor(v.X)), int32(math.Floor(v.Y)), int32(math.Floor(v.Z))}
+}
+
+// An axis-aligned box.
+type Box struct {
+	Min, Max Vector
+}
+
+func (b Box) Center() Vector {
+	return b.Min.Add(b.Max).Scale(0.5)
+}
@@ -0,0 +1,90 @@
+package geometry
+
+import (
+	"math"
+	"mc/protocol"
+)
+
+// Returns the distance along the ray to where it enters the box, and the
+// face it enters through. The ray starts at origin and goes along the
+// unit vector dir for up to maxDistance. A ray starting inside the box
+// hits it at a distance of 0.
+func (b Box) Intersect(origin, dir Vector, maxDistance float64) (float64, protocol.Face, bool) {
+	o := [3]float64{origin.X, origin.Y, origin.Z}
+	d := [3]float64{dir.X, dir.Y, dir.Z}
+	min := [3]float64{b.Min.X, b.Min.Y, b.Min.Z}
+	max := [3]float64{b.Max.X, b.Max.Y, b.Max.Z}
+	// the faces on the negative side of each axis
+	faces := [3]protocol.Face{protocol.FaceXNeg, protocol.FaceYNeg, protocol.FaceZNeg}
+
+	enter, exit := math.Inf(-1), math.Inf(1)
+	var face protocol.Face
+	for i := range o {
+		if d[i] == 0 {
+			if o[i] < min[i] || o[i] > max[i] {
+				return 0, 0, false
+			}
+			continue
+		}
+		near, far := (min[i]-o[i])/d[i], (max[i]-o[i])/d[i]
+		f := faces[i]
+		if near > far {
+			near, far = far, near
+			f++ // the positive side
+		}
+		if near > enter {
+			enter, face = near, f
+		}
+		exit = math.Min(exit, far)
+	}
+	if enter > exit || exit < 0 || enter > maxDistance {
+		return 0, 0, false
+	}
+	return math.Max(enter, 0), face, true
+}
+
+// Calls visit with each block the ray passes through, in order, along
+// with the distance to where the ray enters it. The block the ray starts
+// in comes first, at a distance of 0. Stops when visit returns false or
+// the ray is longer than maxDistance.
+func Traverse(origin, dir Vector, maxDistance float64, visit func(p Point, distance float64) bool) {
+	start := PointAt(origin)
+	p := [3]int32{start.X, start.Y, start.Z}
+	o := [3]float64{origin.X, origin.Y, origin.Z}
+	d := [3]float64{dir.X, dir.Y, dir.Z}
+
+	// distance to the next block boundary along each axis, and between
+	// boundaries
+	var step [3]int32
+	var next, delta [3]float64
+	for i := range o {
+		switch {
+		case d[i] > 0:
+			step[i] = 1
+			next[i] = (math.Floor(o[i]) + 1 - o[i]) / d[i]
+			delta[i] = 1 / d[i]
+		case d[i] < 0:
+			step[i] = -1
+			next[i] = (math.Floor(o[i]) - o[i]) / d[i]
+			delta[i] = -1 / d[i]
+		default:
+			next[i], delta[i] = math.Inf(1), math.Inf(1)
+		}
+	}
+
+	distance := 0.0
+	for visit(Point{p[0], p[1], p[2]}, distance) {
+		axis := 0
+		for i := 1; i < 3; i++ {
+			if next[i] < next[axis] {
+				axis = i
+			}
+		}
+		distance = next[axis]
+		if distance > maxDistance {
+			return
+		}
+		p[axis] += step[axis]
+		next[axis] += delta[axis]
+	}
+}
@@ -0,0 +1,59 @@
+package geometry
+
+import (
+	. "github.com/jeffh/goexpect"
+	"mc/protocol"
+	"testing"
+)
+
+var unitBox = Box{Vector{0, 0, 0}, Vector{1, 1, 1}}
+
+func TestIntersectFindsTheFaceEntered(t *testing.T) {
+	it := NewIt(t)
+	distance, face, ok := unitBox.Intersect(Vector{0.5, 3, 0.5}, Vector{0, -1, 0}, 5)
+	it.Expects(ok, ToBeTrue)
+	it.Expects(distance, ToEqual, 2.0)
+	it.Expects(face, ToEqual, protocol.FaceYPos)
+
+	distance, face, ok = unitBox.Intersect(Vector{-1, 0.5, 0.5}, Vector{1, 0, 0}, 5)
+	it.Expects(ok, ToBeTrue)
+	it.Expects(distance, ToEqual, 1.0)
+	it.Expects(face, ToEqual, protocol.FaceXNeg)
+
+	_, face, _ = unitBox.Intersect(Vector{0.5, 0.5, 3}, Vector{0, 0, -1}, 5)
+	it.Expects(face, ToEqual, protocol.FaceZPos)
+}
+
+func TestIntersectMisses(t *testing.T) {
+	it := NewIt(t)
+	_, _, ok := unitBox.Intersect(Vector{0.5, 3, 0.5}, Vector{0, 1, 0}, 5)
+	it.Expects(ok, Not(ToBeTrue))
+	_, _, ok = unitBox.Intersect(Vector{2, 3, 0.5}, Vector{0, -1, 0}, 5)
+	it.Expects(ok, Not(ToBeTrue))
+	_, _, ok = unitBox.Intersect(Vector{0.5, 3, 0.5}, Vector{0, -1, 0}, 1.5)
+	it.Expects(ok, Not(ToBeTrue))
+
+	distance, _, ok := unitBox.Intersect(Vector{0.5, 0.5, 0.5}, Vector{0, 1, 0}, 5)
+	it.Expects(ok, ToBeTrue)
+	it.Expects(distance, ToEqual, 0.0)
+}
+
+func TestTraverseVisitsBlocksInOrder(t *testing.T) {
+	it := NewIt(t)
+	points := make([]Point, 0)
+	distances := make([]float64, 0)
+	Traverse(Vector{0.5, 0.5, 0.5}, Vector{1, 0, 0}, 2.8, func(p Point, d float64) bool {
+		points = append(points, p)
+		distances = append(distances, d)
+		return true
+	})
+	it.Expects(points, ToEqual, []Point{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}, {3, 0, 0}})
+	it.Expects(distances, ToEqual, []float64{0, 0.5, 1.5, 2.5})
+
+	points = points[:0]
+	Traverse(Vector{0.5, 0.5, 0.5}, Vector{-0.6, -0.8, 0}, 10, func(p Point, d float64) bool {
+		points = append(points, p)
+		return len(points) < 4
+	})
+	it.Expects(points, ToEqual, []Point{{0, 0, 0}, {0, -1, 0}, {-1, -1, 0}, {-1, -2, 0}})
+}
@@ -0,0 +1,273 @@
+package mc
+
+import (
+	"ax"
+	"mc/protocol"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// A function that reads the next packet from the connection.
+type PacketReader func() (interface{}, error)
+
+// A function that writes a packet to the connection.
+type PacketWriter func(v interface{}) error
+
+// Middleware intercepts the packets flowing through a Client.
+//
+// Each middleware wraps the next reader or writer in the pipeline,
+// which allows it to observe, rewrite, delay or drop packets in
+// either direction. Dropping an inbound packet is done by reading
+// the next packet instead of returning the current one. Dropping an
+// outbound packet is done by not calling the next writer.
+type Middleware interface {
+	WrapReader(next PacketReader) PacketReader
+	WrapWriter(next PacketWriter) PacketWriter
+}
+
+// Returns the name of the packet's type, such as "KeepAlive".
+func packetName(p interface{}) string {
+	t := reflect.TypeOf(p)
+	if t == nil {
+		return "<nil>"
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+//////////////////////////////////////////////////////////
+
+// A middleware built from two functions. Each function is given
+// the packet and returns the packet to pass along. Returning nil
+// drops the packet. Either function can be nil to pass packets
+// through untouched.
+//
+// This is the easiest way to build a custom policy:
+//
+//     c.Use(mc.NewPacketFilter(nil, func(p interface{}) interface{} {
+//         if _, ok := p.(*protocol.ChatMessage); ok {
+//             return nil // never chat
+//         }
+//         return p
+//     }))
+type PacketFilter struct {
+	Inbound  func(p interface{}) interface{}
+	Outbound func(p interface{}) interface{}
+}
+
+func NewPacketFilter(inbound, outbound func(p interface{}) interface{}) *PacketFilter {
+	return &PacketFilter{Inbound: inbound, Outbound: outbound}
+}
+
+func (f *PacketFilter) WrapReader(next PacketReader) PacketReader {
+	if f.Inbound == nil {
+		return next
+	}
+	return func() (interface{}, error) {
+		for {
+			p, err := next()
+			if err != nil {
+				return p, err
+			}
+			p = f.Inbound(p)
+			if p != nil {
+				return p, nil
+			}
+		}
+	}
+}
+
+func (f *PacketFilter) WrapWriter(next PacketWriter) PacketWriter {
+	if f.Outbound == nil {
+		return next
+	}
+	return func(v interface{}) error {
+		v = f.Outbound(v)
+		if v == nil {
+			return nil
+		}
+		return next(v)
+	}
+}
+
+//////////////////////////////////////////////////////////
+
+// A middleware that logs every packet read or written.
+type TrafficLogger struct {
+	Logger ax.Logger
+}
+
+func NewTrafficLogger(l ax.Logger) *TrafficLogger {
+	return &TrafficLogger{Logger: ax.Use(l)}
+}
+
+func (t *TrafficLogger) WrapReader(next PacketReader) PacketReader {
+	return func() (interface{}, error) {
+		p, err := next()
+		if err != nil {
+			t.Logger.Printf("S->C error: %s", err)
+		} else {
+			t.Logger.Printf("S->C %#v", p)
+		}
+		return p, err
+	}
+}
+
+func (t *TrafficLogger) WrapWriter(next PacketWriter) PacketWriter {
+	return func(v interface{}) error {
+		t.Logger.Printf("C->S %#v", v)
+		return next(v)
+	}
+}
+
+//////////////////////////////////////////////////////////
+
+// A middleware that counts the packets read and written, grouped by
+// the packet type's name. It is safe to query the counts while the
+// client is running.
+type PacketCounter struct {
+	mutex    sync.Mutex
+	received map[string]int
+	sent     map[string]int
+}
+
+func NewPacketCounter() *PacketCounter {
+	return &PacketCounter{
+		received: make(map[string]int),
+		sent:     make(map[string]int),
+	}
+}
+
+func (c *PacketCounter) WrapReader(next PacketReader) PacketReader {
+	return func() (interface{}, error) {
+		p, err := next()
+		if err == nil {
+			c.mutex.Lock()
+			c.received[packetName(p)]++
+			c.mutex.Unlock()
+		}
+		return p, err
+	}
+}
+
+func (c *PacketCounter) WrapWriter(next PacketWriter) PacketWriter {
+	return func(v interface{}) error {
+		err := next(v)
+		if err == nil {
+			c.mutex.Lock()
+			c.sent[packetName(v)]++
+			c.mutex.Unlock()
+		}
+		return err
+	}
+}
+
+func copyCounts(m map[string]int) map[string]int {
+	counts := make(map[string]int, len(m))
+	for k, v := range m {
+		counts[k] = v
+	}
+	return counts
+}
+
+// Returns a copy of the number of packets read for each packet type.
+func (c *PacketCounter) Received() map[string]int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return copyCounts(c.received)
+}
+
+// Returns a copy of the number of packets written for each packet type.
+func (c *PacketCounter) Sent() map[string]int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return copyCounts(c.sent)
+}
+
+// Sets all the counts back to zero.
+func (c *PacketCounter) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.received = make(map[string]int)
+	c.sent = make(map[string]int)
+}
+
+//////////////////////////////////////////////////////////
+
+// A middleware that limits the rate of outgoing packets. Writes are
+// delayed until the limit allows them, up to Burst packets can be
+// sent back-to-back.
+//
+// Packets that Bypass returns true for are never delayed. By default,
+// KeepAlives bypass the limit, since delaying them gets the client
+// kicked.
+type RateLimiter struct {
+	Interval time.Duration // minimum time between packets
+	Burst    int
+	Bypass   func(p interface{}) bool
+
+	mutex  sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+	sleep  func(time.Duration)
+}
+
+// Creates a rate limiter that allows the given number of packets per
+// duration.
+func NewRateLimiter(packets int, per time.Duration) *RateLimiter {
+	if packets < 1 {
+		panic("RateLimiter needs to allow at least one packet")
+	}
+	return &RateLimiter{
+		Interval: per / time.Duration(packets),
+		Burst:    packets,
+		Bypass:   isKeepAlive,
+		tokens:   float64(packets),
+		now:      time.Now,
+		sleep:    time.Sleep,
+	}
+}
+
+func isKeepAlive(p interface{}) bool {
+	_, ok := p.(*protocol.KeepAlive)
+	return ok
+}
+
+// Returns how long the caller needs to wait before sending a packet,
+// and takes a token.
+func (r *RateLimiter) reserve() time.Duration {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	now := r.now()
+	if !r.last.IsZero() {
+		r.tokens += float64(now.Sub(r.last)) / float64(r.Interval)
+		if r.tokens > float64(r.Burst) {
+			r.tokens = float64(r.Burst)
+		}
+	}
+	r.last = now
+	r.tokens--
+	if r.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-r.tokens * float64(r.Interval))
+}
+
+func (r *RateLimiter) WrapReader(next PacketReader) PacketReader {
+	return next
+}
+
+func (r *RateLimiter) WrapWriter(next PacketWriter) PacketWriter {
+	return func(v interface{}) error {
+		if r.Bypass == nil || !r.Bypass(v) {
+			if wait := r.reserve(); wait > 0 {
+				r.sleep(wait)
+			}
+		}
+		return next(v)
+	}
+}
@@ -0,0 +1,117 @@
+package mc
+
+import (
+	. "github.com/jeffh/goexpect"
+	"mc/protocol"
+	"testing"
+	"time"
+)
+
+func TestClientWithoutMiddlewaresReadsWhatItWrites(t *testing.T) {
+	c, _ := createClient()
+	Expect(t, c.WritePacket(&protocol.KeepAlive{ID: 42}), ToBeNil)
+
+	p, err := c.ReadPacket()
+	Expect(t, err, ToBeNil)
+	Expect(t, p, ToEqual, &protocol.KeepAlive{ID: 42})
+}
+
+func TestPacketFilterCanRewriteAndDropPackets(t *testing.T) {
+	c, _ := createClient()
+	c.Use(NewPacketFilter(func(p interface{}) interface{} {
+		if _, ok := p.(*protocol.KeepAlive); ok {
+			return nil
+		}
+		return p
+	}, func(p interface{}) interface{} {
+		if chat, ok := p.(*protocol.ChatMessage); ok {
+			return &protocol.ChatMessage{Message: "[bot] " + chat.Message}
+		}
+		return p
+	}))
+
+	Expect(t, c.WritePacket(&protocol.KeepAlive{ID: 1}), ToBeNil)
+	Expect(t, c.WritePacket(&protocol.ChatMessage{Message: "hi"}), ToBeNil)
+
+	p, err := c.ReadPacket()
+	Expect(t, err, ToBeNil)
+	Expect(t, p, ToEqual, &protocol.ChatMessage{Message: "[bot] hi"})
+}
+
+func TestMiddlewaresAreAppliedInOrder(t *testing.T) {
+	c, _ := createClient()
+	appender := func(suffix string) *PacketFilter {
+		return NewPacketFilter(func(p interface{}) interface{} {
+			chat := p.(*protocol.ChatMessage)
+			return &protocol.ChatMessage{Message: chat.Message + suffix}
+		}, func(p interface{}) interface{} {
+			chat := p.(*protocol.ChatMessage)
+			return &protocol.ChatMessage{Message: chat.Message + suffix}
+		})
+	}
+	c.Use(appender("1"), appender("2"))
+
+	Expect(t, c.WritePacket(&protocol.ChatMessage{Message: ">"}), ToBeNil)
+	p, err := c.ReadPacket()
+	Expect(t, err, ToBeNil)
+	Expect(t, p, ToEqual, &protocol.ChatMessage{Message: ">1221"})
+}
+
+func TestPacketCounterCountsByPacketType(t *testing.T) {
+	c, _ := createClient()
+	counter := NewPacketCounter()
+	c.Use(counter)
+
+	Expect(t, c.WritePacket(&protocol.KeepAlive{ID: 1}), ToBeNil)
+	Expect(t, c.WritePacket(&protocol.KeepAlive{ID: 2}), ToBeNil)
+	Expect(t, c.WritePacket(&protocol.ChatMessage{Message: "hi"}), ToBeNil)
+	_, err := c.ReadPacket()
+	Expect(t, err, ToBeNil)
+
+	Expect(t, counter.Sent(), ToEqual, map[string]int{"KeepAlive": 2, "ChatMessage": 1})
+	Expect(t, counter.Received(), ToEqual, map[string]int{"KeepAlive": 1})
+
+	counter.Reset()
+	Expect(t, counter.Sent(), ToBeEmpty)
+}
+
+func TestRateLimiterDelaysPacketsOverTheLimit(t *testing.T) {
+	c, _ := createClient()
+	now := time.Unix(0, 0)
+	slept := time.Duration(0)
+	limiter := NewRateLimiter(2, time.Second)
+	limiter.now = func() time.Time { return now }
+	limiter.sleep = func(d time.Duration) {
+		slept += d
+		now = now.Add(d)
+	}
+	c.Use(limiter)
+
+	Expect(t, c.WritePacket(&protocol.ChatMessage{Message: "1"}), ToBeNil)
+	Expect(t, c.WritePacket(&protocol.ChatMessage{Message: "2"}), ToBeNil)
+	Expect(t, slept, ToEqual, time.Duration(0))
+
+	Expect(t, c.WritePacket(&protocol.ChatMessage{Message: "3"}), ToBeNil)
+	Expect(t, slept, ToEqual, 500*time.Millisecond)
+
+	// keep alives are never delayed
+	Expect(t, c.WritePacket(&protocol.KeepAlive{ID: 1}), ToBeNil)
+	Expect(t, slept, ToEqual, 500*time.Millisecond)
+}
+
+func TestMiddlewaresSeeTheLogin(t *testing.T) {
+	c, _ := createClient()
+	counter := NewPacketCounter()
+	c.Use(counter)
+	// the server kicks the client before it can log in
+	Expect(t, c.WritePacket(&protocol.Disconnect{Reason: "The server is full!"}), ToBeNil)
+	counter.Reset()
+
+	_, err := c.ConnectUnencrypted("localhost", 25565, "MCBot")
+	Expect(t, err, ToEqual, &protocol.DisconnectError{
+		Reason: "The server is full!",
+		State:  protocol.LoginNegotiating,
+	})
+	Expect(t, counter.Sent(), ToEqual, map[string]int{"Handshake": 1})
+	Expect(t, counter.Received(), ToEqual, map[string]int{"Disconnect": 1})
+}
@@ -0,0 +1,91 @@
+package mc
+
+import (
+	"mc/protocol"
+)
+
+// The settings the client tells the server about after logging in.
+//
+// Some servers treat clients differently based on these. For example,
+// the view distance limits how many chunks the server sends.
+type ClientOptions struct {
+	Locale       string
+	ViewDistance protocol.ViewDistance
+	ChatFlags    int8 // see protocol.ChatFlags*
+	Difficulty   protocol.GameDifficulty
+	ShowCape     bool
+	Brand        string // sent on the MC|Brand channel, empty to not send it
+}
+
+// Returns the options the vanilla client uses by default.
+func DefaultClientOptions() ClientOptions {
+	return ClientOptions{
+		Locale:       "en_US",
+		ViewDistance: protocol.FarViewDistance,
+		ChatFlags:    protocol.ChatFlagsEnabled | protocol.ChatFlagsColors,
+		Difficulty:   protocol.GameDifficultyNormal,
+		ShowCape:     true,
+		Brand:        "vanilla",
+	}
+}
+
+func (o *ClientOptions) settingsPacket() *protocol.ClientSettings {
+	return &protocol.ClientSettings{
+		Locale:     o.Locale,
+		ViewDist:   o.ViewDistance,
+		ChatFlags:  o.ChatFlags,
+		Difficulty: o.Difficulty,
+		ShowCape:   o.ShowCape,
+	}
+}
+
+func (o *ClientOptions) brandPacket() *protocol.PluginMessage {
+	return &protocol.PluginMessage{
+		Channel: protocol.BrandChannel,
+		Data:    []byte(o.Brand),
+	}
+}
+
+// Sends the client's options. This is done automatically after a
+// successful connect.
+func (c *Client) applyOptions() error {
+	err := c.WritePacket(c.Options.settingsPacket())
+	if err != nil {
+		return err
+	}
+	if c.Options.Brand != "" {
+		err = c.WritePacket(c.Options.brandPacket())
+	}
+	return err
+}
+
+// Changes the client's options mid-session. Once connected, the new
+// settings are queued on the Outbox. Before connecting, the options are
+// only stored and sent after logging in.
+//
+// SetOptions is not safe to call concurrently with itself or with
+// connecting.
+func (c *Client) SetOptions(o ClientOptions) {
+	brandChanged := o.Brand != c.Options.Brand
+	c.Options = o
+	if c.LoginRequest == nil {
+		return
+	}
+	if !c.queue(o.settingsPacket()) {
+		return
+	}
+	if brandChanged && o.Brand != "" {
+		c.queue(o.brandPacket())
+	}
+}
+
+// Queues a packet on the Outbox. Returns false if the client was closed
+// before the packet was queued.
+func (c *Client) queue(p interface{}) bool {
+	select {
+	case c.Outbox <- p:
+		return true
+	case <-c.closed:
+		return false
+	}
+}
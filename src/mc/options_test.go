@@ -0,0 +1,78 @@
+package mc
+
+import (
+	. "github.com/jeffh/goexpect"
+	"mc/protocol"
+	"testing"
+)
+
+func TestClientSendsItsOptionsAfterLogin(t *testing.T) {
+	c, buf := createClient()
+	c.Options.Locale = "en_GB"
+	c.Options.ViewDistance = protocol.ShortViewDistance
+	Expect(t, c.applyOptions(), ToBeNil)
+
+	p, err := c.ReadPacket()
+	Expect(t, err, ToBeNil)
+	Expect(t, p, ToEqual, &protocol.ClientSettings{
+		Locale:     "en_GB",
+		ViewDist:   protocol.ShortViewDistance,
+		ChatFlags:  protocol.ChatFlagsColors,
+		Difficulty: protocol.GameDifficultyNormal,
+		ShowCape:   true,
+	})
+
+	p, err = c.ReadPacket()
+	Expect(t, err, ToBeNil)
+	Expect(t, p, ToEqual, &protocol.PluginMessage{
+		Channel: "MC|Brand",
+		Data:    []byte("vanilla"),
+	})
+	Expect(t, buf.Len(), ToEqual, 0)
+}
+
+func TestClientSkipsBrandWhenEmpty(t *testing.T) {
+	c, buf := createClient()
+	c.Options.Brand = ""
+	Expect(t, c.applyOptions(), ToBeNil)
+
+	p, err := c.ReadPacket()
+	Expect(t, err, ToBeNil)
+	Expect(t, p, ToEqual, c.Options.settingsPacket())
+	Expect(t, buf.Len(), ToEqual, 0)
+}
+
+func TestClientCanChangeOptionsMidSession(t *testing.T) {
+	c, _ := createClient()
+	c.LoginRequest = &protocol.LoginRequest{EntityID: 1}
+	options := DefaultClientOptions()
+	options.ViewDistance = protocol.TinyViewDistance
+	options.Brand = "mcbot"
+	c.SetOptions(options)
+
+	Expect(t, c.Options, ToEqual, options)
+	Expect(t, <-c.Outbox, ToEqual, options.settingsPacket())
+	Expect(t, <-c.Outbox, ToEqual, &protocol.PluginMessage{
+		Channel: "MC|Brand",
+		Data:    []byte("mcbot"),
+	})
+	Expect(t, len(c.Outbox), ToEqual, 0)
+}
+
+func TestClientOnlyStoresOptionsBeforeConnecting(t *testing.T) {
+	c, _ := createClient()
+	options := DefaultClientOptions()
+	options.Brand = "mcbot"
+	c.SetOptions(options)
+
+	Expect(t, c.Options, ToEqual, options)
+	Expect(t, len(c.Outbox), ToEqual, 0)
+}
+
+func TestClientStopsChangingOptionsWhenClosed(t *testing.T) {
+	c := NewClient(newClosableBuffer(), 0, nil)
+	c.LoginRequest = &protocol.LoginRequest{EntityID: 1}
+	c.Close()
+	c.SetOptions(DefaultClientOptions())
+	Expect(t, len(c.Outbox), ToEqual, 0)
+}
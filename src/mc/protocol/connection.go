@@ -29,7 +29,14 @@ import (
 	"mc/protocol/session"
 )
 
+// The server id of an EncryptionKeyRequest from an offline mode server,
+// which doesn't verify sessions.
+const OfflineServerID = "-"
+
 // Handles the handshake to a minecraft server. Uses a plaintext connection.
+//
+// This only handles servers that reply with an EncryptionKeyRequest. Use
+// Login to also handle offline mode servers.
 func EstablishPlaintextConnection(c *Connection, h *Handshake) (err error) {
 	err = c.WritePacket(h)
 	if err != nil {
@@ -49,15 +56,13 @@ func EstablishPlaintextConnection(c *Connection, h *Handshake) (err error) {
 }
 
 // Handles the handshake to a minecraft server. Secret is the shared key
-// used by both parties for encryption.
+// used by both parties for encryption. SessionID is the authenticated
+// session (see session.YggdrasilSession.SessionID) used to join the
+// server through the session client.
 //
 // The encryption upgrading of the socket stream is done
 // immediately after the connection has been established without errors.
-func EstablishEncryptedConnection(c *Connection, h *Handshake, secret []byte, sessionClient session.Client) (err error) {
-	if len(secret) != 16 {
-		panic("Secret must be 16-bytes")
-	}
-
+func EstablishEncryptedConnection(c *Connection, h *Handshake, secret []byte, sessionID string, sessionClient session.Client) (err error) {
 	err = c.WritePacket(h)
 	if err != nil {
 		return
@@ -74,11 +79,29 @@ func EstablishEncryptedConnection(c *Connection, h *Handshake, secret []byte, se
 		return
 	}
 
+	return NegotiateEncryption(c, h, ekReq, secret, sessionID, sessionClient)
+}
+
+// Responds to the server's EncryptionKeyRequest by joining the session
+// and exchanging the shared secret. The connection is encrypted once
+// the server has accepted the secret. Offline mode servers don't need a
+// session, so sessionID may be empty for them.
+func NegotiateEncryption(c *Connection, h *Handshake, ekReq *EncryptionKeyRequest, secret []byte, sessionID string, sessionClient session.Client) error {
+	return NegotiateEncryptionThrough(c, c, h, ekReq, secret, sessionID, sessionClient)
+}
+
+// Like NegotiateEncryption, but reads and writes the packets through
+// rw instead of the connection. The connection is still the one that
+// gets encrypted.
+func NegotiateEncryptionThrough(rw PacketReadWriter, c *Connection, h *Handshake, ekReq *EncryptionKeyRequest, secret []byte, sessionID string, sessionClient session.Client) (err error) {
+	if len(secret) != 16 {
+		panic("Secret must be 16-bytes")
+	}
+
 	publicKey, err := x509.ParsePKIXPublicKey(ekReq.PublicKey)
 	if err != nil {
 		return
 	}
-	fmt.Printf("PublicKey: %#v\n", publicKey)
 
 	c.Encryption.PublicKey = publicKey
 	encSecret, err := c.Encryption.encrypt(secret)
@@ -91,21 +114,25 @@ func EstablishEncryptedConnection(c *Connection, h *Handshake, secret []byte, se
 		return
 	}
 
-	// join session
-	err = sessionClient.JoinServer(session.ServerInfo{
-		Username:     h.Username,
-		SessionID:    "Hello",
-		ServerID:     ekReq.ServerID,
-		SharedSecret: secret,
-		PublicKey:    ekReq.PublicKey,
-	})
-	if err != nil {
-		return
+	// join session, which offline mode servers don't check
+	if ekReq.ServerID != OfflineServerID {
+		if sessionID == "" {
+			err = fmt.Errorf("The online mode server needs an authenticated session")
+			return
+		}
+		err = sessionClient.JoinServer(session.ServerInfo{
+			Username:     h.Username,
+			SessionID:    sessionID,
+			ServerID:     ekReq.ServerID,
+			SharedSecret: secret,
+			PublicKey:    ekReq.PublicKey,
+		})
+		if err != nil {
+			return
+		}
 	}
 
-	fmt.Printf("Secret: %#v\n", secret)
-	fmt.Printf("Token: %#v\n", ekReq.VerifyToken)
-	err = c.WritePacket(&EncryptionKeyResponse{
+	err = rw.WritePacket(&EncryptionKeyResponse{
 		SharedSecret: encSecret,
 		VerifyToken:  encToken,
 	})
@@ -113,14 +140,12 @@ func EstablishEncryptedConnection(c *Connection, h *Handshake, secret []byte, se
 		return
 	}
 	// expect response
-
-	fmt.Println("Awaiting for Encryption Key Response...")
-	p, err = c.ReadPacket()
+	p, err := rw.ReadPacket()
 	if err != nil {
 		return
 	}
 
-	_, ok = p.(*EncryptionKeyResponse)
+	_, ok := p.(*EncryptionKeyResponse)
 	if !ok {
 		err = fmt.Errorf("Expected EncryptionKeyResponse packet, but got: %#v", p)
 		return
@@ -189,6 +214,13 @@ type ReadPacketer interface {
 	UpgradeReader(ReaderFactory)
 }
 
+// The interface that wraps reading and writing packets. Connection
+// implements it.
+type PacketReadWriter interface {
+	ReadPacket() (interface{}, error)
+	WritePacket(v interface{}) error
+}
+
 // Represents the minecraft connection. It allows consumers of this type
 // to send and receive packets
 type Connection struct {
@@ -6,7 +6,9 @@ import (
 	"crypto/x509"
 	"fmt"
 	. "github.com/jeffh/goexpect"
+	"httphandlers"
 	"mc/protocol/session"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -183,7 +185,7 @@ func TestCanNegotiateEncryptedConnection(t *testing.T) {
 		Port:     25565,
 	}
 	secret := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
-	err = EstablishEncryptedConnection(c, handshake, secret, sessionRecorder)
+	err = EstablishEncryptedConnection(c, handshake, secret, "token:deadbeef:badfeed", sessionRecorder)
 
 	Expect(t, err, ToBeNil)
 
@@ -239,7 +241,7 @@ func TestCanNegotiateEncryptedConnectionWithSession(t *testing.T) {
 		Port:     25565,
 	}
 	secret := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
-	err = EstablishEncryptedConnection(c, handshake, secret, sessionRecorder)
+	err = EstablishEncryptedConnection(c, handshake, secret, "token:deadbeef:badfeed", sessionRecorder)
 
 	Expect(t, err, ToBeNil)
 
@@ -272,9 +274,50 @@ func TestCanNegotiateEncryptedConnectionWithSession(t *testing.T) {
 	serverInfo := sessionRecorder.JoinRequests[0]
 	Expect(t, serverInfo, ToEqual, session.ServerInfo{
 		Username:     "Joe Smoe",
-		SessionID:    "Hello",
+		SessionID:    "token:deadbeef:badfeed",
 		ServerID:     "hi",
 		SharedSecret: secret,
 		PublicKey:    pub,
 	})
 }
+
+// A session client that joins servers through a fake Mojang service
+// without any accounts.
+func fakeJoinServer() (*httptest.Server, *session.SessionClient) {
+	server := httptest.NewServer(httphandlers.NewMojangHandler())
+	client := session.NewSessionClient()
+	client.URL = server.URL + "/game/joinserver.jsp"
+	return server, client
+}
+
+func TestNegotiatingWithOfflineServersDoesNotNeedASession(t *testing.T) {
+	it := NewIt(t)
+	server, sessionClient := fakeJoinServer()
+	defer server.Close()
+	c, rbuf, wbuf := createConnection()
+	_, pub, err := createPPK()
+	it.Must(err)
+	rbuf.WritePacket(&EncryptionKeyResponse{})
+
+	secret := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	ekReq := &EncryptionKeyRequest{ServerID: OfflineServerID, PublicKey: pub, VerifyToken: []byte{1, 2, 3, 4}}
+	it.Must(NegotiateEncryption(c, createHandshake(), ekReq, secret, "", sessionClient))
+	it.Expects(c.IsEncrypted(), ToBeTrue)
+	it.Expects(wbuf.IsEmpty(), Not(ToBeTrue))
+}
+
+func TestNegotiatingWithOnlineServersNeedsASession(t *testing.T) {
+	it := NewIt(t)
+	server, sessionClient := fakeJoinServer()
+	defer server.Close()
+	c, _, wbuf := createConnection()
+	_, pub, err := createPPK()
+	it.Must(err)
+
+	secret := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	ekReq := &EncryptionKeyRequest{ServerID: "abc", PublicKey: pub, VerifyToken: []byte{1, 2, 3, 4}}
+	err = NegotiateEncryption(c, createHandshake(), ekReq, secret, "", sessionClient)
+	it.Expects(err, ToEqual, fmt.Errorf("The online mode server needs an authenticated session"))
+	it.Expects(c.IsEncrypted(), Not(ToBeTrue))
+	it.Expects(wbuf.IsEmpty(), ToBeTrue)
+}
@@ -36,6 +36,10 @@ func init() {
 	DefaultDataReaders.Add([]EntityMetadata{}, ProtocolReadEntityMetadataSlice)
 	DefaultDataReaders.Add(DestroyEntity{}, ProtocolReadDestroyEntity)
 	DefaultDataReaders.Add(MapChunkBulk{}, ProtocolReadMapChunkBulk)
+	DefaultDataReaders.Add(Explosion{}, ProtocolReadExplosion)
+	DefaultDataReaders.Add(OpenWindow{}, ProtocolReadOpenWindow)
+	DefaultDataReaders.Add(UpdateScore{}, ProtocolReadUpdateScore)
+	DefaultDataReaders.Add(Teams{}, ProtocolReadTeams)
 
 	DefaultDataReaders.Add(SpawnObject{}, ProtocolReadSpawnObject)           // needs test
 	DefaultDataReaders.Add(EntityProperties{}, ProtocolReadEntityProperties) // needs test
@@ -395,3 +399,129 @@ func ProtocolReadEntityProperties(r *Reader) (v interface{}, err error) {
 	}
 	return
 }
+
+// Explosions send their affected blocks as a count followed by byte
+// offsets from the explosion's (truncated) position.
+func ProtocolReadExplosion(r *Reader) (v interface{}, err error) {
+	var e Explosion
+	defer func() { v = e }()
+
+	for _, f := range []interface{}{&e.X, &e.Y, &e.Z, &e.Radius} {
+		err = r.ReadValue(f)
+		if err != nil {
+			return
+		}
+	}
+
+	var count int32
+	err = r.ReadValue(&count)
+	if err != nil {
+		return
+	}
+	origin := BlockPosition{int32(e.X), int32(e.Y), int32(e.Z)}
+	e.AffectedBlocks = make([]BlockPosition, count)
+	for i := range e.AffectedBlocks {
+		offset := make([]int8, 3)
+		err = r.ReadValue(offset)
+		if err != nil {
+			return
+		}
+		e.AffectedBlocks[i] = BlockPosition{
+			origin.X + int32(offset[0]),
+			origin.Y + int32(offset[1]),
+			origin.Z + int32(offset[2]),
+		}
+	}
+
+	for _, f := range []interface{}{&e.PlayerXVelocity, &e.PlayerYVelocity, &e.PlayerZVelocity} {
+		err = r.ReadValue(f)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Only horse windows send the entity ID of the horse.
+func ProtocolReadOpenWindow(r *Reader) (v interface{}, err error) {
+	var o OpenWindow
+	defer func() { v = o }()
+
+	err = r.ReadValue(&o.WindowID)
+	if err != nil {
+		return
+	}
+	err = r.ReadValue(&o.InventoryType)
+	if err != nil {
+		return
+	}
+	err = r.ReadDispatch(&o.Title)
+	if err != nil {
+		return
+	}
+	err = r.ReadValue(&o.NumSlots)
+	if err != nil {
+		return
+	}
+	err = r.ReadDispatch(&o.UseProvidedTitle)
+	if err != nil {
+		return
+	}
+	if o.InventoryType == WindowTypeHorse {
+		err = r.ReadValue(&o.EntityID)
+	}
+	return
+}
+
+// Removed scores don't send the objective or the value.
+func ProtocolReadUpdateScore(r *Reader) (v interface{}, err error) {
+	var u UpdateScore
+	defer func() { v = u }()
+
+	err = r.ReadDispatch(&u.ItemName)
+	if err != nil {
+		return
+	}
+	err = r.ReadValue(&u.Type)
+	if err != nil || u.Type == ScoreTypeDelete {
+		return
+	}
+	err = r.ReadDispatch(&u.ScoreName)
+	if err != nil {
+		return
+	}
+	err = r.ReadValue(&u.Value)
+	return
+}
+
+// Only creating and updating teams sends their info, and only creating
+// teams and adding or removing players sends the players.
+func ProtocolReadTeams(r *Reader) (v interface{}, err error) {
+	var t Teams
+	defer func() { v = t }()
+
+	err = r.ReadDispatch(&t.ID)
+	if err != nil {
+		return
+	}
+	err = r.ReadValue(&t.Type)
+	if err != nil {
+		return
+	}
+	if t.Type == TeamCreate || t.Type == TeamUpdate {
+		for _, s := range []*string{&t.Name, &t.Prefix, &t.Suffix} {
+			err = r.ReadDispatch(s)
+			if err != nil {
+				return
+			}
+		}
+		err = r.ReadValue(&t.FriendlyFire)
+		if err != nil {
+			return
+		}
+	}
+	if t.Type == TeamCreate || t.Type == TeamPlayerAdd || t.Type == TeamPlayerDelete {
+		err = r.ReadDispatch(&t.PlayersDelta)
+	}
+	return
+}
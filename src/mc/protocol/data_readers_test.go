@@ -339,3 +339,110 @@ func TestProtocolEntityProperties(t *testing.T) {
 		},
 	})
 }
+
+func TestProtocolExplosionReader(t *testing.T) {
+	r, b := createProtocolReader()
+	err := writeBytes(b, float64(10.5), float64(64), float64(-3.5), float32(4),
+		int32(2), int8(1), int8(0), int8(-1), int8(-2), int8(3), int8(0),
+		float32(0.5), float32(1), float32(-0.5))
+	Expect(t, err, ToBeNil)
+
+	v, err := ProtocolReadExplosion(r)
+	Expect(t, err, ToBeNil)
+	Expect(t, v, ToEqual, Explosion{
+		X:               10.5,
+		Y:               64,
+		Z:               -3.5,
+		Radius:          4,
+		AffectedBlocks:  []BlockPosition{{11, 64, -4}, {8, 67, -3}},
+		PlayerXVelocity: 0.5,
+		PlayerYVelocity: 1,
+		PlayerZVelocity: -0.5,
+	})
+	Expect(t, b.Len(), ToBe, 0)
+}
+
+func TestProtocolOpenWindowReader(t *testing.T) {
+	r, b := createProtocolReader()
+	Expect(t, writeBytes(b, int8(2), int8(WindowTypeChest), "Chest", int8(27), byte(1)), ToBeNil)
+
+	v, err := ProtocolReadOpenWindow(r)
+	Expect(t, err, ToBeNil)
+	Expect(t, v, ToEqual, OpenWindow{
+		WindowID:         2,
+		InventoryType:    WindowTypeChest,
+		Title:            "Chest",
+		NumSlots:         27,
+		UseProvidedTitle: true,
+	})
+	Expect(t, b.Len(), ToBe, 0)
+}
+
+func TestProtocolOpenWindowReaderForHorses(t *testing.T) {
+	r, b := createProtocolReader()
+	Expect(t, writeBytes(b, int8(3), int8(WindowTypeHorse), "", int8(2), byte(0), int32(42)), ToBeNil)
+
+	v, err := ProtocolReadOpenWindow(r)
+	Expect(t, err, ToBeNil)
+	Expect(t, v, ToEqual, OpenWindow{
+		WindowID:      3,
+		InventoryType: WindowTypeHorse,
+		NumSlots:      2,
+		EntityID:      42,
+	})
+	Expect(t, b.Len(), ToBe, 0)
+}
+
+func TestProtocolUpdateScoreReader(t *testing.T) {
+	r, b := createProtocolReader()
+	Expect(t, writeBytes(b, "Steve", byte(ScoreTypeCreateOrUpdate), "kills", int32(3)), ToBeNil)
+
+	v, err := ProtocolReadUpdateScore(r)
+	Expect(t, err, ToBeNil)
+	Expect(t, v, ToEqual, UpdateScore{ItemName: "Steve", Type: ScoreTypeCreateOrUpdate, ScoreName: "kills", Value: 3})
+	Expect(t, b.Len(), ToBe, 0)
+}
+
+func TestProtocolUpdateScoreReaderForRemovedScores(t *testing.T) {
+	r, b := createProtocolReader()
+	Expect(t, writeBytes(b, "Steve", byte(ScoreTypeDelete)), ToBeNil)
+
+	v, err := ProtocolReadUpdateScore(r)
+	Expect(t, err, ToBeNil)
+	Expect(t, v, ToEqual, UpdateScore{ItemName: "Steve", Type: ScoreTypeDelete})
+	Expect(t, b.Len(), ToBe, 0)
+}
+
+func TestProtocolTeamsReader(t *testing.T) {
+	r, b := createProtocolReader()
+	Expect(t, writeBytes(b, "red", byte(TeamCreate), "Red Team", "[R]", "!", byte(TeamFriendlyFireOn),
+		int16(2), "Steve", "Alex"), ToBeNil)
+
+	v, err := ProtocolReadTeams(r)
+	Expect(t, err, ToBeNil)
+	Expect(t, v, ToEqual, Teams{
+		ID:           "red",
+		Type:         TeamCreate,
+		Name:         "Red Team",
+		Prefix:       "[R]",
+		Suffix:       "!",
+		FriendlyFire: TeamFriendlyFireOn,
+		PlayersDelta: []string{"Steve", "Alex"},
+	})
+	Expect(t, b.Len(), ToBe, 0)
+}
+
+func TestProtocolTeamsReaderOnlyReadsWhatTheModeSends(t *testing.T) {
+	r, b := createProtocolReader()
+	Expect(t, writeBytes(b, "red", byte(TeamDelete)), ToBeNil)
+	v, err := ProtocolReadTeams(r)
+	Expect(t, err, ToBeNil)
+	Expect(t, v, ToEqual, Teams{ID: "red", Type: TeamDelete})
+	Expect(t, b.Len(), ToBe, 0)
+
+	Expect(t, writeBytes(b, "red", byte(TeamPlayerDelete), int16(1), "Alex"), ToBeNil)
+	v, err = ProtocolReadTeams(r)
+	Expect(t, err, ToBeNil)
+	Expect(t, v, ToEqual, Teams{ID: "red", Type: TeamPlayerDelete, PlayersDelta: []string{"Alex"}})
+	Expect(t, b.Len(), ToBe, 0)
+}
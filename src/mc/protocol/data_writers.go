@@ -21,6 +21,7 @@ func init() {
 	// since encoding/binary supports only fixed-sized data types
 	// we need to add custom parsers for the given datatypes
 	DefaultDataWriters.Add("", ProtocolWriteString) // strings
+	DefaultDataWriters.Add(LevelType(""), ProtocolWriteLevelType)
 	DefaultDataWriters.Add(true, ProtocolWriteBool) // bool
 	DefaultDataWriters.Add([]byte{}, ProtocolWriteByteSlice)
 
@@ -30,6 +31,10 @@ func init() {
 	DefaultDataWriters.Add(Slot{}, ProtocolWriteSlot)
 
 	DefaultDataWriters.Add([]EntityMetadata{}, ProtocolWriteEntityMetadataSlice)
+	DefaultDataWriters.Add(Explosion{}, ProtocolWriteExplosion)
+	DefaultDataWriters.Add(OpenWindow{}, ProtocolWriteOpenWindow)
+	DefaultDataWriters.Add(UpdateScore{}, ProtocolWriteUpdateScore)
+	DefaultDataWriters.Add(Teams{}, ProtocolWriteTeams)
 }
 
 /////////////////////////////////////////////////////////////////
@@ -89,7 +94,7 @@ func ProtocolWriteSlot(w *Writer, v interface{}) error {
 		return err
 	}
 
-	return nil
+	return w.WriteValue(slot.GzippedNBT)
 }
 
 func ProtocolWriteSlotSlice(w *Writer, v interface{}) error {
@@ -137,6 +142,10 @@ func ProtocolWriteBool(w *Writer, v interface{}) error {
 	return w.WriteValue(value)
 }
 
+func ProtocolWriteLevelType(w *Writer, v interface{}) error {
+	return ProtocolWriteString(w, string(v.(LevelType)))
+}
+
 func ProtocolWriteString(w *Writer, v interface{}) error {
 	s := v.(string)
 	size := int16(len(s))
@@ -171,3 +180,70 @@ func ProtocolWriteByteSlice(w *Writer, v interface{}) error {
 	}
 	return nil
 }
+
+func ProtocolWriteExplosion(w *Writer, v interface{}) error {
+	e := v.(Explosion)
+	for _, f := range []interface{}{e.X, e.Y, e.Z, e.Radius, int32(len(e.AffectedBlocks))} {
+		err := w.WriteValue(f)
+		if err != nil {
+			return err
+		}
+	}
+
+	origin := BlockPosition{int32(e.X), int32(e.Y), int32(e.Z)}
+	for _, b := range e.AffectedBlocks {
+		offset := []int8{int8(b.X - origin.X), int8(b.Y - origin.Y), int8(b.Z - origin.Z)}
+		err := w.WriteValue(offset)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, f := range []interface{}{e.PlayerXVelocity, e.PlayerYVelocity, e.PlayerZVelocity} {
+		err := w.WriteValue(f)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ProtocolWriteOpenWindow(w *Writer, v interface{}) error {
+	o := v.(OpenWindow)
+	fields := []interface{}{o.WindowID, o.InventoryType, o.Title, o.NumSlots, o.UseProvidedTitle}
+	if o.InventoryType == WindowTypeHorse {
+		fields = append(fields, o.EntityID)
+	}
+	return writeFields(w, fields)
+}
+
+func ProtocolWriteUpdateScore(w *Writer, v interface{}) error {
+	u := v.(UpdateScore)
+	fields := []interface{}{u.ItemName, u.Type}
+	if u.Type != ScoreTypeDelete {
+		fields = append(fields, u.ScoreName, u.Value)
+	}
+	return writeFields(w, fields)
+}
+
+func ProtocolWriteTeams(w *Writer, v interface{}) error {
+	t := v.(Teams)
+	fields := []interface{}{t.ID, t.Type}
+	if t.Type == TeamCreate || t.Type == TeamUpdate {
+		fields = append(fields, t.Name, t.Prefix, t.Suffix, t.FriendlyFire)
+	}
+	if t.Type == TeamCreate || t.Type == TeamPlayerAdd || t.Type == TeamPlayerDelete {
+		fields = append(fields, t.PlayersDelta)
+	}
+	return writeFields(w, fields)
+}
+
+func writeFields(w *Writer, fields []interface{}) error {
+	for _, f := range fields {
+		err := w.WriteDispatch(f)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
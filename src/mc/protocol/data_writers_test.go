@@ -125,6 +125,45 @@ func TestProtocolSlotWriterForEmptyGzippedNBT(t *testing.T) {
 	Expect(t, b.Len(), ToEqual, 0)
 }
 
+func TestProtocolSlotWriterWithGzippedNBT(t *testing.T) {
+	w, b := createProtocolWriter()
+	slot := Slot{
+		ID:         276,
+		Count:      1,
+		Damage:     3,
+		GzippedNBT: []byte{1, 2, 3},
+	}
+
+	err := ProtocolWriteSlot(w, slot)
+	Expect(t, err, ToBeNil)
+	var id, dmg, size int16
+	var count int8
+	data := make([]byte, 3)
+	err = readBytes(b, &id, &count, &dmg, &size, &data)
+	Expect(t, err, ToBeNil)
+	Expect(t, size, ToEqual, int16(3))
+	Expect(t, data, ToEqual, []byte{1, 2, 3})
+	Expect(t, b.Len(), ToEqual, 0)
+}
+
+func TestProtocolClickWindowWriter(t *testing.T) {
+	w, b := createProtocolWriter()
+	click := &ClickWindow{
+		WindowID:     2,
+		Slot:         5,
+		MouseButton:  ButtonRightMouse,
+		ActionNumber: 3,
+		Mode:         ClickModeShift,
+		ClickedItem:  Slot{ID: 1, Count: 64, GzippedNBT: []byte{}},
+	}
+	Expect(t, w.WritePacket(click), ToBeNil)
+
+	r := NewReader(b, ClientPacketMapper, nil, nil)
+	p, err := r.ReadPacket()
+	Expect(t, err, ToBeNil)
+	Expect(t, p, ToEqual, click)
+}
+
 func TestProtocolBoolWriter(t *testing.T) {
 	w, buf := createProtocolWriter()
 	value := true
@@ -166,3 +205,69 @@ func TestProtocolBytesWriter(t *testing.T) {
 		Expect(t, ch, ToEqual, b)
 	}
 }
+
+func TestProtocolExplosionWriter(t *testing.T) {
+	w, b := createProtocolWriter()
+	explosion := &Explosion{
+		X:               10.5,
+		Y:               64,
+		Z:               -3.5,
+		Radius:          4,
+		AffectedBlocks:  []BlockPosition{{11, 64, -4}, {8, 67, -3}},
+		PlayerXVelocity: 0.5,
+	}
+	Expect(t, w.WritePacket(explosion), ToBeNil)
+
+	r := NewReader(b, ServerPacketMapper, nil, nil)
+	p, err := r.ReadPacket()
+	Expect(t, err, ToBeNil)
+	Expect(t, p, ToEqual, explosion)
+}
+
+func TestProtocolOpenWindowWriter(t *testing.T) {
+	for _, window := range []*OpenWindow{
+		{WindowID: 2, InventoryType: WindowTypeChest, Title: "Chest", NumSlots: 27, UseProvidedTitle: true},
+		{WindowID: 3, InventoryType: WindowTypeHorse, NumSlots: 2, EntityID: 42},
+	} {
+		w, b := createProtocolWriter()
+		Expect(t, w.WritePacket(window), ToBeNil)
+
+		r := NewReader(b, ServerPacketMapper, nil, nil)
+		p, err := r.ReadPacket()
+		Expect(t, err, ToBeNil)
+		Expect(t, p, ToEqual, window)
+	}
+}
+
+func TestProtocolUpdateScoreWriter(t *testing.T) {
+	for _, score := range []*UpdateScore{
+		{ItemName: "Steve", Type: ScoreTypeCreateOrUpdate, ScoreName: "kills", Value: 3},
+		{ItemName: "Steve", Type: ScoreTypeDelete},
+	} {
+		w, b := createProtocolWriter()
+		Expect(t, w.WritePacket(score), ToBeNil)
+
+		r := NewReader(b, ServerPacketMapper, nil, nil)
+		p, err := r.ReadPacket()
+		Expect(t, err, ToBeNil)
+		Expect(t, p, ToEqual, score)
+	}
+}
+
+func TestProtocolTeamsWriter(t *testing.T) {
+	for _, team := range []*Teams{
+		{ID: "red", Type: TeamCreate, Name: "Red Team", Prefix: "[R]", Suffix: "!", PlayersDelta: []string{"Steve"}},
+		{ID: "red", Type: TeamUpdate, Name: "Reds", FriendlyFire: TeamFriendlyFireOn},
+		{ID: "red", Type: TeamPlayerAdd, PlayersDelta: []string{"Alex"}},
+		{ID: "red", Type: TeamDelete},
+	} {
+		w, b := createProtocolWriter()
+		Expect(t, w.WritePacket(team), ToBeNil)
+
+		r := NewReader(b, ServerPacketMapper, nil, nil)
+		p, err := r.ReadPacket()
+		Expect(t, err, ToBeNil)
+		Expect(t, p, ToEqual, team)
+		Expect(t, b.Len(), ToBe, 0)
+	}
+}
@@ -0,0 +1,131 @@
+package protocol
+
+import (
+	"fmt"
+)
+
+// Called when the server asks the client to encrypt the connection.
+// NegotiateEncryption does most of the work.
+type Encrypter func(c *Connection, h *Handshake, req *EncryptionKeyRequest) error
+
+// The stages of logging in to a server.
+type LoginState int
+
+const (
+	LoginHandshaking LoginState = iota // the handshake hasn't been sent
+	LoginNegotiating                   // waiting for the server's reply to the handshake
+	LoginSpawning                      // waiting for the server to spawn the player
+	LoggedIn
+)
+
+func (s LoginState) String() string {
+	switch s {
+	case LoginHandshaking:
+		return "handshaking"
+	case LoginNegotiating:
+		return "negotiating"
+	case LoginSpawning:
+		return "spawning"
+	case LoggedIn:
+		return "logged in"
+	}
+	return "unknown"
+}
+
+// Returned when the server disconnects the client while logging in. For
+// example, when the server is full or the client's version is outdated.
+type DisconnectError struct {
+	Reason string
+	State  LoginState // when the disconnect happened
+}
+
+func (e *DisconnectError) Error() string {
+	return fmt.Sprintf("Disconnected while %s: %s", e.State, e.Reason)
+}
+
+// A state machine that logs in to online and offline mode servers.
+//
+// Online mode servers reply to the handshake with an
+// EncryptionKeyRequest. After encrypting the connection, the client
+// asks to spawn and the server replies with a LoginRequest. Offline mode
+// servers may skip straight to sending the LoginRequest.
+type Login struct {
+	Connection *Connection
+	// The packets of the login are read and written through Packets,
+	// which is the Connection unless it is replaced.
+	Packets   PacketReadWriter
+	Handshake *Handshake
+	// Optional. When nil, only servers that skip encryption can be
+	// joined.
+	Encrypt  Encrypter
+	State    LoginState
+	Response *LoginRequest // set once logged in
+}
+
+func NewLogin(c *Connection, h *Handshake, encrypt Encrypter) *Login {
+	return &Login{
+		Connection: c,
+		Packets:    c,
+		Handshake:  h,
+		Encrypt:    encrypt,
+		State:      LoginHandshaking,
+	}
+}
+
+// Steps through the login until the server spawns the player.
+func (l *Login) Run() (*LoginRequest, error) {
+	for l.State != LoggedIn {
+		err := l.Step()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return l.Response, nil
+}
+
+// Advances the login by sending or receiving a single packet.
+func (l *Login) Step() error {
+	if l.State == LoginHandshaking {
+		err := l.Packets.WritePacket(l.Handshake)
+		if err != nil {
+			return err
+		}
+		l.State = LoginNegotiating
+		return nil
+	}
+	if l.State == LoggedIn {
+		return nil
+	}
+
+	p, err := l.Packets.ReadPacket()
+	if err != nil {
+		return err
+	}
+
+	switch t := p.(type) {
+	case *Disconnect:
+		return &DisconnectError{Reason: t.Reason, State: l.State}
+	case *LoginRequest:
+		l.Response = t
+		l.State = LoggedIn
+		return nil
+	case *EncryptionKeyRequest:
+		if l.State != LoginNegotiating {
+			break
+		}
+		if l.Encrypt == nil {
+			return fmt.Errorf("The server requires encryption")
+		}
+		err = l.Encrypt(l.Connection, l.Handshake, t)
+		if err != nil {
+			return err
+		}
+		err = l.Packets.WritePacket(&ClientStatus{Payload: ClientStatusInitialSpawn})
+		if err != nil {
+			return err
+		}
+		l.State = LoginSpawning
+		return nil
+	}
+	return fmt.Errorf("Unexpected packet while %s: %#v", l.State, p)
+}
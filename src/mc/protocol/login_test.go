@@ -0,0 +1,94 @@
+package protocol
+
+import (
+	"fmt"
+	. "github.com/jeffh/goexpect"
+	"testing"
+)
+
+func createHandshake() *Handshake {
+	return &Handshake{
+		Version:  Version,
+		Username: "Joe Smoe",
+		Hostname: "localhost",
+		Port:     25565,
+	}
+}
+
+func TestLoginToOfflineServer(t *testing.T) {
+	it := NewIt(t)
+	c, rbuf, wbuf := createConnection()
+	login := &LoginRequest{EntityID: 42, LevelType: DefaultLevelType}
+	rbuf.WritePacket(login)
+
+	handshake := createHandshake()
+	resp, err := NewLogin(c, handshake, nil).Run()
+	it.Must(err)
+	it.Expects(resp, ToBe, login)
+
+	it.Expects(wbuf, ToReadPacket, handshake)
+	it.Expects(wbuf.IsEmpty(), ToBeTrue)
+}
+
+func TestLoginEncryptsWhenRequested(t *testing.T) {
+	it := NewIt(t)
+	c, rbuf, wbuf := createConnection()
+	ekReq := &EncryptionKeyRequest{ServerID: "abc"}
+	login := &LoginRequest{EntityID: 42}
+	rbuf.WritePacket(ekReq)
+	rbuf.WritePacket(login)
+
+	var requested *EncryptionKeyRequest
+	handshake := createHandshake()
+	l := NewLogin(c, handshake, func(c *Connection, h *Handshake, req *EncryptionKeyRequest) error {
+		requested = req
+		return nil
+	})
+	resp, err := l.Run()
+	it.Must(err)
+	it.Expects(resp, ToBe, login)
+	it.Expects(requested, ToBe, ekReq)
+	it.Expects(l.State, ToEqual, LoggedIn)
+
+	it.Expects(wbuf, ToReadPacket, handshake)
+	it.Expects(wbuf, ToReadPacket, &ClientStatus{Payload: ClientStatusInitialSpawn})
+}
+
+func TestLoginReportsDisconnects(t *testing.T) {
+	it := NewIt(t)
+	c, rbuf, _ := createConnection()
+	rbuf.WritePacket(&EncryptionKeyRequest{ServerID: "-"})
+	rbuf.WritePacket(&Disconnect{Reason: "The server is full!"})
+
+	encrypt := func(c *Connection, h *Handshake, req *EncryptionKeyRequest) error { return nil }
+	resp, err := NewLogin(c, createHandshake(), encrypt).Run()
+	it.Expects(resp, ToBeNil)
+	it.Expects(err, ToEqual, &DisconnectError{
+		Reason: "The server is full!",
+		State:  LoginSpawning,
+	})
+}
+
+func TestLoginRejectsUnexpectedPackets(t *testing.T) {
+	it := NewIt(t)
+	c, rbuf, _ := createConnection()
+	rbuf.WritePacket(&KeepAlive{ID: 1})
+
+	l := NewLogin(c, createHandshake(), nil)
+	it.Must(l.Step())
+	it.Expects(l.Step(), Not(ToBeNil))
+	it.Expects(l.State, ToEqual, LoginNegotiating)
+}
+
+func TestLoginFailsWhenEncryptionIsRequiredButUnsupported(t *testing.T) {
+	it := NewIt(t)
+	c, rbuf, wbuf := createConnection()
+	rbuf.WritePacket(&EncryptionKeyRequest{ServerID: "abc"})
+
+	l := NewLogin(c, createHandshake(), nil)
+	_, err := l.Run()
+	it.Expects(err, ToEqual, fmt.Errorf("The server requires encryption"))
+	it.Expects(l.State, ToEqual, LoginNegotiating)
+	it.Expects(wbuf, ToReadPacket, createHandshake())
+	it.Expects(wbuf.IsEmpty(), ToBeTrue)
+}
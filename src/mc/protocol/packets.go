@@ -382,6 +382,30 @@ type MultiBlockChange struct {
 	RecordCount    int16
 	Data           Int32PrefixedBytes // see http://www.wiki.vg/Protocol#Multi_Block_Change_.280x34.29
 }
+
+// A single block change of a MultiBlockChange. Coordinates are relative
+// to the chunk column.
+type BlockChangeRecord struct {
+	X, Y, Z  uint8
+	Type     int16
+	Metadata int8
+}
+
+// Decodes the packed 4-byte records of Data.
+func (m *MultiBlockChange) Records() []BlockChangeRecord {
+	records := make([]BlockChangeRecord, 0, len(m.Data)/4)
+	for i := 0; i+4 <= len(m.Data); i += 4 {
+		r := uint32(m.Data[i])<<24 | uint32(m.Data[i+1])<<16 | uint32(m.Data[i+2])<<8 | uint32(m.Data[i+3])
+		records = append(records, BlockChangeRecord{
+			X:        uint8(r >> 28),
+			Z:        uint8(r >> 24 & 0xf),
+			Y:        uint8(r >> 16),
+			Type:     int16(r >> 4 & 0xfff),
+			Metadata: int8(r & 0xf),
+		})
+	}
+	return records
+}
 type BlockChange struct {
 	X        int32
 	Y        int8
@@ -410,7 +434,7 @@ type MapChunkBulk struct {
 type Explosion struct {
 	X, Y, Z                                           float64
 	Radius                                            float32
-	AffectedBlocks                                    []BlockPosition
+	AffectedBlocks                                    []BlockPosition // absolute positions
 	PlayerXVelocity, PlayerYVelocity, PlayerZVelocity float32
 }
 type Effect struct {
@@ -437,10 +461,12 @@ type GlobalEntity struct {
 	X, Y, Z  int32 // absolute
 }
 type OpenWindow struct {
-	WindowID      int8
-	InventoryType int8
-	Title         string
-	NumSlots      int8
+	WindowID         int8
+	InventoryType    WindowType
+	Title            string
+	NumSlots         int8
+	UseProvidedTitle bool  // otherwise the client translates the default title
+	EntityID         int32 // only sent for horses
 }
 type CloseWindow struct {
 	WindowID int8
@@ -448,10 +474,10 @@ type CloseWindow struct {
 type ClickWindow struct {
 	WindowID     int8
 	Slot         int16
-	MouseButton  MouseButton
+	MouseButton  MouseButton // or the hotbar slot, for ClickModeNumberKey
 	ActionNumber int16
-	ShiftPressed bool
-	ClickedItem  Slot
+	Mode         ClickMode
+	ClickedItem  Slot // what the slot held before the click
 }
 type SetSlot struct {
 	WindowID int8
@@ -464,7 +490,7 @@ func (s *SetSlot) IsHeld() bool {
 }
 
 type SetWindowItems struct {
-	WindowID WindowType
+	WindowID int8
 	Slots    []Slot
 }
 type UpdateWindowProperty struct {
@@ -542,10 +568,21 @@ type ClientSettings struct {
 type ClientStatus struct {
 	Payload int8 // 0 = initial spawn, 1 = respawn
 }
+
+const (
+	ClientStatusInitialSpawn int8 = 0
+	ClientStatusRespawn      int8 = 1
+)
+
 type PluginMessage struct {
 	Channel string
 	Data    []byte
 }
+
+// The plugin channel clients use to tell the server their brand
+// (eg - "vanilla").
+const BrandChannel = "MC|Brand"
+
 type EncryptionKeyResponse struct {
 	SharedSecret []byte
 	VerifyToken  []byte
@@ -585,6 +622,7 @@ type Teams struct {
 	Type         TeamType
 	Name         string               // only for create or update
 	Prefix       string               // only for create or update
+	Suffix       string               // only for create or update
 	FriendlyFire TeamFriendlyFireType // only for create or update
 	PlayersDelta []string             // only for create or add/remove players
 }
@@ -699,6 +737,14 @@ const (
 	TinyViewDistance
 )
 
+// bit flags for ClientSettings.ChatFlags
+const (
+	ChatFlagsEnabled      int8 = 0x00 // lower two bits are the chat mode
+	ChatFlagsCommandsOnly int8 = 0x01
+	ChatFlagsHidden       int8 = 0x02
+	ChatFlagsColors       int8 = 0x08
+)
+
 type GameState int8
 
 const (
@@ -842,6 +888,34 @@ const (
 	ButtonMiddleMouse
 )
 
+// The buttons of ClickModeDrag. Dragging starts and ends outside of the
+// window and adds each slot the mouse passes over.
+const (
+	ButtonStartLeftDrag MouseButton = iota
+	ButtonAddLeftDrag
+	ButtonEndLeftDrag
+	_
+	ButtonStartRightDrag
+	ButtonAddRightDrag
+	ButtonEndRightDrag
+)
+
+// The slot of clicks outside of the window.
+const SlotOutsideWindow int16 = -999
+
+// How a window was clicked, which changes what the MouseButton means.
+type ClickMode int8
+
+const (
+	ClickModeNormal      ClickMode = iota // left or right clicks
+	ClickModeShift                        // moves the stack to another part of the window
+	ClickModeNumberKey                    // swaps with a hotbar slot
+	ClickModeMiddle                       // clones the stack in creative mode
+	ClickModeDrop                         // drops one item, or the stack with the right button
+	ClickModeDrag                         // spreads the held stack over several slots
+	ClickModeDoubleClick                  // gathers items like the held stack
+)
+
 type GameDifficulty uint8
 
 const (
@@ -969,6 +1043,8 @@ const (
 	WindowTypeBeacon
 	WindowTypeAnvil
 	WindowTypeHopper
+	WindowTypeDropper
+	WindowTypeHorse
 )
 
 /////////////////////////////////////////////////////////
@@ -73,3 +73,16 @@ func TestGameModeSurvivalFlag(t *testing.T) {
 	Expect(t, gm.IsAdventure(), ToBeTrue)
 	Expect(t, gm.IsHardcore(), ToBeTrue)
 }
+
+func TestMultiBlockChangeDecodesRecords(t *testing.T) {
+	m := MultiBlockChange{
+		ChunkX:      1,
+		ChunkY:      2,
+		RecordCount: 2,
+		Data:        Int32PrefixedBytes{0x3a, 0x40, 0x01, 0x12, 0xf0, 0xff, 0x1a, 0x3f},
+	}
+	Expect(t, m.Records(), ToEqual, []BlockChangeRecord{
+		{X: 3, Y: 0x40, Z: 0xa, Type: 0x11, Metadata: 2},
+		{X: 15, Y: 0xff, Z: 0, Type: 0x1a3, Metadata: 15},
+	})
+}
@@ -0,0 +1,350 @@
+package session
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	fileStoreMagic      = "MCTS"
+	fileStoreVersion    = 1
+	fileStoreSaltSize   = 16
+	fileStoreIterations = 10000
+)
+
+var (
+	ErrWrongPassphrase = errors.New("Wrong passphrase or corrupted token store")
+	ErrNotATokenStore  = errors.New("File is not a token store")
+)
+
+// An account kept in a FileTokenStore.
+type StoredAccount struct {
+	Username string
+	Password string // optional
+	Session  *YggdrasilSession
+}
+
+// A TokenStore that keeps the sessions, and optionally the passwords,
+// of many accounts in a single file.
+//
+// The file is encrypted with AES-CTR and authenticated with an
+// HMAC-SHA256, using keys derived from a passphrase with PBKDF2. Every
+// change is written to disk immediately by replacing the file, so a
+// crash never leaves a partially written store behind.
+//
+// It is safe to use from multiple goroutines.
+type FileTokenStore struct {
+	Path string
+
+	mutex    sync.Mutex
+	salt     []byte
+	cryptKey []byte
+	macKey   []byte
+	accounts map[string]StoredAccount
+}
+
+// Opens the token store at the given path, creating an empty one if
+// the file doesn't exist.
+//
+// If client is not nil, stale sessions are refreshed (see Refresh). The
+// store is still returned if refreshing fails.
+func OpenFileTokenStore(path, passphrase string, client *YggdrasilClient) (*FileTokenStore, error) {
+	f := &FileTokenStore{
+		Path:     path,
+		accounts: make(map[string]StoredAccount),
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		f.salt = make([]byte, fileStoreSaltSize)
+		if _, err = rand.Read(f.salt); err != nil {
+			return nil, err
+		}
+		f.deriveKeys(passphrase)
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = f.decode(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if client != nil {
+		return f, f.Refresh(client)
+	}
+	return f, nil
+}
+
+// Validates every stored session, refreshing the ones that have
+// expired. Accounts that fail to refresh log in again if their password
+// is stored.
+//
+// Returns an error naming the accounts that could not be refreshed.
+func (f *FileTokenStore) Refresh(client *YggdrasilClient) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	failed := make([]string, 0)
+	changed := false
+	for username, account := range f.accounts {
+		if account.Session == nil || client.Validate(account.Session) == nil {
+			continue
+		}
+		s := *account.Session
+		err := client.Refresh(&s)
+		if err == nil {
+			account.Session = &s
+		} else if account.Password != "" {
+			account.Session, err = client.Authenticate(username, account.Password)
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%s)", username, err))
+			continue
+		}
+		f.accounts[username] = account
+		changed = true
+	}
+
+	if changed {
+		if err := f.write(); err != nil {
+			return err
+		}
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("Failed to refresh: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// Returns a copy of every stored account, sorted by username.
+func (f *FileTokenStore) Accounts() []StoredAccount {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	accounts := make([]StoredAccount, 0, len(f.accounts))
+	for _, a := range f.accounts {
+		accounts = append(accounts, a)
+	}
+	sort.Sort(byUsername(accounts))
+	return accounts
+}
+
+// Returns the stored account, or false if there isn't one.
+func (f *FileTokenStore) Account(username string) (StoredAccount, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	a, ok := f.accounts[username]
+	return a, ok
+}
+
+func (f *FileTokenStore) LoadSession(username string) (*YggdrasilSession, error) {
+	a, ok := f.Account(username)
+	if !ok || a.Session == nil {
+		return nil, nil
+	}
+	s := *a.Session
+	return &s, nil
+}
+
+func (f *FileTokenStore) SaveSession(username string, s *YggdrasilSession) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	a := f.accounts[username]
+	a.Username = username
+	copied := *s
+	a.Session = &copied
+	f.accounts[username] = a
+	return f.write()
+}
+
+// Stores the account's password, so it can log in again when its
+// session can't be refreshed.
+func (f *FileTokenStore) SavePassword(username, password string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	a := f.accounts[username]
+	a.Username = username
+	a.Password = password
+	f.accounts[username] = a
+	return f.write()
+}
+
+// Forgets everything about the account.
+func (f *FileTokenStore) Remove(username string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	delete(f.accounts, username)
+	return f.write()
+}
+
+//////////////////////////////////////////////////////////
+
+type byUsername []StoredAccount
+
+func (a byUsername) Len() int           { return len(a) }
+func (a byUsername) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byUsername) Less(i, j int) bool { return a[i].Username < a[j].Username }
+
+func (f *FileTokenStore) deriveKeys(passphrase string) {
+	key := pbkdf2([]byte(passphrase), f.salt, fileStoreIterations, 64, sha256.New)
+	f.cryptKey = key[:32]
+	f.macKey = key[32:]
+}
+
+// The file is laid out as:
+//
+//   magic | version | salt | iv | encrypted json | hmac
+//
+// The hmac covers everything before it.
+func (f *FileTokenStore) decode(data []byte, passphrase string) error {
+	headerSize := len(fileStoreMagic) + 1 + fileStoreSaltSize
+	if len(data) < headerSize+aes.BlockSize+sha256.Size || string(data[:len(fileStoreMagic)]) != fileStoreMagic {
+		return ErrNotATokenStore
+	}
+	if data[len(fileStoreMagic)] != fileStoreVersion {
+		return fmt.Errorf("Unsupported token store version: %d", data[len(fileStoreMagic)])
+	}
+	f.salt = data[len(fileStoreMagic)+1 : headerSize]
+	f.deriveKeys(passphrase)
+
+	signed, sum := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	mac := hmac.New(sha256.New, f.macKey)
+	mac.Write(signed)
+	if !hmac.Equal(mac.Sum(nil), sum) {
+		return ErrWrongPassphrase
+	}
+
+	iv := signed[headerSize : headerSize+aes.BlockSize]
+	plaintext, err := f.crypt(iv, signed[headerSize+aes.BlockSize:])
+	if err != nil {
+		return err
+	}
+	accounts := make([]StoredAccount, 0)
+	err = json.Unmarshal(plaintext, &accounts)
+	if err != nil {
+		return err
+	}
+	for _, a := range accounts {
+		f.accounts[a.Username] = a
+	}
+	return nil
+}
+
+func (f *FileTokenStore) encode() ([]byte, error) {
+	accounts := make([]StoredAccount, 0, len(f.accounts))
+	for _, a := range f.accounts {
+		accounts = append(accounts, a)
+	}
+	sort.Sort(byUsername(accounts))
+	plaintext, err := json.Marshal(accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err = rand.Read(iv); err != nil {
+		return nil, err
+	}
+	ciphertext, err := f.crypt(iv, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	b := bytes.NewBufferString(fileStoreMagic)
+	b.WriteByte(fileStoreVersion)
+	b.Write(f.salt)
+	b.Write(iv)
+	b.Write(ciphertext)
+	mac := hmac.New(sha256.New, f.macKey)
+	mac.Write(b.Bytes())
+	b.Write(mac.Sum(nil))
+	return b.Bytes(), nil
+}
+
+// Encrypts or decrypts the data, since CTR mode is symmetric.
+func (f *FileTokenStore) crypt(iv, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(f.cryptKey)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(data))
+	cipher.NewCTR(block, iv).XORKeyStream(out, data)
+	return out, nil
+}
+
+// Writes the store to a temporary file and then renames it over the
+// old one.
+func (f *FileTokenStore) write() error {
+	data, err := f.encode()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(f.Path), filepath.Base(f.Path)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(0600)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), f.Path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// PBKDF2 (RFC 2898) key derivation.
+func pbkdf2(password, salt []byte, iterations, keyLen int, h func() hash.Hash) []byte {
+	prf := hmac.New(h, password)
+	hashLen := prf.Size()
+	blocks := (keyLen + hashLen - 1) / hashLen
+
+	key := make([]byte, 0, blocks*hashLen)
+	counter := make([]byte, 4)
+	for block := 1; block <= blocks; block++ {
+		binary.BigEndian.PutUint32(counter, uint32(block))
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(counter)
+		u := prf.Sum(nil)
+		t := make([]byte, len(u))
+		copy(t, u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
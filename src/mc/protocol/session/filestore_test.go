@@ -0,0 +1,117 @@
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	. "github.com/jeffh/goexpect"
+	"httphandlers"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func tempStorePath() (string, func()) {
+	dir, err := ioutil.TempDir("", "tokenstore")
+	if err != nil {
+		panic(err)
+	}
+	return filepath.Join(dir, "accounts.dat"), func() { os.RemoveAll(dir) }
+}
+
+func TestPBKDF2MatchesTestVectors(t *testing.T) {
+	it := NewIt(t)
+	key := pbkdf2([]byte("password"), []byte("salt"), 1, 32, sha256.New)
+	it.Expects(hex.EncodeToString(key), ToEqual, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b")
+	key = pbkdf2([]byte("password"), []byte("salt"), 2, 32, sha256.New)
+	it.Expects(hex.EncodeToString(key), ToEqual, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43")
+}
+
+func TestFileTokenStorePersistsEncryptedAccounts(t *testing.T) {
+	it := NewIt(t)
+	path, cleanup := tempStorePath()
+	defer cleanup()
+
+	store, err := OpenFileTokenStore(path, "hunter2", nil)
+	it.Must(err)
+	session := &YggdrasilSession{AccessToken: "deadbeef", ProfileName: "john"}
+	it.Must(store.SaveSession("john@example.com", session))
+	it.Must(store.SavePassword("john@example.com", "secret"))
+	it.Must(store.SavePassword("jane@example.com", "password"))
+
+	data, err := ioutil.ReadFile(path)
+	it.Must(err)
+	it.Expects(strings.Contains(string(data), "deadbeef"), Not(ToBeTrue))
+	it.Expects(strings.Contains(string(data), "secret"), Not(ToBeTrue))
+
+	store, err = OpenFileTokenStore(path, "hunter2", nil)
+	it.Must(err)
+	loaded, err := store.LoadSession("john@example.com")
+	it.Must(err)
+	it.Expects(loaded, ToEqual, session)
+	it.Expects(store.Accounts(), ToEqual, []StoredAccount{
+		{Username: "jane@example.com", Password: "password"},
+		{Username: "john@example.com", Password: "secret", Session: session},
+	})
+
+	it.Must(store.Remove("jane@example.com"))
+	_, ok := store.Account("jane@example.com")
+	it.Expects(ok, Not(ToBeTrue))
+}
+
+func TestFileTokenStoreRejectsWrongPassphrases(t *testing.T) {
+	it := NewIt(t)
+	path, cleanup := tempStorePath()
+	defer cleanup()
+
+	store, err := OpenFileTokenStore(path, "hunter2", nil)
+	it.Must(err)
+	it.Must(store.SavePassword("john@example.com", "secret"))
+
+	_, err = OpenFileTokenStore(path, "hunter3", nil)
+	it.Expects(err, ToEqual, ErrWrongPassphrase)
+
+	it.Must(ioutil.WriteFile(path, []byte("plaintext"), 0600))
+	_, err = OpenFileTokenStore(path, "hunter2", nil)
+	it.Expects(err, ToEqual, ErrNotATokenStore)
+}
+
+func TestFileTokenStoreRefreshesStaleSessionsWhenOpened(t *testing.T) {
+	it := NewIt(t)
+	path, cleanup := tempStorePath()
+	defer cleanup()
+	server := httptest.NewServer(httphandlers.NewMojangHandler(httphandlers.MojangAccount{
+		Username: "john@example.com",
+		Password: "secret",
+		Profiles: []httphandlers.MojangProfile{{Id: "badfeed", Name: "john"}},
+	}))
+	defer server.Close()
+	client := NewYggdrasilClient()
+	client.URL = server.URL
+
+	store, err := OpenFileTokenStore(path, "hunter2", nil)
+	it.Must(err)
+	stale := &YggdrasilSession{AccessToken: "expired", ClientToken: "client"}
+	it.Must(store.SaveSession("john@example.com", stale))
+	it.Must(store.SavePassword("john@example.com", "secret"))
+	it.Must(store.SaveSession("jane@example.com", stale))
+
+	store, err = OpenFileTokenStore(path, "hunter2", client)
+	it.Expects(err, Not(ToBeNil))
+	it.Expects(strings.Contains(err.Error(), "jane@example.com"), ToBeTrue)
+	it.Expects(strings.Contains(err.Error(), "john@example.com"), Not(ToBeTrue))
+
+	session, err := store.LoadSession("john@example.com")
+	it.Must(err)
+	it.Expects(session.ProfileName, ToEqual, "john")
+	it.Must(client.Validate(session))
+
+	// the refreshed session was written to disk
+	store, err = OpenFileTokenStore(path, "hunter2", nil)
+	it.Must(err)
+	reloaded, err := store.LoadSession("john@example.com")
+	it.Must(err)
+	it.Expects(reloaded, ToEqual, session)
+}
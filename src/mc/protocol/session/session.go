@@ -1,6 +1,8 @@
 package session
 
 import (
+	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 )
@@ -53,10 +55,7 @@ type ServerInfo struct {
 }
 
 func (i *ServerInfo) serverHash() string {
-	b := []byte(i.ServerID)
-	b = append(b, i.SharedSecret...)
-	b = append(b, i.PublicKey...)
-	return sha1HexDigest(b)
+	return ServerHash(i.ServerID, i.SharedSecret, i.PublicKey)
 }
 
 func NewSessionClient() *SessionClient {
@@ -83,6 +82,17 @@ func (s *SessionClient) JoinServer(info ServerInfo) error {
 	query.Add(s.UserKey, info.Username)
 	query.Add(s.HashKey, info.serverHash())
 	uri.RawQuery = query.Encode()
-	_, err = s.Client.Get(uri.String())
-	return err
+	resp, err := s.Client.Get(uri.String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if string(body) != "OK" {
+		return fmt.Errorf("Failed to join server: %s", body)
+	}
+	return nil
 }
@@ -37,3 +37,37 @@ func TestSessionClientCanJoinServer(t *testing.T) {
 	it.Expects(r.URL.Query().Get("sessionId"), ToEqual, "sessionID")
 	it.Expects(r.URL.Query().Get("serverId"), ToEqual, "-f6217b3fe196685c9cfef5eea9a02125855af37")
 }
+
+func TestClientsCanJoinTheFakeMojangService(t *testing.T) {
+	it := NewIt(t)
+	mojang := httphandlers.NewMojangHandler(httphandlers.MojangAccount{
+		Username: "john@example.com",
+		Password: "secret",
+		Profiles: []httphandlers.MojangProfile{{Id: "badfeed", Name: "john"}},
+	})
+	server := httptest.NewServer(mojang)
+	defer server.Close()
+
+	yggdrasil := NewYggdrasilClient()
+	yggdrasil.URL = server.URL
+	token, err := yggdrasil.Authenticate("john@example.com", "secret")
+	it.Must(err)
+	it.Must(yggdrasil.Validate(token))
+	it.Must(yggdrasil.Refresh(token))
+	it.Must(yggdrasil.Validate(token))
+
+	client := NewSessionClient()
+	client.URL = server.URL + "/game/joinserver.jsp"
+	info := ServerInfo{
+		Username:     token.ProfileName,
+		SessionID:    token.SessionID(),
+		ServerID:     "myServer",
+		SharedSecret: []byte("secret"),
+		PublicKey:    []byte("publicKey"),
+	}
+	it.Must(client.JoinServer(info))
+	it.Expects(mojang.HasJoined("john", info.serverHash()), ToBeTrue)
+
+	info.SessionID = "token:expired:badfeed"
+	it.Expects(client.JoinServer(info), Not(ToBeNil))
+}
@@ -7,6 +7,17 @@ import (
 	"strings"
 )
 
+// Computes the server hash that clients send to joinserver.jsp and
+// servers send to checkserver.jsp.
+func ServerHash(serverID string, sharedSecret, publicKey []byte) string {
+	b := []byte(serverID)
+	b = append(b, sharedSecret...)
+	b = append(b, publicKey...)
+	return sha1HexDigest(b)
+}
+
+// Minecraft's hash is the SHA1 digest printed as a signed, two's
+// complement hex number without leading zeros.
 func sha1HexDigest(data []byte) string {
 	hasher := sha1.New()
 	hasher.Write(data)
@@ -0,0 +1,42 @@
+package session
+
+import (
+	"sync"
+)
+
+// Persists yggdrasil sessions between runs, so accounts don't need to
+// log in with their password every time.
+//
+// Sessions are keyed by the account's username (or email).
+type TokenStore interface {
+	// Returns the stored session, or nil if there isn't one.
+	LoadSession(username string) (*YggdrasilSession, error)
+	SaveSession(username string, s *YggdrasilSession) error
+}
+
+// A TokenStore that only keeps sessions in memory.
+type MemoryTokenStore struct {
+	mutex    sync.Mutex
+	sessions map[string]YggdrasilSession
+}
+
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{sessions: make(map[string]YggdrasilSession)}
+}
+
+func (m *MemoryTokenStore) LoadSession(username string) (*YggdrasilSession, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	s, ok := m.sessions[username]
+	if !ok {
+		return nil, nil
+	}
+	return &s, nil
+}
+
+func (m *MemoryTokenStore) SaveSession(username string, s *YggdrasilSession) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.sessions[username] = *s
+	return nil
+}
@@ -0,0 +1,22 @@
+package session
+
+import (
+	. "github.com/jeffh/goexpect"
+	"testing"
+)
+
+func TestMemoryTokenStoreSavesCopiesOfSessions(t *testing.T) {
+	it := NewIt(t)
+	store := NewMemoryTokenStore()
+	s, err := store.LoadSession("john")
+	it.Must(err)
+	it.Expects(s, ToBeNil)
+
+	saved := &YggdrasilSession{AccessToken: "deadbeef", ProfileName: "john"}
+	it.Must(store.SaveSession("john", saved))
+	saved.AccessToken = "changed"
+
+	s, err = store.LoadSession("john")
+	it.Must(err)
+	it.Expects(s, ToEqual, &YggdrasilSession{AccessToken: "deadbeef", ProfileName: "john"})
+}
@@ -0,0 +1,86 @@
+package session
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+const CheckServerURL = "http://session.minecraft.net/game/checkserver.jsp"
+
+// The server-side counterpart to Client. Servers use it to check that
+// a connecting player has joined the server through the session
+// service.
+//
+// Only the Username, ServerID, SharedSecret and PublicKey of the
+// ServerInfo are used.
+type Verifier interface {
+	VerifyJoin(info ServerInfo) (bool, error)
+}
+
+// A Verifier that allows every player. Useful for offline mode.
+type NullVerifier struct{}
+
+func (n *NullVerifier) VerifyJoin(s ServerInfo) (bool, error) {
+	return true, nil
+}
+
+// A Verifier that records every request and replies with Allow.
+type RecorderVerifier struct {
+	VerifyRequests []ServerInfo
+	Allow          bool
+}
+
+func NewRecorderVerifier(allow bool) *RecorderVerifier {
+	return &RecorderVerifier{make([]ServerInfo, 0), allow}
+}
+
+func (r *RecorderVerifier) VerifyJoin(s ServerInfo) (bool, error) {
+	r.VerifyRequests = append(r.VerifyRequests, s)
+	return r.Allow, nil
+}
+
+// Verifies joins with the session service's checkserver.jsp.
+type SessionVerifier struct {
+	URL     string
+	UserKey string
+	HashKey string
+	Client  *http.Client
+}
+
+func NewSessionVerifier() *SessionVerifier {
+	return &SessionVerifier{
+		URL:     CheckServerURL,
+		UserKey: sessionUserKey,
+		HashKey: sessionHashKey,
+		Client:  &http.Client{},
+	}
+}
+
+func (s *SessionVerifier) VerifyJoin(info ServerInfo) (bool, error) {
+	uri, err := url.Parse(s.URL)
+	if err != nil {
+		return false, err
+	}
+	query := uri.Query()
+	query.Add(s.UserKey, info.Username)
+	query.Add(s.HashKey, info.serverHash())
+	uri.RawQuery = query.Encode()
+	resp, err := s.Client.Get(uri.String())
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+	switch string(body) {
+	case "YES":
+		return true, nil
+	case "NO":
+		return false, nil
+	}
+	return false, fmt.Errorf("Unexpected response from session server: %s", body)
+}
@@ -0,0 +1,72 @@
+package session
+
+import (
+	. "github.com/jeffh/goexpect"
+	"httphandlers"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSessionVerifierChecksJoins(t *testing.T) {
+	it := NewIt(t)
+	mojang := httphandlers.NewMojangHandler(httphandlers.MojangAccount{
+		Username: "john@example.com",
+		Password: "secret",
+		Profiles: []httphandlers.MojangProfile{{Id: "badfeed", Name: "john"}},
+	})
+	server := httptest.NewServer(mojang)
+	defer server.Close()
+
+	yggdrasil := NewYggdrasilClient()
+	yggdrasil.URL = server.URL
+	token, err := yggdrasil.Authenticate("john@example.com", "secret")
+	it.Must(err)
+
+	info := ServerInfo{
+		Username:     "john",
+		SessionID:    token.SessionID(),
+		ServerID:     "myServer",
+		SharedSecret: []byte("secret"),
+		PublicKey:    []byte("publicKey"),
+	}
+	verifier := NewSessionVerifier()
+	verifier.URL = server.URL + "/game/checkserver.jsp"
+
+	joined, err := verifier.VerifyJoin(info)
+	it.Must(err)
+	it.Expects(joined, Not(ToBeTrue))
+
+	client := NewSessionClient()
+	client.URL = server.URL + "/game/joinserver.jsp"
+	it.Must(client.JoinServer(info))
+
+	joined, err = verifier.VerifyJoin(info)
+	it.Must(err)
+	it.Expects(joined, ToBeTrue)
+
+	info.SharedSecret = []byte("another secret")
+	joined, err = verifier.VerifyJoin(info)
+	it.Must(err)
+	it.Expects(joined, Not(ToBeTrue))
+}
+
+func TestSessionVerifierFailsOnUnexpectedResponses(t *testing.T) {
+	it := NewIt(t)
+	server := httptest.NewServer(httphandlers.NewFixtureFromString("Not Found"))
+	defer server.Close()
+	verifier := NewSessionVerifier()
+	verifier.URL = server.URL
+
+	joined, err := verifier.VerifyJoin(ServerInfo{Username: "john"})
+	it.Expects(joined, Not(ToBeTrue))
+	it.Expects(err, Not(ToBeNil))
+}
+
+func TestRecorderVerifierRecordsRequests(t *testing.T) {
+	it := NewIt(t)
+	verifier := NewRecorderVerifier(true)
+	joined, err := verifier.VerifyJoin(ServerInfo{Username: "john"})
+	it.Must(err)
+	it.Expects(joined, ToBeTrue)
+	it.Expects(verifier.VerifyRequests, ToEqual, []ServerInfo{{Username: "john"}})
+}
@@ -10,8 +10,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
 const (
@@ -32,15 +34,37 @@ type YggdrasilClient struct {
 }
 
 type YggdrasilSession struct {
-	AccessToken string
-	ClientToken string
-	ProfileID   string
+	AccessToken       string
+	ClientToken       string
+	ProfileID         string
+	ProfileName       string // the in-game name of the selected profile
+	AvailableProfiles []YggdrasilProfile
 }
 
 func (s *YggdrasilSession) SessionID() string {
 	return fmt.Sprintf("token:%s:%s", s.AccessToken, s.ProfileID)
 }
 
+// Returns the selected profile, or nil if none is selected.
+func (s *YggdrasilSession) SelectedProfile() *YggdrasilProfile {
+	if s.ProfileID == "" {
+		return nil
+	}
+	return &YggdrasilProfile{Id: s.ProfileID, Name: s.ProfileName}
+}
+
+func (s *YggdrasilSession) update(r *yggdrasilResponse) {
+	s.AccessToken = r.AccessToken
+	s.ClientToken = r.ClientToken
+	if r.SelectedProfile.Id != "" {
+		s.ProfileID = r.SelectedProfile.Id
+		s.ProfileName = r.SelectedProfile.Name
+	}
+	if r.AvailableProfiles != nil {
+		s.AvailableProfiles = r.AvailableProfiles
+	}
+}
+
 func NewYggdrasilClient() *YggdrasilClient {
 	return &YggdrasilClient{
 		URL: YggdrasilURL,
@@ -60,19 +84,37 @@ func (s *YggdrasilClient) fullPath(path string) string {
 	return uri.String()
 }
 
-func (s *YggdrasilClient) post(path string, data interface{}, respData interface{}) (*http.Response, error) {
+// Posts the data as JSON and decodes the response into respData.
+//
+// Returns a *YggdrasilError if the server replied with one.
+func (s *YggdrasilClient) post(path string, data interface{}, respData interface{}) error {
 	b := bytes.NewBuffer([]byte{})
 	encoder := json.NewEncoder(b)
 	err := encoder.Encode(data)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	resp, err := s.Client.Post(s.fullPath(path), "application/json", b)
-	if err == nil && respData != nil {
-		decoder := json.NewDecoder(resp.Body)
-		err = decoder.Decode(respData)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	errResponse := &yggdrasilResponse{}
+	if len(body) > 0 && json.Unmarshal(body, errResponse) == nil && errResponse.IsError() {
+		return errResponse.Error()
 	}
-	return resp, err
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &YggdrasilError{ErrorCode: resp.Status}
+	}
+	if respData != nil {
+		err = json.Unmarshal(body, respData)
+	}
+	return err
 }
 
 func (s *YggdrasilClient) Authenticate(username, password string) (*YggdrasilSession, error) {
@@ -82,64 +124,69 @@ func (s *YggdrasilClient) Authenticate(username, password string) (*YggdrasilSes
 		Password: password,
 	}
 	authResponse := &yggdrasilResponse{}
-	_, err := s.post("/authenticate", data, authResponse)
+	err := s.post("/authenticate", data, authResponse)
 	if err != nil {
 		return nil, err
 	}
 
-	if authResponse.IsError() {
-		return nil, &YggdrasilError{
-			ErrorCode:    authResponse.ErrorCode,
-			ErrorMessage: authResponse.ErrorMessage,
-			Cause:        authResponse.Cause,
-		}
-	}
-
-	token := &YggdrasilSession{
-		AccessToken: authResponse.AccessToken,
-		ClientToken: authResponse.ClientToken,
-		ProfileID:   authResponse.SelectedProfile.Id,
-	}
-	return token, err
+	token := &YggdrasilSession{}
+	token.update(authResponse)
+	return token, nil
 }
 
+// Gets a new access token for the session, invalidating the old one.
 func (s *YggdrasilClient) Refresh(token *YggdrasilSession) error {
+	return s.refresh(token, nil)
+}
+
+// Refreshes the session with the given profile selected. The profile
+// must be one of the account's available profiles and the session must
+// not already have a profile selected.
+func (s *YggdrasilClient) SelectProfile(token *YggdrasilSession, profile YggdrasilProfile) error {
+	return s.refresh(token, &profile)
+}
+
+func (s *YggdrasilClient) refresh(token *YggdrasilSession, profile *YggdrasilProfile) error {
 	data := &yggdrasilRefreshRequest{
-		AccessToken: token.AccessToken,
-		ClientToken: token.ClientToken,
+		AccessToken:     token.AccessToken,
+		ClientToken:     token.ClientToken,
+		SelectedProfile: profile,
 	}
 	authResponse := &yggdrasilResponse{}
-	_, err := s.post("/refresh", data, authResponse)
+	err := s.post("/refresh", data, authResponse)
 	if err != nil {
 		return err
 	}
 
-	if authResponse.IsError() {
-		return &YggdrasilError{
-			ErrorCode:    authResponse.ErrorCode,
-			ErrorMessage: authResponse.ErrorMessage,
-			Cause:        authResponse.Cause,
-		}
-	}
-
-	token.AccessToken = authResponse.AccessToken
-	token.ClientToken = authResponse.ClientToken
-	return err
+	token.update(authResponse)
+	return nil
 }
 
+// Returns nil if the session's access token can still be used to join
+// servers.
 func (s *YggdrasilClient) Validate(token *YggdrasilSession) error {
 	data := &yggdrasilRefreshRequest{
 		AccessToken: token.AccessToken,
 	}
-	resp, err := s.post("/validate", data, nil)
-	if err != nil {
-		return nil
+	return s.post("/validate", data, nil)
+}
+
+// Invalidates the session's access token.
+func (s *YggdrasilClient) Invalidate(token *YggdrasilSession) error {
+	data := &yggdrasilRefreshRequest{
+		AccessToken: token.AccessToken,
+		ClientToken: token.ClientToken,
 	}
+	return s.post("/invalidate", data, nil)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("Auth token has expired")
+// Invalidates every access token of the account.
+func (s *YggdrasilClient) Signout(username, password string) error {
+	data := &yggdrasilSignoutRequest{
+		Username: username,
+		Password: password,
 	}
-	return nil
+	return s.post("/signout", data, nil)
 }
 
 type YggdrasilAgent struct {
@@ -153,15 +200,21 @@ type YggdrasilProfile struct {
 }
 
 type yggdrasilAuthenticateRequest struct {
-	Agent       YggdrasilAgent `json:"agent",omitempty`
+	Agent       YggdrasilAgent `json:"agent"`
 	Username    string         `json:"username"`
 	Password    string         `json:"password"`
 	ClientToken string         `json:"clientToken,omitempty"`
 }
 
 type yggdrasilRefreshRequest struct {
-	AccessToken string `json:"accessToken"`
-	ClientToken string `json:"clientToken,omitempty"`
+	AccessToken     string            `json:"accessToken"`
+	ClientToken     string            `json:"clientToken,omitempty"`
+	SelectedProfile *YggdrasilProfile `json:"selectedProfile,omitempty"`
+}
+
+type yggdrasilSignoutRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
 }
 
 type yggdrasilResponse struct {
@@ -179,6 +232,25 @@ func (y *yggdrasilResponse) IsError() bool {
 	return y.ErrorCode != ""
 }
 
+func (y *yggdrasilResponse) Error() *YggdrasilError {
+	return &YggdrasilError{
+		ErrorCode:    y.ErrorCode,
+		ErrorMessage: y.ErrorMessage,
+		Cause:        y.Cause,
+	}
+}
+
+// The documented kinds of errors returned by yggdrasil.
+type YggdrasilErrorKind int
+
+const (
+	UnknownYggdrasilError   YggdrasilErrorKind = iota
+	InvalidCredentialsError                    // wrong username or password
+	AccountMigratedError                       // the account must log in with its email
+	RateLimitedError                           // too many login attempts
+	InvalidTokenError                          // the access token is expired or was invalidated
+)
+
 type YggdrasilError struct {
 	ErrorCode    string
 	ErrorMessage string
@@ -188,3 +260,58 @@ type YggdrasilError struct {
 func (y *YggdrasilError) Error() string {
 	return fmt.Sprintf("%s - %s: %s", y.ErrorCode, y.ErrorMessage, y.Cause)
 }
+
+// Classifies the error based on its code, message and cause.
+func (y *YggdrasilError) Kind() YggdrasilErrorKind {
+	if y.ErrorCode != "ForbiddenOperationException" {
+		return UnknownYggdrasilError
+	}
+	switch {
+	case y.Cause == "UserMigratedException":
+		return AccountMigratedError
+	case y.ErrorMessage == "Invalid token.":
+		return InvalidTokenError
+	case y.ErrorMessage == "Invalid credentials.":
+		// yggdrasil gives no details when rate limiting
+		return RateLimitedError
+	case strings.HasPrefix(y.ErrorMessage, "Invalid credentials."):
+		return InvalidCredentialsError
+	}
+	return UnknownYggdrasilError
+}
+
+// Returns the kind of the given error, or UnknownYggdrasilError if it
+// isn't a *YggdrasilError.
+func YggdrasilErrorKindOf(err error) YggdrasilErrorKind {
+	if y, ok := err.(*YggdrasilError); ok {
+		return y.Kind()
+	}
+	return UnknownYggdrasilError
+}
+
+//////////////////////////////////////////////////////////
+
+// Picks one of the profiles by its name, ignoring case. If name is
+// empty, the account must only have one profile.
+func PickProfile(profiles []YggdrasilProfile, name string) (*YggdrasilProfile, error) {
+	if name != "" {
+		for i := range profiles {
+			if strings.EqualFold(profiles[i].Name, name) {
+				return &profiles[i], nil
+			}
+		}
+		return nil, fmt.Errorf("No profile named %#v", name)
+	}
+
+	switch len(profiles) {
+	case 0:
+		return nil, fmt.Errorf("Account has no profiles")
+	case 1:
+		return &profiles[0], nil
+	}
+	names := make([]string, len(profiles))
+	for i, p := range profiles {
+		names[i] = p.Name
+	}
+	return nil, fmt.Errorf("Account has several profiles, pick one of: %s", strings.Join(names, ", "))
+}
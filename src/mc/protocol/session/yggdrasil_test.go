@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	. "github.com/jeffh/goexpect"
 	"httphandlers"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -44,9 +45,11 @@ func TestSessionServerAuthenticate(t *testing.T) {
 	it.Must(err)
 
 	it.Expects(token, ToEqual, &YggdrasilSession{
-		AccessToken: "deadbeef",
-		ClientToken: "clientID",
-		ProfileID:   "badfeed",
+		AccessToken:       "deadbeef",
+		ClientToken:       "clientID",
+		ProfileID:         "badfeed",
+		ProfileName:       "john",
+		AvailableProfiles: []YggdrasilProfile{{Id: "badfeed", Name: "john"}},
 	})
 
 	requests := recorder.RequestsByPath("/authenticate")
@@ -104,6 +107,7 @@ func TestSessionServiceRefresh(t *testing.T) {
 		AccessToken: "deadbeef",
 		ClientToken: "clientID",
 		ProfileID:   "badfeed",
+		ProfileName: "john",
 	})
 
 	requests := recorder.RequestsByPath("/refresh")
@@ -163,3 +167,93 @@ func TestSessionServiceValidate(t *testing.T) {
 	it.Expects(auth.AccessToken, ToEqual, "accessToken")
 	it.Expects(auth.ClientToken, ToEqual, "")
 }
+
+func fakeMojang() (*httptest.Server, *YggdrasilClient) {
+	server := httptest.NewServer(httphandlers.NewMojangHandler(httphandlers.MojangAccount{
+		Username: "john@example.com",
+		Password: "secret",
+		Profiles: []httphandlers.MojangProfile{{Id: "badfeed", Name: "john"}, {Id: "cafe", Name: "Johnny"}},
+	}))
+	client := NewYggdrasilClient()
+	client.URL = server.URL
+	return server, client
+}
+
+func TestSessionServiceSelectsProfiles(t *testing.T) {
+	it := NewIt(t)
+	server, client := fakeMojang()
+	defer server.Close()
+
+	token, err := client.Authenticate("john@example.com", "secret")
+	it.Must(err)
+	it.Expects(token.SelectedProfile(), ToEqual, &YggdrasilProfile{Id: "badfeed", Name: "john"})
+	it.Expects(token.AvailableProfiles, ToBeLengthOf, 2)
+
+	profile, err := PickProfile(token.AvailableProfiles, "johnny")
+	it.Must(err)
+	it.Must(client.SelectProfile(token, *profile))
+	it.Expects(token.SelectedProfile(), ToEqual, &YggdrasilProfile{Id: "cafe", Name: "Johnny"})
+	it.Expects(token.SessionID(), ToEqual, "token:"+token.AccessToken+":cafe")
+}
+
+func TestSessionServiceInvalidatesTokens(t *testing.T) {
+	it := NewIt(t)
+	server, client := fakeMojang()
+	defer server.Close()
+
+	token, err := client.Authenticate("john@example.com", "secret")
+	it.Must(err)
+	it.Must(client.Invalidate(token))
+
+	err = client.Validate(token)
+	it.Expects(err, Not(ToBeNil))
+	it.Expects(YggdrasilErrorKindOf(err), ToEqual, InvalidTokenError)
+}
+
+func TestSessionServiceSignsOut(t *testing.T) {
+	it := NewIt(t)
+	server, client := fakeMojang()
+	defer server.Close()
+
+	token, err := client.Authenticate("john@example.com", "secret")
+	it.Must(err)
+	it.Must(client.Signout("john@example.com", "secret"))
+	it.Expects(client.Validate(token), Not(ToBeNil))
+
+	err = client.Signout("john@example.com", "wrong")
+	it.Expects(YggdrasilErrorKindOf(err), ToEqual, InvalidCredentialsError)
+}
+
+func TestYggdrasilErrorKinds(t *testing.T) {
+	it := NewIt(t)
+	forbidden := func(message, cause string) *YggdrasilError {
+		return &YggdrasilError{"ForbiddenOperationException", message, cause}
+	}
+	it.Expects(forbidden("Invalid credentials. Invalid username or password.", "").Kind(), ToEqual, InvalidCredentialsError)
+	it.Expects(forbidden("Invalid credentials. Account migrated, use e-mail as username.", "UserMigratedException").Kind(), ToEqual, AccountMigratedError)
+	it.Expects(forbidden("Invalid credentials.", "").Kind(), ToEqual, RateLimitedError)
+	it.Expects(forbidden("Invalid token.", "").Kind(), ToEqual, InvalidTokenError)
+	it.Expects((&YggdrasilError{ErrorCode: "IllegalArgumentException"}).Kind(), ToEqual, UnknownYggdrasilError)
+	it.Expects(YggdrasilErrorKindOf(io.EOF), ToEqual, UnknownYggdrasilError)
+}
+
+func TestPickProfile(t *testing.T) {
+	it := NewIt(t)
+	john := YggdrasilProfile{Id: "badfeed", Name: "john"}
+	jane := YggdrasilProfile{Id: "cafe", Name: "jane"}
+
+	profile, err := PickProfile([]YggdrasilProfile{john}, "")
+	it.Must(err)
+	it.Expects(profile, ToEqual, &john)
+
+	profile, err = PickProfile([]YggdrasilProfile{john, jane}, "Jane")
+	it.Must(err)
+	it.Expects(profile, ToEqual, &jane)
+
+	_, err = PickProfile([]YggdrasilProfile{john, jane}, "")
+	it.Expects(err, Not(ToBeNil))
+	_, err = PickProfile([]YggdrasilProfile{john}, "bob")
+	it.Expects(err, Not(ToBeNil))
+	_, err = PickProfile(nil, "")
+	it.Expects(err, Not(ToBeNil))
+}
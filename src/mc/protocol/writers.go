@@ -118,5 +118,6 @@ func (w *Writer) WritePacket(v interface{}) error {
 		return err
 	}
 	w.WriteValue(pt)
-	return w.WriteStruct(v)
+	// dereference, so custom writers of packet structs are used
+	return w.WriteDispatch(reflect.Indirect(reflect.ValueOf(v)).Interface())
 }
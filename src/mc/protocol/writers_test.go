@@ -76,3 +76,21 @@ func TestWriterCanWriteDoublesAndBools(t *testing.T) {
 	Expect(t, Z, ToEqual, float64(64))
 	Expect(t, IsOnGround, ToEqual, int8(1))
 }
+
+func TestWriterCanWriteLoginRequests(t *testing.T) {
+	w, buf := createProtocolWriter()
+	p := LoginRequest{
+		EntityID:   13,
+		LevelType:  DefaultLevelType,
+		GameMode:   GameModeCreative,
+		Dimension:  GameDimensionNether,
+		Difficulty: GameDifficultyNormal,
+		MaxPlayers: 8,
+	}
+	Expect(t, w.WriteStruct(&p), ToBeNil)
+
+	r := NewReader(buf, ClientPacketMapper, nil, nil)
+	read := LoginRequest{}
+	Expect(t, r.ReadStruct(&read), ToBeNil)
+	Expect(t, read, ToEqual, p)
+}
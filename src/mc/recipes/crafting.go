@@ -0,0 +1,294 @@
+package recipes
+
+import (
+	"fmt"
+)
+
+var crafting = craftingRecipes()
+
+// The materials of tools and armor, by the prefix of their names.
+var toolMaterials = []struct{ prefix, material string }{
+	{"wooden", "planks:*"},
+	{"stone", "cobblestone"},
+	{"iron", "iron_ingot"},
+	{"diamond", "diamond"},
+	{"golden", "gold_ingot"},
+}
+
+var armorMaterials = []struct{ prefix, material string }{
+	{"leather", "leather"},
+	{"iron", "iron_ingot"},
+	{"diamond", "diamond"},
+	{"golden", "gold_ingot"},
+}
+
+// Blocks made of nine items, which can be crafted back into them.
+var storageBlocks = []struct{ block, item string }{
+	{"gold_block", "gold_ingot"},
+	{"iron_block", "iron_ingot"},
+	{"diamond_block", "diamond"},
+	{"emerald_block", "emerald"},
+	{"lapis_block", "dye:4"},
+	{"redstone_block", "redstone"},
+	{"coal_block", "coal:0"},
+	{"hay_block", "wheat"},
+}
+
+// Stairs and the blocks they're made of.
+var stairs = []struct{ stairs, block string }{
+	{"oak_stairs", "planks:0"},
+	{"spruce_stairs", "planks:1"},
+	{"birch_stairs", "planks:2"},
+	{"jungle_stairs", "planks:3"},
+	{"stone_stairs", "cobblestone"},
+	{"brick_stairs", "brick_block"},
+	{"stone_brick_stairs", "stonebrick:*"},
+	{"nether_brick_stairs", "nether_brick"},
+	{"sandstone_stairs", "sandstone:*"},
+	{"quartz_stairs", "quartz_block:*"},
+}
+
+// Stone slab variants and the blocks they're made of.
+var stoneSlabs = []string{
+	0: "stone",
+	1: "sandstone:*",
+	3: "cobblestone",
+	4: "brick_block",
+	5: "stonebrick:*",
+	6: "nether_brick",
+	7: "quartz_block:*",
+}
+
+// Dyes mixed from others, by their damage values.
+var mixedDyes = []struct {
+	result, count int
+	from          []int
+}{
+	{14, 2, []int{1, 11}},       // orange
+	{9, 2, []int{1, 15}},        // pink
+	{10, 2, []int{2, 15}},       // lime
+	{8, 2, []int{0, 15}},        // gray
+	{7, 2, []int{8, 15}},        // light gray
+	{7, 3, []int{0, 15, 15}},    // light gray
+	{12, 2, []int{4, 15}},       // light blue
+	{6, 2, []int{4, 2}},         // cyan
+	{5, 2, []int{4, 1}},         // purple
+	{13, 2, []int{5, 9}},        // magenta
+	{13, 3, []int{4, 1, 9}},     // magenta
+	{13, 4, []int{4, 1, 1, 15}}, // magenta
+}
+
+func dye(color int) string {
+	return fmt.Sprintf("dye:%d", color)
+}
+
+func craftingRecipes() []*Recipe {
+	r := make([]*Recipe, 0)
+	add := func(recipes ...*Recipe) {
+		r = append(r, recipes...)
+	}
+
+	// tools, weapons and armor
+	for _, m := range toolMaterials {
+		k := keys{'X': m.material, '#': "stick"}
+		add(
+			shaped(m.prefix+"_pickaxe", 1, []string{"XXX", " # ", " # "}, k),
+			shaped(m.prefix+"_axe", 1, []string{"XX", "X#", " #"}, k),
+			shaped(m.prefix+"_shovel", 1, []string{"X", "#", "#"}, k),
+			shaped(m.prefix+"_hoe", 1, []string{"XX", " #", " #"}, k),
+			shaped(m.prefix+"_sword", 1, []string{"X", "X", "#"}, k),
+		)
+	}
+	for _, m := range armorMaterials {
+		k := keys{'X': m.material}
+		add(
+			shaped(m.prefix+"_helmet", 1, []string{"XXX", "X X"}, k),
+			shaped(m.prefix+"_chestplate", 1, []string{"X X", "XXX", "XXX"}, k),
+			shaped(m.prefix+"_leggings", 1, []string{"XXX", "X X", "X X"}, k),
+			shaped(m.prefix+"_boots", 1, []string{"X X", "X X"}, k),
+		)
+	}
+	add(
+		shaped("bow", 1, []string{" #X", "# X", " #X"}, keys{'#': "stick", 'X': "string"}),
+		shaped("arrow", 4, []string{"X", "#", "Y"}, keys{'X': "flint", '#': "stick", 'Y': "feather"}),
+		shaped("bucket", 1, []string{"# #", " # "}, keys{'#': "iron_ingot"}),
+		shaped("shears", 1, []string{" #", "# "}, keys{'#': "iron_ingot"}),
+		shapeless("flint_and_steel", 1, "iron_ingot", "flint"),
+		shaped("fishing_rod", 1, []string{"  #", " #X", "# X"}, keys{'#': "stick", 'X': "string"}),
+		shaped("carrot_on_a_stick", 1, []string{"# ", " X"}, keys{'#': "fishing_rod", 'X': "carrot"}),
+		shaped("compass", 1, []string{" # ", "#X#", " # "}, keys{'#': "iron_ingot", 'X': "redstone"}),
+		shaped("clock", 1, []string{" # ", "#X#", " # "}, keys{'#': "gold_ingot", 'X': "redstone"}),
+		shaped("map", 1, []string{"###", "#X#", "###"}, keys{'#': "paper", 'X': "compass"}),
+		shaped("lead", 2, []string{"~~ ", "~O ", "  ~"}, keys{'~': "string", 'O': "slime_ball"}),
+	)
+
+	// wood
+	for variant := 0; variant < 4; variant++ {
+		add(shapeless(fmt.Sprintf("planks:%d", variant), 4, fmt.Sprintf("log:%d", variant)))
+	}
+	planks := keys{'#': "planks:*", 'X': "stick"}
+	add(
+		shaped("stick", 4, []string{"#", "#"}, planks),
+		shaped("crafting_table", 1, []string{"##", "##"}, planks),
+		shaped("chest", 1, []string{"###", "# #", "###"}, planks),
+		shaped("wooden_door", 1, []string{"##", "##", "##"}, planks),
+		shaped("trapdoor", 2, []string{"###", "###"}, planks),
+		shaped("sign", 3, []string{"###", "###", " X "}, planks),
+		shaped("ladder", 3, []string{"X X", "XXX", "X X"}, planks),
+		shaped("fence", 2, []string{"XXX", "XXX"}, planks),
+		shaped("fence_gate", 1, []string{"X#X", "X#X"}, planks),
+		shaped("bowl", 4, []string{"# #", " # "}, planks),
+		shaped("boat", 1, []string{"# #", "###"}, planks),
+		shaped("wooden_pressure_plate", 1, []string{"##"}, planks),
+		shapeless("wooden_button", 1, "planks:*"),
+		shaped("bookshelf", 1, []string{"###", "XXX", "###"}, keys{'#': "planks:*", 'X': "book"}),
+		shaped("noteblock", 1, []string{"###", "#X#", "###"}, keys{'#': "planks:*", 'X': "redstone"}),
+		shaped("jukebox", 1, []string{"###", "#X#", "###"}, keys{'#': "planks:*", 'X': "diamond"}),
+		shaped("bed", 1, []string{"###", "XXX"}, keys{'#': "wool:*", 'X': "planks:*"}),
+		shaped("tripwire_hook", 2, []string{"I", "S", "#"}, keys{'I': "iron_ingot", 'S': "stick", '#': "planks:*"}),
+	)
+	for variant := 0; variant < 4; variant++ {
+		k := keys{'#': fmt.Sprintf("planks:%d", variant)}
+		add(shaped(fmt.Sprintf("wooden_slab:%d", variant), 6, []string{"###"}, k))
+	}
+
+	// building blocks
+	for _, s := range stairs {
+		add(shaped(s.stairs, 4, []string{"#  ", "## ", "###"}, keys{'#': s.block}))
+	}
+	for variant, block := range stoneSlabs {
+		if block != "" {
+			add(shaped(fmt.Sprintf("stone_slab:%d", variant), 6, []string{"###"}, keys{'#': block}))
+		}
+	}
+	for _, s := range storageBlocks {
+		add(
+			shaped(s.block, 1, []string{"###", "###", "###"}, keys{'#': s.item}),
+			shapeless(s.item, 9, s.block),
+		)
+	}
+	add(
+		shaped("gold_ingot", 1, []string{"###", "###", "###"}, keys{'#': "gold_nugget"}),
+		shapeless("gold_nugget", 9, "gold_ingot"),
+		shaped("furnace", 1, []string{"###", "# #", "###"}, keys{'#': "cobblestone"}),
+		shaped("stonebrick", 4, []string{"##", "##"}, keys{'#': "stone"}),
+		shaped("sandstone", 1, []string{"##", "##"}, keys{'#': "sand"}),
+		shaped("sandstone:1", 1, []string{"#", "#"}, keys{'#': "stone_slab:1"}),
+		shaped("sandstone:2", 4, []string{"##", "##"}, keys{'#': "sandstone"}),
+		shaped("brick_block", 1, []string{"##", "##"}, keys{'#': "brick"}),
+		shaped("nether_brick", 1, []string{"##", "##"}, keys{'#': "netherbrick"}),
+		shaped("quartz_block", 1, []string{"##", "##"}, keys{'#': "quartz"}),
+		shaped("quartz_block:1", 1, []string{"#", "#"}, keys{'#': "stone_slab:7"}),
+		shaped("quartz_block:2", 2, []string{"#", "#"}, keys{'#': "quartz_block"}),
+		shaped("glowstone", 1, []string{"##", "##"}, keys{'#': "glowstone_dust"}),
+		shaped("snow", 1, []string{"##", "##"}, keys{'#': "snowball"}),
+		shaped("snow_layer", 6, []string{"###"}, keys{'#': "snow"}),
+		shaped("clay", 1, []string{"##", "##"}, keys{'#': "clay_ball"}),
+		shaped("wool", 1, []string{"##", "##"}, keys{'#': "string"}),
+		shaped("glass_pane", 16, []string{"###", "###"}, keys{'#': "glass"}),
+		shaped("iron_bars", 16, []string{"###", "###"}, keys{'#': "iron_ingot"}),
+		shaped("nether_brick_fence", 6, []string{"###", "###"}, keys{'#': "nether_brick"}),
+		shaped("cobblestone_wall", 6, []string{"###", "###"}, keys{'#': "cobblestone"}),
+		shaped("cobblestone_wall:1", 6, []string{"###", "###"}, keys{'#': "mossy_cobblestone"}),
+		shaped("iron_door", 1, []string{"##", "##", "##"}, keys{'#': "iron_ingot"}),
+		shaped("tnt", 1, []string{"X#X", "#X#", "X#X"}, keys{'X': "gunpowder", '#': "sand"}),
+		shaped("lit_pumpkin", 1, []string{"A", "B"}, keys{'A': "pumpkin", 'B': "torch"}),
+		shaped("melon_block", 1, []string{"MMM", "MMM", "MMM"}, keys{'M': "melon"}),
+		shaped("flower_pot", 1, []string{"# #", " # "}, keys{'#': "brick"}),
+		shaped("painting", 1, []string{"###", "#X#", "###"}, keys{'#': "stick", 'X': "wool:*"}),
+		shaped("item_frame", 1, []string{"###", "#X#", "###"}, keys{'#': "stick", 'X': "leather"}),
+		shaped("glass_bottle", 3, []string{"# #", " # "}, keys{'#': "glass"}),
+	)
+
+	// light and redstone
+	add(
+		shaped("torch", 4, []string{"X", "#"}, keys{'X': "coal:0", '#': "stick"}),
+		shaped("torch", 4, []string{"X", "#"}, keys{'X': "coal:1", '#': "stick"}),
+		shaped("lever", 1, []string{"X", "#"}, keys{'X': "stick", '#': "cobblestone"}),
+		shaped("redstone_torch", 1, []string{"X", "#"}, keys{'X': "redstone", '#': "stick"}),
+		shapeless("stone_button", 1, "stone"),
+		shaped("stone_pressure_plate", 1, []string{"##"}, keys{'#': "stone"}),
+		shaped("light_weighted_pressure_plate", 1, []string{"##"}, keys{'#': "gold_ingot"}),
+		shaped("heavy_weighted_pressure_plate", 1, []string{"##"}, keys{'#': "iron_ingot"}),
+		shaped("repeater", 1, []string{"#X#", "III"}, keys{'#': "redstone_torch", 'X': "redstone", 'I': "stone"}),
+		shaped("comparator", 1, []string{" # ", "#X#", "III"}, keys{'#': "redstone_torch", 'X': "quartz", 'I': "stone"}),
+		shaped("daylight_detector", 1, []string{"GGG", "QQQ", "WWW"}, keys{'G': "glass", 'Q': "quartz", 'W': "wooden_slab:*"}),
+		shaped("redstone_lamp", 1, []string{" R ", "RGR", " R "}, keys{'R': "redstone", 'G': "glowstone"}),
+		shaped("piston", 1, []string{"TTT", "#X#", "#R#"}, keys{'T': "planks:*", '#': "cobblestone", 'X': "iron_ingot", 'R': "redstone"}),
+		shaped("sticky_piston", 1, []string{"S", "P"}, keys{'S': "slime_ball", 'P': "piston"}),
+		shaped("dispenser", 1, []string{"###", "#X#", "#R#"}, keys{'#': "cobblestone", 'X': "bow", 'R': "redstone"}),
+		shaped("dropper", 1, []string{"###", "# #", "#R#"}, keys{'#': "cobblestone", 'R': "redstone"}),
+		shaped("hopper", 1, []string{"I I", "ICI", " I "}, keys{'I': "iron_ingot", 'C': "chest"}),
+		shapeless("trapped_chest", 1, "chest", "tripwire_hook"),
+	)
+
+	// transportation
+	add(
+		shaped("minecart", 1, []string{"# #", "###"}, keys{'#': "iron_ingot"}),
+		shaped("chest_minecart", 1, []string{"A", "B"}, keys{'A': "chest", 'B': "minecart"}),
+		shaped("furnace_minecart", 1, []string{"A", "B"}, keys{'A': "furnace", 'B': "minecart"}),
+		shaped("tnt_minecart", 1, []string{"A", "B"}, keys{'A': "tnt", 'B': "minecart"}),
+		shaped("hopper_minecart", 1, []string{"A", "B"}, keys{'A': "hopper", 'B': "minecart"}),
+		shaped("rail", 16, []string{"X X", "X#X", "X X"}, keys{'X': "iron_ingot", '#': "stick"}),
+		shaped("golden_rail", 6, []string{"X X", "X#X", "XRX"}, keys{'X': "gold_ingot", '#': "stick", 'R': "redstone"}),
+		shaped("detector_rail", 6, []string{"X X", "X#X", "XRX"}, keys{'X': "iron_ingot", '#': "stone_pressure_plate", 'R': "redstone"}),
+		shaped("activator_rail", 6, []string{"XSX", "X#X", "XSX"}, keys{'X': "iron_ingot", 'S': "stick", '#': "redstone_torch"}),
+	)
+
+	// food
+	add(
+		shaped("bread", 1, []string{"###"}, keys{'#': "wheat"}),
+		shaped("cookie", 8, []string{"#X#"}, keys{'#': "wheat", 'X': "dye:3"}),
+		shaped("cake", 1, []string{"AAA", "BEB", "CCC"}, keys{'A': "milk_bucket", 'B': "sugar", 'E': "egg", 'C': "wheat"}),
+		shapeless("sugar", 1, "reeds"),
+		shapeless("mushroom_stew", 1, "brown_mushroom", "red_mushroom", "bowl"),
+		shapeless("pumpkin_pie", 1, "pumpkin", "sugar", "egg"),
+		shaped("golden_apple", 1, []string{"###", "#X#", "###"}, keys{'#': "gold_ingot", 'X': "apple"}),
+		shaped("golden_apple:1", 1, []string{"###", "#X#", "###"}, keys{'#': "gold_block", 'X': "apple"}),
+		shaped("golden_carrot", 1, []string{"###", "#X#", "###"}, keys{'#': "gold_nugget", 'X': "carrot"}),
+		shapeless("speckled_melon", 1, "melon", "gold_nugget"),
+		shapeless("melon_seeds", 1, "melon"),
+		shapeless("pumpkin_seeds", 4, "pumpkin"),
+	)
+
+	// books, brewing and enchanting
+	add(
+		shaped("paper", 3, []string{"###"}, keys{'#': "reeds"}),
+		shapeless("book", 1, "paper", "paper", "paper", "leather"),
+		shapeless("writable_book", 1, "book", "dye:0", "feather"),
+		shaped("enchanting_table", 1, []string{" B ", "D#D", "###"}, keys{'B': "book", 'D': "diamond", '#': "obsidian"}),
+		shaped("brewing_stand", 1, []string{" B ", "###"}, keys{'B': "blaze_rod", '#': "cobblestone"}),
+		shaped("cauldron", 1, []string{"# #", "# #", "###"}, keys{'#': "iron_ingot"}),
+		shaped("anvil", 1, []string{"III", " i ", "iii"}, keys{'I': "iron_block", 'i': "iron_ingot"}),
+		shaped("beacon", 1, []string{"GGG", "GSG", "OOO"}, keys{'G': "glass", 'S': "nether_star", 'O': "obsidian"}),
+		shaped("ender_chest", 1, []string{"###", "#E#", "###"}, keys{'#': "obsidian", 'E': "ender_eye"}),
+		shapeless("blaze_powder", 2, "blaze_rod"),
+		shapeless("magma_cream", 1, "blaze_powder", "slime_ball"),
+		shapeless("fermented_spider_eye", 1, "spider_eye", "brown_mushroom", "sugar"),
+		shapeless("ender_eye", 1, "ender_pearl", "blaze_powder"),
+		shapeless("fire_charge", 3, "gunpowder", "blaze_powder", "coal:*"),
+	)
+
+	// dyes and colored blocks
+	add(
+		shapeless(dye(15), 3, "bone"),
+		shapeless(dye(1), 2, "red_flower"),
+		shapeless(dye(11), 2, "yellow_flower"),
+	)
+	for _, d := range mixedDyes {
+		from := make([]string, len(d.from))
+		for i, color := range d.from {
+			from[i] = dye(color)
+		}
+		add(shapeless(dye(d.result), d.count, from...))
+	}
+	for color := 0; color < 16; color++ {
+		add(
+			shapeless(fmt.Sprintf("wool:%d", color), 1, dye(15-color), "wool:0"),
+			shaped(fmt.Sprintf("carpet:%d", color), 3, []string{"##"}, keys{'#': fmt.Sprintf("wool:%d", color)}),
+			shaped(fmt.Sprintf("stained_hardened_clay:%d", color), 8, []string{"###", "#X#", "###"},
+				keys{'#': "hardened_clay", 'X': dye(15 - color)}),
+		)
+	}
+	return r
+}
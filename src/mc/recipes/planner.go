@@ -0,0 +1,217 @@
+package recipes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Counts of items, such as what an inventory holds.
+type Items map[Item]int
+
+// Returns how many of the items can be used as the ingredient.
+func (items Items) Count(ingredient Item) int {
+	count := 0
+	for i, n := range items {
+		if ingredient.Matches(i) {
+			count += n
+		}
+	}
+	return count
+}
+
+func (items Items) Add(item Item, count int) {
+	items[item] += count
+}
+
+// Removes up to count items that match the ingredient. Returns how
+// many were removed.
+func (items Items) Take(ingredient Item, count int) int {
+	taken := 0
+	for _, i := range items.sorted() {
+		if taken == count {
+			break
+		}
+		if ingredient.Matches(i) {
+			n := min(items[i], count-taken)
+			items[i] -= n
+			taken += n
+			if items[i] == 0 {
+				delete(items, i)
+			}
+		}
+	}
+	return taken
+}
+
+func (items Items) Copy() Items {
+	c := make(Items, len(items))
+	for i, n := range items {
+		c[i] = n
+	}
+	return c
+}
+
+// Returns the items in a stable order, so the same variants are used up
+// first every time.
+func (items Items) sorted() []Item {
+	sorted := make([]Item, 0, len(items))
+	for i := range items {
+		sorted = append(sorted, i)
+	}
+	sort.Slice(sorted, func(a, b int) bool {
+		if sorted[a].ID != sorted[b].ID {
+			return sorted[a].ID < sorted[b].ID
+		}
+		return sorted[a].Damage < sorted[b].Damage
+	})
+	return sorted
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+//////////////////////////////////////////////////////////
+
+// Returns the recipes that can be crafted at least once from the items
+// in a crafting grid of the given width.
+func Craftable(items Items, gridSize int) []*Recipe {
+	found := make([]*Recipe, 0)
+	for _, r := range crafting {
+		if r.GridSize() <= gridSize && Times(r, items) > 0 {
+			found = append(found, r)
+		}
+	}
+	return found
+}
+
+// Returns how many times the recipe can be crafted from the items.
+func Times(r *Recipe, items Items) int {
+	times := -1
+	for _, need := range r.Needs() {
+		t := items.Count(need.Item) / need.Count
+		if times < 0 || t < times {
+			times = t
+		}
+	}
+	if times < 0 {
+		return 0
+	}
+	return times
+}
+
+// Crafting a recipe a number of times, as part of a plan.
+type Step struct {
+	Recipe *Recipe
+	Times  int
+}
+
+// Returned by Plan with the items that are missing and can't be
+// crafted.
+type MissingItemsError struct {
+	Missing Items
+}
+
+func (e *MissingItemsError) Error() string {
+	names := make([]string, 0, len(e.Missing))
+	for _, i := range e.Missing.sorted() {
+		names = append(names, fmt.Sprintf("%d %s", e.Missing[i], i))
+	}
+	return "Missing " + strings.Join(names, ", ")
+}
+
+// Returns the crafts that make count of the item from the items, with
+// the intermediate items, like planks and sticks, crafted first. Items
+// that are already there are used before crafting more. Only recipes
+// that fit in a crafting grid of the given width are used. Returns a
+// MissingItemsError if there aren't enough ingredients.
+func Plan(target Item, count int, items Items, gridSize int) ([]Step, error) {
+	p := &planner{
+		items:    items.Copy(),
+		gridSize: gridSize,
+		steps:    make([]Step, 0),
+		missing:  make(Items),
+		crafting: make(map[Item]bool),
+	}
+	if !p.need(target, count) {
+		return nil, &MissingItemsError{p.missing}
+	}
+	return p.steps, nil
+}
+
+type planner struct {
+	items    Items
+	gridSize int
+	steps    []Step
+	missing  Items
+	crafting map[Item]bool // items being crafted, to avoid cycles like ingots and nuggets
+}
+
+func (p *planner) copy() *planner {
+	c := &planner{
+		items:    p.items.Copy(),
+		gridSize: p.gridSize,
+		steps:    append([]Step{}, p.steps...),
+		missing:  p.missing.Copy(),
+		crafting: make(map[Item]bool),
+	}
+	for i := range p.crafting {
+		c.crafting[i] = true
+	}
+	return c
+}
+
+// Uses up count of the ingredient, crafting what's missing. Returns
+// false if it can't be crafted.
+func (p *planner) need(ingredient Item, count int) bool {
+	count -= p.items.Take(ingredient, count)
+	if count == 0 {
+		return true
+	}
+	var missing Items
+	for _, r := range For(ingredient) {
+		if r.GridSize() > p.gridSize || p.crafting[r.Result] {
+			continue
+		}
+		try, ok := p.craft(r, count)
+		if ok {
+			*p = *try
+			return true
+		}
+		// reporting the raw materials of the first recipe, along with
+		// what was already missing
+		if missing == nil {
+			missing = try.missing
+		}
+	}
+	if missing == nil {
+		p.missing.Add(ingredient, count)
+	} else {
+		p.missing = missing
+	}
+	return false
+}
+
+// Returns the plan after crafting count items with the recipe, and
+// false if its ingredients can't all be found.
+func (p *planner) craft(r *Recipe, count int) (*planner, bool) {
+	try := p.copy()
+	try.crafting[r.Result] = true
+	times := (count + r.Count - 1) / r.Count
+	ok := true
+	for _, need := range r.Needs() {
+		if !try.need(need.Item, need.Count*times) {
+			ok = false
+		}
+	}
+	delete(try.crafting, r.Result)
+	try.steps = append(try.steps, Step{r, times})
+	if extra := times*r.Count - count; extra > 0 {
+		try.items.Add(r.Result, extra)
+	}
+	return try, ok
+}
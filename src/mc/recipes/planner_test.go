@@ -0,0 +1,84 @@
+package recipes
+
+import (
+	. "github.com/jeffh/goexpect"
+	"testing"
+)
+
+func TestCraftableRecipes(t *testing.T) {
+	it := NewIt(t)
+	items := Items{planks: 4}
+	names := make([]string, 0)
+	for _, r := range Craftable(items, 2) {
+		names = append(names, r.Result.String())
+	}
+	it.Expects(names, ToEqual, []string{"stick", "crafting_table", "wooden_pressure_plate", "wooden_button"})
+	it.Expects(len(Craftable(items, 3)) > len(names), ToBeTrue)
+	it.Expects(Craftable(Items{}, 3), ToBeEmpty)
+	it.Expects(Times(For(stick)[0], Items{planks: 5}), ToEqual, 2)
+}
+
+func TestPlanUsesWhatIsThere(t *testing.T) {
+	it := NewIt(t)
+	steps, err := Plan(pickaxe, 1, Items{planks: 3, stick: 2}, 3)
+	it.Expects(err, ToBeNil)
+	it.Expects(steps, ToEqual, []Step{{For(pickaxe)[0], 1}})
+
+	steps, err = Plan(stick, 1, Items{stick: 2}, 2)
+	it.Expects(err, ToBeNil)
+	it.Expects(steps, ToBeEmpty)
+}
+
+func TestPlanCraftsIntermediateItems(t *testing.T) {
+	it := NewIt(t)
+	steps, err := Plan(pickaxe, 1, Items{log: 2}, 3)
+	it.Expects(err, ToBeNil)
+	it.Expects(steps, ToBeLengthOf, 4)
+	// 3 planks for the head, and the leftover one with another log
+	// for the sticks
+	it.Expects(steps[0].Recipe.Result, ToEqual, planks)
+	it.Expects(steps[0].Times, ToEqual, 1)
+	it.Expects(steps[1].Recipe.Result, ToEqual, planks)
+	it.Expects(steps[1].Times, ToEqual, 1)
+	it.Expects(steps[2].Recipe.Result, ToEqual, stick)
+	it.Expects(steps[3].Recipe.Result, ToEqual, pickaxe)
+
+	steps, err = Plan(item("stone_pickaxe"), 1, Items{log: 1, cobble: 3}, 3)
+	it.Expects(err, ToBeNil)
+	it.Expects(steps, ToBeLengthOf, 3)
+	it.Expects(steps[1].Recipe.Result, ToEqual, stick)
+}
+
+func TestPlanReportsMissingItems(t *testing.T) {
+	it := NewIt(t)
+	_, err := Plan(item("stone_pickaxe"), 1, Items{cobble: 1, stick: 2}, 3)
+	it.Expects(err, ToEqual, &MissingItemsError{Items{cobble: 2}})
+	it.Expects(err.Error(), ToEqual, "Missing 2 cobblestone")
+
+	_, err = Plan(pickaxe, 1, Items{}, 3)
+	it.Expects(err.(*MissingItemsError).Missing, ToEqual, Items{log: 2})
+
+	// sticks can be crafted, but the cobblestone is still missing
+	_, err = Plan(item("stone_pickaxe"), 1, Items{planks: 2}, 3)
+	it.Expects(err, ToEqual, &MissingItemsError{Items{cobble: 3}})
+
+	// pickaxes need a workbench
+	_, err = Plan(pickaxe, 1, Items{planks: 3, stick: 2}, 2)
+	it.Expects(err, Not(ToBeNil))
+}
+
+func TestPlanAvoidsCycles(t *testing.T) {
+	it := NewIt(t)
+	_, err := Plan(item("gold_ingot"), 1, Items{}, 3)
+	it.Expects(err, Not(ToBeNil))
+
+	steps, err := Plan(item("gold_nugget"), 9, Items{item("gold_block"): 1}, 3)
+	it.Expects(err, ToBeNil)
+	it.Expects(steps, ToBeLengthOf, 2)
+
+	// dyed wool needs white wool, which is only another variant
+	steps, err = Plan(item("wool:14"), 1, Items{item("string"): 4, item("dye:1"): 1}, 2)
+	it.Expects(err, ToBeNil)
+	it.Expects(steps, ToBeLengthOf, 2)
+	it.Expects(steps[0].Recipe.Result, ToEqual, item("wool:0"))
+}
@@ -0,0 +1,286 @@
+// The crafting and smelting recipes of protocol version 74 (Minecraft
+// 1.6.2), and a planner for crafting items from an inventory.
+package recipes
+
+import (
+	"fmt"
+	"mc/registry"
+	"strconv"
+	"strings"
+)
+
+// The damage value of ingredients that match every variant of an item.
+const AnyDamage int16 = 32767
+
+// A kind of item, as held in slots.
+type Item struct {
+	ID     int16
+	Damage int16 // or variant
+}
+
+// The empty cells of crafting grids and shaped recipes.
+var None = Item{ID: -1}
+
+// Returns true if the item can be used where this ingredient is needed.
+func (i Item) Matches(o Item) bool {
+	return i.ID == o.ID && (i.Damage == AnyDamage || i.Damage == o.Damage)
+}
+
+func (i Item) String() string {
+	info, ok := registry.ItemByID(i.ID)
+	if !ok {
+		return fmt.Sprintf("%d:%d", i.ID, i.Damage)
+	}
+	if i.Damage == AnyDamage {
+		return info.Name
+	}
+	return info.VariantName(i.Damage)
+}
+
+// A crafting recipe. Shaped recipes have their ingredients in a pattern,
+// which may also be mirrored. Shapeless ones can be placed anywhere in
+// the crafting grid.
+type Recipe struct {
+	Result Item
+	Count  int // of the result
+	// The size of the pattern of shaped recipes, or 0 for shapeless
+	// ones.
+	Width, Height int
+	// The pattern, row by row, with None for empty cells.
+	Ingredients []Item
+}
+
+func (r *Recipe) IsShaped() bool {
+	return r.Width > 0
+}
+
+// Returns the width of the smallest crafting grid the recipe fits in:
+// 2 for the player's inventory, or 3 for a workbench.
+func (r *Recipe) GridSize() int {
+	if r.IsShaped() {
+		if r.Width > 2 || r.Height > 2 {
+			return 3
+		}
+		return 2
+	}
+	if len(r.Ingredients) > 4 {
+		return 3
+	}
+	return 2
+}
+
+// An ingredient and how many of it a single craft uses.
+type Need struct {
+	Item  Item
+	Count int
+}
+
+// Returns the ingredients used by a single craft, in the order they
+// first appear in the recipe.
+func (r *Recipe) Needs() []Need {
+	needs := make([]Need, 0)
+next:
+	for _, i := range r.Ingredients {
+		if i == None {
+			continue
+		}
+		for n := range needs {
+			if needs[n].Item == i {
+				needs[n].Count++
+				continue next
+			}
+		}
+		needs = append(needs, Need{i, 1})
+	}
+	return needs
+}
+
+// Returns true if the crafting grid, row by row with the given width,
+// holds exactly the recipe's ingredients.
+func (r *Recipe) Matches(grid []Item, width int) bool {
+	if !r.IsShaped() {
+		return r.matchesShapeless(grid)
+	}
+	// the part of the grid that isn't empty has to be the pattern's size
+	minX, minY, maxX, maxY := width, len(grid)/width, -1, -1
+	for i, item := range grid {
+		if item == None {
+			continue
+		}
+		x, y := i%width, i/width
+		if x < minX {
+			minX = x
+		}
+		if x > maxX {
+			maxX = x
+		}
+		if y < minY {
+			minY = y
+		}
+		if y > maxY {
+			maxY = y
+		}
+	}
+	if maxX-minX+1 != r.Width || maxY-minY+1 != r.Height {
+		return false
+	}
+	return r.matchesAt(grid, width, minX, minY, false) || r.matchesAt(grid, width, minX, minY, true)
+}
+
+func (r *Recipe) matchesAt(grid []Item, width, left, top int, mirrored bool) bool {
+	for y := 0; y < r.Height; y++ {
+		for x := 0; x < r.Width; x++ {
+			px := x
+			if mirrored {
+				px = r.Width - 1 - x
+			}
+			want := r.Ingredients[y*r.Width+px]
+			got := grid[(top+y)*width+left+x]
+			if want == None && got != None || want != None && !want.Matches(got) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r *Recipe) matchesShapeless(grid []Item) bool {
+	used := make([]bool, len(r.Ingredients))
+next:
+	for _, item := range grid {
+		if item == None {
+			continue
+		}
+		for i, want := range r.Ingredients {
+			if !used[i] && want.Matches(item) {
+				used[i] = true
+				continue next
+			}
+		}
+		return false
+	}
+	for _, u := range used {
+		if !u {
+			return false
+		}
+	}
+	return true
+}
+
+// A furnace recipe.
+type Smelting struct {
+	Input      Item
+	Result     Item
+	Experience float32 // given for each smelted item
+}
+
+//////////////////////////////////////////////////////////
+
+// Returns every crafting recipe.
+func All() []*Recipe {
+	return crafting
+}
+
+// Returns the recipes that craft the item. Use AnyDamage to include
+// every variant.
+func For(item Item) []*Recipe {
+	found := make([]*Recipe, 0)
+	for _, r := range crafting {
+		if item.Matches(r.Result) {
+			found = append(found, r)
+		}
+	}
+	return found
+}
+
+// Returns the recipe for what's in the crafting grid, row by row with
+// the given width, or nil if nothing can be crafted from it.
+func Match(grid []Item, width int) *Recipe {
+	for _, r := range crafting {
+		if r.Matches(grid, width) {
+			return r
+		}
+	}
+	return nil
+}
+
+// Returns what the item turns into in a furnace, or false if it can't
+// be smelted.
+func Smelt(input Item) (*Smelting, bool) {
+	for _, s := range smelting {
+		if s.Input.Matches(input) {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// Returns the furnace recipes that produce the item.
+func SmeltingFor(item Item) []*Smelting {
+	found := make([]*Smelting, 0)
+	for _, s := range smelting {
+		if item.Matches(s.Result) {
+			found = append(found, s)
+		}
+	}
+	return found
+}
+
+//////////////////////////////////////////////////////////
+
+// Parses the registry name of an item, optionally followed by a colon
+// and either a damage value or * for any damage.
+func item(name string) Item {
+	damage := int16(0)
+	if i := strings.Index(name, ":"); i >= 0 {
+		if name[i+1:] == "*" {
+			damage = AnyDamage
+		} else {
+			d, err := strconv.Atoi(name[i+1:])
+			if err != nil {
+				panic(fmt.Errorf("Bad damage value in %#v", name))
+			}
+			damage = int16(d)
+		}
+		name = name[:i]
+	}
+	info, ok := registry.ItemByName(name)
+	if !ok {
+		panic(fmt.Errorf("Unknown item %#v", name))
+	}
+	return Item{info.ID, damage}
+}
+
+// The symbols of a shaped recipe's pattern.
+type keys map[rune]string
+
+func shaped(result string, count int, pattern []string, k keys) *Recipe {
+	r := &Recipe{
+		Result: item(result),
+		Count:  count,
+		Width:  len(pattern[0]),
+		Height: len(pattern),
+	}
+	for _, row := range pattern {
+		for _, symbol := range row {
+			if symbol == ' ' {
+				r.Ingredients = append(r.Ingredients, None)
+			} else {
+				r.Ingredients = append(r.Ingredients, item(k[symbol]))
+			}
+		}
+	}
+	return r
+}
+
+func shapeless(result string, count int, ingredients ...string) *Recipe {
+	r := &Recipe{Result: item(result), Count: count}
+	for _, i := range ingredients {
+		r.Ingredients = append(r.Ingredients, item(i))
+	}
+	return r
+}
+
+func smelt(input, result string, experience float32) *Smelting {
+	return &Smelting{item(input), item(result), experience}
+}
@@ -0,0 +1,101 @@
+package recipes
+
+import (
+	. "github.com/jeffh/goexpect"
+	"testing"
+)
+
+var (
+	log     = item("log:0")
+	planks  = item("planks:0")
+	stick   = item("stick")
+	cobble  = item("cobblestone")
+	pickaxe = item("wooden_pickaxe")
+)
+
+func TestItemsAreParsedFromNames(t *testing.T) {
+	it := NewIt(t)
+	it.Expects(item("stone"), ToEqual, Item{1, 0})
+	it.Expects(item("planks:2"), ToEqual, Item{5, 2})
+	it.Expects(item("wool:*"), ToEqual, Item{35, AnyDamage})
+	it.Expects(item("wool:*").Matches(Item{35, 14}), ToBeTrue)
+	it.Expects(item("wool:1").Matches(Item{35, 14}), Not(ToBeTrue))
+	it.Expects(item("dye:4").String(), ToEqual, "lapis_lazuli")
+}
+
+func TestRecipesMatchShapedGrids(t *testing.T) {
+	it := NewIt(t)
+	n := None
+	grid := []Item{
+		n, n, n,
+		planks, planks, n,
+		stick, planks, n,
+	}
+	// stairs and axes are mirrored
+	r := Match([]Item{
+		n, planks, planks,
+		n, stick, planks,
+		n, stick, n,
+	}, 3)
+	it.Expects(r.Result, ToEqual, item("wooden_axe"))
+	it.Expects(Match(grid, 3), ToBeNil)
+
+	r = Match([]Item{
+		n, planks,
+		n, planks,
+	}, 2)
+	it.Expects(r.Result, ToEqual, stick)
+	it.Expects(r.Count, ToEqual, 4)
+	it.Expects(r.GridSize(), ToEqual, 2)
+
+	// other variants of planks work too
+	r = Match([]Item{
+		Item{5, 3}, Item{5, 1},
+		Item{5, 2}, planks,
+	}, 2)
+	it.Expects(r.Result, ToEqual, item("crafting_table"))
+}
+
+func TestRecipesMatchShapelessGrids(t *testing.T) {
+	it := NewIt(t)
+	n := None
+	r := Match([]Item{
+		n, n, n,
+		n, n, item("flint"),
+		item("iron_ingot"), n, n,
+	}, 3)
+	it.Expects(r.Result, ToEqual, item("flint_and_steel"))
+	it.Expects(r.IsShaped(), Not(ToBeTrue))
+	it.Expects(Match([]Item{item("flint"), n, n, n}, 2), ToBeNil)
+
+	r = Match([]Item{n, Item{17, 2}, n, n}, 2)
+	it.Expects(r.Result, ToEqual, Item{5, 2})
+
+	r = Match([]Item{item("dye:1"), item("dye:15"), item("dye:4"), item("dye:1")}, 2)
+	it.Expects(r.Result, ToEqual, item("dye:13"))
+	it.Expects(r.Count, ToEqual, 4)
+}
+
+func TestRecipesCanBeFoundByResult(t *testing.T) {
+	it := NewIt(t)
+	it.Expects(For(item("torch")), ToBeLengthOf, 2)
+	it.Expects(For(item("planks:*")), ToBeLengthOf, 4)
+	it.Expects(For(item("bedrock")), ToBeEmpty)
+	it.Expects(For(pickaxe)[0].Needs(), ToEqual, []Need{{item("planks:*"), 3}, {stick, 2}})
+	it.Expects(For(item("furnace"))[0].GridSize(), ToEqual, 3)
+}
+
+func TestSmelting(t *testing.T) {
+	it := NewIt(t)
+	s, ok := Smelt(item("iron_ore"))
+	it.Expects(ok, ToBeTrue)
+	it.Expects(s.Result, ToEqual, item("iron_ingot"))
+
+	s, ok = Smelt(Item{17, 3})
+	it.Expects(ok, ToBeTrue)
+	it.Expects(s.Result, ToEqual, item("coal:1"))
+
+	_, ok = Smelt(item("dirt"))
+	it.Expects(ok, Not(ToBeTrue))
+	it.Expects(SmeltingFor(item("glass")), ToBeLengthOf, 1)
+}
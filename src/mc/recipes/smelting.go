@@ -0,0 +1,24 @@
+package recipes
+
+var smelting = []*Smelting{
+	smelt("iron_ore", "iron_ingot", 0.7),
+	smelt("gold_ore", "gold_ingot", 1),
+	smelt("diamond_ore", "diamond", 1),
+	smelt("emerald_ore", "emerald", 1),
+	smelt("coal_ore", "coal:0", 0.1),
+	smelt("redstone_ore", "redstone", 0.7),
+	smelt("lapis_ore", "dye:4", 0.2),
+	smelt("quartz_ore", "quartz", 0.2),
+	smelt("sand", "glass", 0.1),
+	smelt("cobblestone", "stone", 0.1),
+	smelt("clay_ball", "brick", 0.3),
+	smelt("clay", "hardened_clay", 0.35),
+	smelt("netherrack", "netherbrick", 0.1),
+	smelt("cactus", "dye:2", 0.2),
+	smelt("log:*", "coal:1", 0.15),
+	smelt("porkchop", "cooked_porkchop", 0.35),
+	smelt("beef", "cooked_beef", 0.35),
+	smelt("chicken", "cooked_chicken", 0.35),
+	smelt("fish", "cooked_fished", 0.35),
+	smelt("potato", "baked_potato", 0.35),
+}
@@ -0,0 +1,183 @@
+package registry
+
+var woodVariants = []Variant{{0, "oak"}, {1, "spruce"}, {2, "birch"}, {3, "jungle"}}
+
+var colorVariants = []Variant{
+	{0, "white"}, {1, "orange"}, {2, "magenta"}, {3, "light_blue"},
+	{4, "yellow"}, {5, "lime"}, {6, "pink"}, {7, "gray"},
+	{8, "silver"}, {9, "cyan"}, {10, "purple"}, {11, "blue"},
+	{12, "brown"}, {13, "green"}, {14, "red"}, {15, "black"},
+}
+
+var stoneSlabVariants = []Variant{
+	{0, "stone"}, {1, "sandstone"}, {2, "wood"}, {3, "cobblestone"},
+	{4, "brick"}, {5, "stone_brick"}, {6, "nether_brick"}, {7, "quartz"},
+}
+
+// Blocks of protocol 74, ordered by id.
+var blocks = []Block{
+	{ID: 0, Name: "air", Transparent: true},
+	{ID: 1, Name: "stone", Solid: true, Hardness: 1.5, Tool: Pickaxe, Harvest: Wood},
+	{ID: 2, Name: "grass", Solid: true, Hardness: 0.6, Tool: Shovel},
+	{ID: 3, Name: "dirt", Solid: true, Hardness: 0.5, Tool: Shovel},
+	{ID: 4, Name: "cobblestone", Solid: true, Hardness: 2, Tool: Pickaxe, Harvest: Wood},
+	{ID: 5, Name: "planks", Variants: woodVariants, Solid: true, Hardness: 2, Tool: Axe},
+	{ID: 6, Name: "sapling", Variants: woodVariants, Transparent: true},
+	{ID: 7, Name: "bedrock", Solid: true, Hardness: Unbreakable},
+	{ID: 8, Name: "flowing_water", Transparent: true, Hardness: 100},
+	{ID: 9, Name: "water", Transparent: true, Hardness: 100},
+	{ID: 10, Name: "flowing_lava", Hardness: 100, Light: 15},
+	{ID: 11, Name: "lava", Hardness: 100, Light: 15},
+	{ID: 12, Name: "sand", Solid: true, Hardness: 0.5, Tool: Shovel},
+	{ID: 13, Name: "gravel", Solid: true, Hardness: 0.6, Tool: Shovel},
+	{ID: 14, Name: "gold_ore", Solid: true, Hardness: 3, Tool: Pickaxe, Harvest: Iron},
+	{ID: 15, Name: "iron_ore", Solid: true, Hardness: 3, Tool: Pickaxe, Harvest: Stone},
+	{ID: 16, Name: "coal_ore", Solid: true, Hardness: 3, Tool: Pickaxe, Harvest: Wood},
+	{ID: 17, Name: "log", Variants: woodVariants, Solid: true, Hardness: 2, Tool: Axe},
+	{ID: 18, Name: "leaves", Variants: woodVariants, Solid: true, Transparent: true, Hardness: 0.2, Tool: Shears},
+	{ID: 19, Name: "sponge", Solid: true, Hardness: 0.6},
+	{ID: 20, Name: "glass", Solid: true, Transparent: true, Hardness: 0.3},
+	{ID: 21, Name: "lapis_ore", Solid: true, Hardness: 3, Tool: Pickaxe, Harvest: Stone},
+	{ID: 22, Name: "lapis_block", Solid: true, Hardness: 3, Tool: Pickaxe, Harvest: Stone},
+	{ID: 23, Name: "dispenser", Solid: true, Hardness: 3.5, Tool: Pickaxe, Harvest: Wood},
+	{ID: 24, Name: "sandstone", Variants: []Variant{{0, "sandstone"}, {1, "chiseled"}, {2, "smooth"}}, Solid: true, Hardness: 0.8, Tool: Pickaxe, Harvest: Wood},
+	{ID: 25, Name: "noteblock", Solid: true, Hardness: 0.8, Tool: Axe},
+	{ID: 26, Name: "bed", Solid: true, Transparent: true, Hardness: 0.2},
+	{ID: 27, Name: "golden_rail", Transparent: true, Hardness: 0.7, Tool: Pickaxe},
+	{ID: 28, Name: "detector_rail", Transparent: true, Hardness: 0.7, Tool: Pickaxe},
+	{ID: 29, Name: "sticky_piston", Solid: true, Transparent: true, Hardness: 0.5},
+	{ID: 30, Name: "web", Transparent: true, Hardness: 4, Tool: Sword, Harvest: Wood},
+	{ID: 31, Name: "tallgrass", Variants: []Variant{{0, "shrub"}, {1, "tall_grass"}, {2, "fern"}}, Transparent: true},
+	{ID: 32, Name: "deadbush", Transparent: true},
+	{ID: 33, Name: "piston", Solid: true, Transparent: true, Hardness: 0.5},
+	{ID: 34, Name: "piston_head", Solid: true, Transparent: true, Hardness: 0.5},
+	{ID: 35, Name: "wool", Variants: colorVariants, Solid: true, Hardness: 0.8, Tool: Shears},
+	{ID: 36, Name: "piston_extension", Transparent: true, Hardness: Unbreakable},
+	{ID: 37, Name: "yellow_flower", Transparent: true},
+	{ID: 38, Name: "red_flower", Transparent: true},
+	{ID: 39, Name: "brown_mushroom", Transparent: true, Light: 1},
+	{ID: 40, Name: "red_mushroom", Transparent: true},
+	{ID: 41, Name: "gold_block", Solid: true, Hardness: 3, Tool: Pickaxe, Harvest: Iron},
+	{ID: 42, Name: "iron_block", Solid: true, Hardness: 5, Tool: Pickaxe, Harvest: Stone},
+	{ID: 43, Name: "double_stone_slab", Variants: stoneSlabVariants, Solid: true, Hardness: 2, Tool: Pickaxe, Harvest: Wood},
+	{ID: 44, Name: "stone_slab", Variants: stoneSlabVariants, Solid: true, Transparent: true, Hardness: 2, Tool: Pickaxe, Harvest: Wood},
+	{ID: 45, Name: "brick_block", Solid: true, Hardness: 2, Tool: Pickaxe, Harvest: Wood},
+	{ID: 46, Name: "tnt", Solid: true},
+	{ID: 47, Name: "bookshelf", Solid: true, Hardness: 1.5, Tool: Axe},
+	{ID: 48, Name: "mossy_cobblestone", Solid: true, Hardness: 2, Tool: Pickaxe, Harvest: Wood},
+	{ID: 49, Name: "obsidian", Solid: true, Hardness: 50, Tool: Pickaxe, Harvest: Diamond},
+	{ID: 50, Name: "torch", Transparent: true, Light: 14},
+	{ID: 51, Name: "fire", Transparent: true, Light: 15},
+	{ID: 52, Name: "mob_spawner", Solid: true, Transparent: true, Hardness: 5, Tool: Pickaxe, Harvest: Wood},
+	{ID: 53, Name: "oak_stairs", Solid: true, Transparent: true, Hardness: 2, Tool: Axe},
+	{ID: 54, Name: "chest", Solid: true, Transparent: true, Hardness: 2.5, Tool: Axe},
+	{ID: 55, Name: "redstone_wire", Transparent: true},
+	{ID: 56, Name: "diamond_ore", Solid: true, Hardness: 3, Tool: Pickaxe, Harvest: Iron},
+	{ID: 57, Name: "diamond_block", Solid: true, Hardness: 5, Tool: Pickaxe, Harvest: Iron},
+	{ID: 58, Name: "crafting_table", Solid: true, Hardness: 2.5, Tool: Axe},
+	{ID: 59, Name: "wheat", Transparent: true},
+	{ID: 60, Name: "farmland", Solid: true, Hardness: 0.6, Tool: Shovel},
+	{ID: 61, Name: "furnace", Solid: true, Hardness: 3.5, Tool: Pickaxe, Harvest: Wood},
+	{ID: 62, Name: "lit_furnace", Solid: true, Hardness: 3.5, Light: 13, Tool: Pickaxe, Harvest: Wood},
+	{ID: 63, Name: "standing_sign", Transparent: true, Hardness: 1, Tool: Axe},
+	{ID: 64, Name: "wooden_door", Solid: true, Transparent: true, Hardness: 3, Tool: Axe},
+	{ID: 65, Name: "ladder", Transparent: true, Hardness: 0.4, Tool: Axe},
+	{ID: 66, Name: "rail", Transparent: true, Hardness: 0.7, Tool: Pickaxe},
+	{ID: 67, Name: "stone_stairs", Solid: true, Transparent: true, Hardness: 2, Tool: Pickaxe, Harvest: Wood},
+	{ID: 68, Name: "wall_sign", Transparent: true, Hardness: 1, Tool: Axe},
+	{ID: 69, Name: "lever", Transparent: true, Hardness: 0.5},
+	{ID: 70, Name: "stone_pressure_plate", Transparent: true, Hardness: 0.5, Tool: Pickaxe, Harvest: Wood},
+	{ID: 71, Name: "iron_door", Solid: true, Transparent: true, Hardness: 5, Tool: Pickaxe, Harvest: Wood},
+	{ID: 72, Name: "wooden_pressure_plate", Transparent: true, Hardness: 0.5, Tool: Axe},
+	{ID: 73, Name: "redstone_ore", Solid: true, Hardness: 3, Tool: Pickaxe, Harvest: Iron},
+	{ID: 74, Name: "lit_redstone_ore", Solid: true, Hardness: 3, Light: 9, Tool: Pickaxe, Harvest: Iron},
+	{ID: 75, Name: "unlit_redstone_torch", Transparent: true},
+	{ID: 76, Name: "redstone_torch", Transparent: true, Light: 7},
+	{ID: 77, Name: "stone_button", Transparent: true, Hardness: 0.5},
+	{ID: 78, Name: "snow_layer", Transparent: true, Hardness: 0.1, Tool: Shovel, Harvest: Wood},
+	{ID: 79, Name: "ice", Solid: true, Transparent: true, Hardness: 0.5, Tool: Pickaxe},
+	{ID: 80, Name: "snow", Solid: true, Hardness: 0.2, Tool: Shovel, Harvest: Wood},
+	{ID: 81, Name: "cactus", Solid: true, Transparent: true, Hardness: 0.4},
+	{ID: 82, Name: "clay", Solid: true, Hardness: 0.6, Tool: Shovel},
+	{ID: 83, Name: "reeds", Transparent: true},
+	{ID: 84, Name: "jukebox", Solid: true, Hardness: 2, Tool: Axe},
+	{ID: 85, Name: "fence", Solid: true, Transparent: true, Hardness: 2, Tool: Axe},
+	{ID: 86, Name: "pumpkin", Solid: true, Hardness: 1, Tool: Axe},
+	{ID: 87, Name: "netherrack", Solid: true, Hardness: 0.4, Tool: Pickaxe, Harvest: Wood},
+	{ID: 88, Name: "soul_sand", Solid: true, Hardness: 0.5, Tool: Shovel},
+	{ID: 89, Name: "glowstone", Solid: true, Hardness: 0.3, Light: 15},
+	{ID: 90, Name: "portal", Transparent: true, Hardness: Unbreakable, Light: 11},
+	{ID: 91, Name: "lit_pumpkin", Solid: true, Hardness: 1, Light: 15, Tool: Axe},
+	{ID: 92, Name: "cake", Solid: true, Transparent: true, Hardness: 0.5},
+	{ID: 93, Name: "unpowered_repeater", Solid: true, Transparent: true},
+	{ID: 94, Name: "powered_repeater", Solid: true, Transparent: true, Light: 9},
+	{ID: 95, Name: "locked_chest", Solid: true, Transparent: true, Light: 15},
+	{ID: 96, Name: "trapdoor", Solid: true, Transparent: true, Hardness: 3, Tool: Axe},
+	{ID: 97, Name: "monster_egg", Variants: []Variant{{0, "stone"}, {1, "cobblestone"}, {2, "stone_brick"}}, Solid: true, Hardness: 0.75},
+	{ID: 98, Name: "stonebrick", Variants: []Variant{{0, "stonebrick"}, {1, "mossy"}, {2, "cracked"}, {3, "chiseled"}}, Solid: true, Hardness: 1.5, Tool: Pickaxe, Harvest: Wood},
+	{ID: 99, Name: "brown_mushroom_block", Solid: true, Hardness: 0.2, Tool: Axe},
+	{ID: 100, Name: "red_mushroom_block", Solid: true, Hardness: 0.2, Tool: Axe},
+	{ID: 101, Name: "iron_bars", Solid: true, Transparent: true, Hardness: 5, Tool: Pickaxe, Harvest: Wood},
+	{ID: 102, Name: "glass_pane", Solid: true, Transparent: true, Hardness: 0.3},
+	{ID: 103, Name: "melon_block", Solid: true, Hardness: 1, Tool: Axe},
+	{ID: 104, Name: "pumpkin_stem", Transparent: true},
+	{ID: 105, Name: "melon_stem", Transparent: true},
+	{ID: 106, Name: "vine", Transparent: true, Hardness: 0.2, Tool: Shears},
+	{ID: 107, Name: "fence_gate", Solid: true, Transparent: true, Hardness: 2, Tool: Axe},
+	{ID: 108, Name: "brick_stairs", Solid: true, Transparent: true, Hardness: 2, Tool: Pickaxe, Harvest: Wood},
+	{ID: 109, Name: "stone_brick_stairs", Solid: true, Transparent: true, Hardness: 1.5, Tool: Pickaxe, Harvest: Wood},
+	{ID: 110, Name: "mycelium", Solid: true, Hardness: 0.6, Tool: Shovel},
+	{ID: 111, Name: "waterlily", Solid: true, Transparent: true},
+	{ID: 112, Name: "nether_brick", Solid: true, Hardness: 2, Tool: Pickaxe, Harvest: Wood},
+	{ID: 113, Name: "nether_brick_fence", Solid: true, Transparent: true, Hardness: 2, Tool: Pickaxe, Harvest: Wood},
+	{ID: 114, Name: "nether_brick_stairs", Solid: true, Transparent: true, Hardness: 2, Tool: Pickaxe, Harvest: Wood},
+	{ID: 115, Name: "nether_wart", Transparent: true},
+	{ID: 116, Name: "enchanting_table", Solid: true, Transparent: true, Hardness: 5, Tool: Pickaxe, Harvest: Wood},
+	{ID: 117, Name: "brewing_stand", Solid: true, Transparent: true, Hardness: 0.5, Light: 1, Tool: Pickaxe, Harvest: Wood},
+	{ID: 118, Name: "cauldron", Solid: true, Transparent: true, Hardness: 2, Tool: Pickaxe, Harvest: Wood},
+	{ID: 119, Name: "end_portal", Transparent: true, Hardness: Unbreakable, Light: 15},
+	{ID: 120, Name: "end_portal_frame", Solid: true, Transparent: true, Hardness: Unbreakable, Light: 1},
+	{ID: 121, Name: "end_stone", Solid: true, Hardness: 3, Tool: Pickaxe, Harvest: Wood},
+	{ID: 122, Name: "dragon_egg", Solid: true, Transparent: true, Hardness: 3, Light: 1},
+	{ID: 123, Name: "redstone_lamp", Solid: true, Hardness: 0.3},
+	{ID: 124, Name: "lit_redstone_lamp", Solid: true, Hardness: 0.3, Light: 15},
+	{ID: 125, Name: "double_wooden_slab", Variants: woodVariants, Solid: true, Hardness: 2, Tool: Axe},
+	{ID: 126, Name: "wooden_slab", Variants: woodVariants, Solid: true, Transparent: true, Hardness: 2, Tool: Axe},
+	{ID: 127, Name: "cocoa", Solid: true, Transparent: true, Hardness: 0.2, Tool: Axe},
+	{ID: 128, Name: "sandstone_stairs", Solid: true, Transparent: true, Hardness: 0.8, Tool: Pickaxe, Harvest: Wood},
+	{ID: 129, Name: "emerald_ore", Solid: true, Hardness: 3, Tool: Pickaxe, Harvest: Iron},
+	{ID: 130, Name: "ender_chest", Solid: true, Transparent: true, Hardness: 22.5, Light: 7, Tool: Pickaxe, Harvest: Wood},
+	{ID: 131, Name: "tripwire_hook", Transparent: true},
+	{ID: 132, Name: "tripwire", Transparent: true},
+	{ID: 133, Name: "emerald_block", Solid: true, Hardness: 5, Tool: Pickaxe, Harvest: Iron},
+	{ID: 134, Name: "spruce_stairs", Solid: true, Transparent: true, Hardness: 2, Tool: Axe},
+	{ID: 135, Name: "birch_stairs", Solid: true, Transparent: true, Hardness: 2, Tool: Axe},
+	{ID: 136, Name: "jungle_stairs", Solid: true, Transparent: true, Hardness: 2, Tool: Axe},
+	{ID: 137, Name: "command_block", Solid: true, Hardness: Unbreakable},
+	{ID: 138, Name: "beacon", Solid: true, Transparent: true, Hardness: 3, Light: 15},
+	{ID: 139, Name: "cobblestone_wall", Variants: []Variant{{0, "cobblestone"}, {1, "mossy"}}, Solid: true, Transparent: true, Hardness: 2, Tool: Pickaxe, Harvest: Wood},
+	{ID: 140, Name: "flower_pot", Solid: true, Transparent: true},
+	{ID: 141, Name: "carrots", Transparent: true},
+	{ID: 142, Name: "potatoes", Transparent: true},
+	{ID: 143, Name: "wooden_button", Transparent: true, Hardness: 0.5},
+	{ID: 144, Name: "skull", Solid: true, Transparent: true, Hardness: 1},
+	{ID: 145, Name: "anvil", Variants: []Variant{{0, "intact"}, {4, "slightly_damaged"}, {8, "very_damaged"}}, Solid: true, Transparent: true, Hardness: 5, Tool: Pickaxe, Harvest: Wood},
+	{ID: 146, Name: "trapped_chest", Solid: true, Transparent: true, Hardness: 2.5, Tool: Axe},
+	{ID: 147, Name: "light_weighted_pressure_plate", Transparent: true, Hardness: 0.5, Tool: Pickaxe, Harvest: Wood},
+	{ID: 148, Name: "heavy_weighted_pressure_plate", Transparent: true, Hardness: 0.5, Tool: Pickaxe, Harvest: Wood},
+	{ID: 149, Name: "unpowered_comparator", Solid: true, Transparent: true},
+	{ID: 150, Name: "powered_comparator", Solid: true, Transparent: true, Light: 9},
+	{ID: 151, Name: "daylight_detector", Solid: true, Transparent: true, Hardness: 0.2, Tool: Axe},
+	{ID: 152, Name: "redstone_block", Solid: true, Hardness: 5, Tool: Pickaxe, Harvest: Wood},
+	{ID: 153, Name: "quartz_ore", Solid: true, Hardness: 3, Tool: Pickaxe, Harvest: Wood},
+	{ID: 154, Name: "hopper", Solid: true, Transparent: true, Hardness: 3, Tool: Pickaxe, Harvest: Wood},
+	{ID: 155, Name: "quartz_block", Variants: []Variant{{0, "quartz_block"}, {1, "chiseled"}, {2, "pillar"}}, Solid: true, Hardness: 0.8, Tool: Pickaxe, Harvest: Wood},
+	{ID: 156, Name: "quartz_stairs", Solid: true, Transparent: true, Hardness: 0.8, Tool: Pickaxe, Harvest: Wood},
+	{ID: 157, Name: "activator_rail", Transparent: true, Hardness: 0.7, Tool: Pickaxe},
+	{ID: 158, Name: "dropper", Solid: true, Hardness: 3.5, Tool: Pickaxe, Harvest: Wood},
+	{ID: 159, Name: "stained_hardened_clay", Variants: colorVariants, Solid: true, Hardness: 1.25, Tool: Pickaxe, Harvest: Wood},
+	{ID: 170, Name: "hay_block", Solid: true, Hardness: 0.5},
+	{ID: 171, Name: "carpet", Variants: colorVariants, Solid: true, Transparent: true, Hardness: 0.1},
+	{ID: 172, Name: "hardened_clay", Solid: true, Hardness: 1.25, Tool: Pickaxe, Harvest: Wood},
+	{ID: 173, Name: "coal_block", Solid: true, Hardness: 5, Tool: Pickaxe, Harvest: Wood},
+}
@@ -0,0 +1,191 @@
+package registry
+
+// Durabilities of tools made of each material.
+const (
+	WoodDurability    = 59
+	StoneDurability   = 131
+	IronDurability    = 250
+	DiamondDurability = 1561
+	GoldDurability    = 32
+)
+
+var dyeVariants = []Variant{
+	{0, "ink_sac"}, {1, "red"}, {2, "green"}, {3, "cocoa_beans"},
+	{4, "lapis_lazuli"}, {5, "purple"}, {6, "cyan"}, {7, "light_gray"},
+	{8, "gray"}, {9, "pink"}, {10, "lime"}, {11, "yellow"},
+	{12, "light_blue"}, {13, "magenta"}, {14, "orange"}, {15, "bone_meal"},
+}
+
+// Items of protocol 74 that aren't blocks, ordered by id.
+var items = []Item{
+	{ID: 256, Name: "iron_shovel", MaxStackSize: 1, Durability: IronDurability, Tool: Shovel, Material: Iron},
+	{ID: 257, Name: "iron_pickaxe", MaxStackSize: 1, Durability: IronDurability, Tool: Pickaxe, Material: Iron},
+	{ID: 258, Name: "iron_axe", MaxStackSize: 1, Durability: IronDurability, Tool: Axe, Material: Iron},
+	{ID: 259, Name: "flint_and_steel", MaxStackSize: 1, Durability: 64},
+	{ID: 260, Name: "apple", MaxStackSize: 64, Food: &Food{Hunger: 4, SaturationModifier: 0.3}},
+	{ID: 261, Name: "bow", MaxStackSize: 1, Durability: 384},
+	{ID: 262, Name: "arrow", MaxStackSize: 64},
+	{ID: 263, Name: "coal", Variants: []Variant{{0, "coal"}, {1, "charcoal"}}, MaxStackSize: 64},
+	{ID: 264, Name: "diamond", MaxStackSize: 64},
+	{ID: 265, Name: "iron_ingot", MaxStackSize: 64},
+	{ID: 266, Name: "gold_ingot", MaxStackSize: 64},
+	{ID: 267, Name: "iron_sword", MaxStackSize: 1, Durability: IronDurability, Tool: Sword, Material: Iron},
+	{ID: 268, Name: "wooden_sword", MaxStackSize: 1, Durability: WoodDurability, Tool: Sword, Material: Wood},
+	{ID: 269, Name: "wooden_shovel", MaxStackSize: 1, Durability: WoodDurability, Tool: Shovel, Material: Wood},
+	{ID: 270, Name: "wooden_pickaxe", MaxStackSize: 1, Durability: WoodDurability, Tool: Pickaxe, Material: Wood},
+	{ID: 271, Name: "wooden_axe", MaxStackSize: 1, Durability: WoodDurability, Tool: Axe, Material: Wood},
+	{ID: 272, Name: "stone_sword", MaxStackSize: 1, Durability: StoneDurability, Tool: Sword, Material: Stone},
+	{ID: 273, Name: "stone_shovel", MaxStackSize: 1, Durability: StoneDurability, Tool: Shovel, Material: Stone},
+	{ID: 274, Name: "stone_pickaxe", MaxStackSize: 1, Durability: StoneDurability, Tool: Pickaxe, Material: Stone},
+	{ID: 275, Name: "stone_axe", MaxStackSize: 1, Durability: StoneDurability, Tool: Axe, Material: Stone},
+	{ID: 276, Name: "diamond_sword", MaxStackSize: 1, Durability: DiamondDurability, Tool: Sword, Material: Diamond},
+	{ID: 277, Name: "diamond_shovel", MaxStackSize: 1, Durability: DiamondDurability, Tool: Shovel, Material: Diamond},
+	{ID: 278, Name: "diamond_pickaxe", MaxStackSize: 1, Durability: DiamondDurability, Tool: Pickaxe, Material: Diamond},
+	{ID: 279, Name: "diamond_axe", MaxStackSize: 1, Durability: DiamondDurability, Tool: Axe, Material: Diamond},
+	{ID: 280, Name: "stick", MaxStackSize: 64},
+	{ID: 281, Name: "bowl", MaxStackSize: 64},
+	{ID: 282, Name: "mushroom_stew", MaxStackSize: 1, Food: &Food{Hunger: 6, SaturationModifier: 0.6}},
+	{ID: 283, Name: "golden_sword", MaxStackSize: 1, Durability: GoldDurability, Tool: Sword, Material: Gold},
+	{ID: 284, Name: "golden_shovel", MaxStackSize: 1, Durability: GoldDurability, Tool: Shovel, Material: Gold},
+	{ID: 285, Name: "golden_pickaxe", MaxStackSize: 1, Durability: GoldDurability, Tool: Pickaxe, Material: Gold},
+	{ID: 286, Name: "golden_axe", MaxStackSize: 1, Durability: GoldDurability, Tool: Axe, Material: Gold},
+	{ID: 287, Name: "string", MaxStackSize: 64},
+	{ID: 288, Name: "feather", MaxStackSize: 64},
+	{ID: 289, Name: "gunpowder", MaxStackSize: 64},
+	{ID: 290, Name: "wooden_hoe", MaxStackSize: 1, Durability: WoodDurability, Tool: Hoe, Material: Wood},
+	{ID: 291, Name: "stone_hoe", MaxStackSize: 1, Durability: StoneDurability, Tool: Hoe, Material: Stone},
+	{ID: 292, Name: "iron_hoe", MaxStackSize: 1, Durability: IronDurability, Tool: Hoe, Material: Iron},
+	{ID: 293, Name: "diamond_hoe", MaxStackSize: 1, Durability: DiamondDurability, Tool: Hoe, Material: Diamond},
+	{ID: 294, Name: "golden_hoe", MaxStackSize: 1, Durability: GoldDurability, Tool: Hoe, Material: Gold},
+	{ID: 295, Name: "wheat_seeds", MaxStackSize: 64},
+	{ID: 296, Name: "wheat", MaxStackSize: 64},
+	{ID: 297, Name: "bread", MaxStackSize: 64, Food: &Food{Hunger: 5, SaturationModifier: 0.6}},
+	{ID: 298, Name: "leather_helmet", MaxStackSize: 1, Durability: 55},
+	{ID: 299, Name: "leather_chestplate", MaxStackSize: 1, Durability: 80},
+	{ID: 300, Name: "leather_leggings", MaxStackSize: 1, Durability: 75},
+	{ID: 301, Name: "leather_boots", MaxStackSize: 1, Durability: 65},
+	{ID: 302, Name: "chainmail_helmet", MaxStackSize: 1, Durability: 165},
+	{ID: 303, Name: "chainmail_chestplate", MaxStackSize: 1, Durability: 240},
+	{ID: 304, Name: "chainmail_leggings", MaxStackSize: 1, Durability: 225},
+	{ID: 305, Name: "chainmail_boots", MaxStackSize: 1, Durability: 195},
+	{ID: 306, Name: "iron_helmet", MaxStackSize: 1, Durability: 165},
+	{ID: 307, Name: "iron_chestplate", MaxStackSize: 1, Durability: 240},
+	{ID: 308, Name: "iron_leggings", MaxStackSize: 1, Durability: 225},
+	{ID: 309, Name: "iron_boots", MaxStackSize: 1, Durability: 195},
+	{ID: 310, Name: "diamond_helmet", MaxStackSize: 1, Durability: 363},
+	{ID: 311, Name: "diamond_chestplate", MaxStackSize: 1, Durability: 528},
+	{ID: 312, Name: "diamond_leggings", MaxStackSize: 1, Durability: 495},
+	{ID: 313, Name: "diamond_boots", MaxStackSize: 1, Durability: 429},
+	{ID: 314, Name: "golden_helmet", MaxStackSize: 1, Durability: 77},
+	{ID: 315, Name: "golden_chestplate", MaxStackSize: 1, Durability: 112},
+	{ID: 316, Name: "golden_leggings", MaxStackSize: 1, Durability: 105},
+	{ID: 317, Name: "golden_boots", MaxStackSize: 1, Durability: 91},
+	{ID: 318, Name: "flint", MaxStackSize: 64},
+	{ID: 319, Name: "porkchop", MaxStackSize: 64, Food: &Food{Hunger: 3, SaturationModifier: 0.3}},
+	{ID: 320, Name: "cooked_porkchop", MaxStackSize: 64, Food: &Food{Hunger: 8, SaturationModifier: 0.8}},
+	{ID: 321, Name: "painting", MaxStackSize: 64},
+	{ID: 322, Name: "golden_apple", Variants: []Variant{{0, "golden_apple"}, {1, "enchanted"}}, MaxStackSize: 64, Food: &Food{Hunger: 4, SaturationModifier: 1.2}},
+	{ID: 323, Name: "sign", MaxStackSize: 16},
+	{ID: 324, Name: "wooden_door", MaxStackSize: 1},
+	{ID: 325, Name: "bucket", MaxStackSize: 16},
+	{ID: 326, Name: "water_bucket", MaxStackSize: 1},
+	{ID: 327, Name: "lava_bucket", MaxStackSize: 1},
+	{ID: 328, Name: "minecart", MaxStackSize: 1},
+	{ID: 329, Name: "saddle", MaxStackSize: 1},
+	{ID: 330, Name: "iron_door", MaxStackSize: 1},
+	{ID: 331, Name: "redstone", MaxStackSize: 64},
+	{ID: 332, Name: "snowball", MaxStackSize: 16},
+	{ID: 333, Name: "boat", MaxStackSize: 1},
+	{ID: 334, Name: "leather", MaxStackSize: 64},
+	{ID: 335, Name: "milk_bucket", MaxStackSize: 1},
+	{ID: 336, Name: "brick", MaxStackSize: 64},
+	{ID: 337, Name: "clay_ball", MaxStackSize: 64},
+	{ID: 338, Name: "reeds", MaxStackSize: 64},
+	{ID: 339, Name: "paper", MaxStackSize: 64},
+	{ID: 340, Name: "book", MaxStackSize: 64},
+	{ID: 341, Name: "slime_ball", MaxStackSize: 64},
+	{ID: 342, Name: "chest_minecart", MaxStackSize: 1},
+	{ID: 343, Name: "furnace_minecart", MaxStackSize: 1},
+	{ID: 344, Name: "egg", MaxStackSize: 16},
+	{ID: 345, Name: "compass", MaxStackSize: 64},
+	{ID: 346, Name: "fishing_rod", MaxStackSize: 1, Durability: 64},
+	{ID: 347, Name: "clock", MaxStackSize: 64},
+	{ID: 348, Name: "glowstone_dust", MaxStackSize: 64},
+	{ID: 349, Name: "fish", MaxStackSize: 64, Food: &Food{Hunger: 2, SaturationModifier: 0.3}},
+	{ID: 350, Name: "cooked_fished", MaxStackSize: 64, Food: &Food{Hunger: 5, SaturationModifier: 0.6}},
+	{ID: 351, Name: "dye", Variants: dyeVariants, MaxStackSize: 64},
+	{ID: 352, Name: "bone", MaxStackSize: 64},
+	{ID: 353, Name: "sugar", MaxStackSize: 64},
+	{ID: 354, Name: "cake", MaxStackSize: 1},
+	{ID: 355, Name: "bed", MaxStackSize: 1},
+	{ID: 356, Name: "repeater", MaxStackSize: 64},
+	{ID: 357, Name: "cookie", MaxStackSize: 64, Food: &Food{Hunger: 2, SaturationModifier: 0.1}},
+	{ID: 358, Name: "filled_map", MaxStackSize: 64},
+	{ID: 359, Name: "shears", MaxStackSize: 1, Durability: 238, Tool: Shears},
+	{ID: 360, Name: "melon", MaxStackSize: 64, Food: &Food{Hunger: 2, SaturationModifier: 0.3}},
+	{ID: 361, Name: "pumpkin_seeds", MaxStackSize: 64},
+	{ID: 362, Name: "melon_seeds", MaxStackSize: 64},
+	{ID: 363, Name: "beef", MaxStackSize: 64, Food: &Food{Hunger: 3, SaturationModifier: 0.3}},
+	{ID: 364, Name: "cooked_beef", MaxStackSize: 64, Food: &Food{Hunger: 8, SaturationModifier: 0.8}},
+	{ID: 365, Name: "chicken", MaxStackSize: 64, Food: &Food{Hunger: 2, SaturationModifier: 0.3, IsHarmful: true}},
+	{ID: 366, Name: "cooked_chicken", MaxStackSize: 64, Food: &Food{Hunger: 6, SaturationModifier: 0.6}},
+	{ID: 367, Name: "rotten_flesh", MaxStackSize: 64, Food: &Food{Hunger: 4, SaturationModifier: 0.1, IsHarmful: true}},
+	{ID: 368, Name: "ender_pearl", MaxStackSize: 16},
+	{ID: 369, Name: "blaze_rod", MaxStackSize: 64},
+	{ID: 370, Name: "ghast_tear", MaxStackSize: 64},
+	{ID: 371, Name: "gold_nugget", MaxStackSize: 64},
+	{ID: 372, Name: "nether_wart", MaxStackSize: 64},
+	{ID: 373, Name: "potion", MaxStackSize: 1},
+	{ID: 374, Name: "glass_bottle", MaxStackSize: 64},
+	{ID: 375, Name: "spider_eye", MaxStackSize: 64, Food: &Food{Hunger: 2, SaturationModifier: 0.8, IsHarmful: true}},
+	{ID: 376, Name: "fermented_spider_eye", MaxStackSize: 64},
+	{ID: 377, Name: "blaze_powder", MaxStackSize: 64},
+	{ID: 378, Name: "magma_cream", MaxStackSize: 64},
+	{ID: 379, Name: "brewing_stand", MaxStackSize: 64},
+	{ID: 380, Name: "cauldron", MaxStackSize: 64},
+	{ID: 381, Name: "ender_eye", MaxStackSize: 64},
+	{ID: 382, Name: "speckled_melon", MaxStackSize: 64},
+	{ID: 383, Name: "spawn_egg", MaxStackSize: 64}, // damage is the MobType
+	{ID: 384, Name: "experience_bottle", MaxStackSize: 64},
+	{ID: 385, Name: "fire_charge", MaxStackSize: 64},
+	{ID: 386, Name: "writable_book", MaxStackSize: 1},
+	{ID: 387, Name: "written_book", MaxStackSize: 1},
+	{ID: 388, Name: "emerald", MaxStackSize: 64},
+	{ID: 389, Name: "item_frame", MaxStackSize: 64},
+	{ID: 390, Name: "flower_pot", MaxStackSize: 64},
+	{ID: 391, Name: "carrot", MaxStackSize: 64, Food: &Food{Hunger: 4, SaturationModifier: 0.6}},
+	{ID: 392, Name: "potato", MaxStackSize: 64, Food: &Food{Hunger: 1, SaturationModifier: 0.3}},
+	{ID: 393, Name: "baked_potato", MaxStackSize: 64, Food: &Food{Hunger: 6, SaturationModifier: 0.6}},
+	{ID: 394, Name: "poisonous_potato", MaxStackSize: 64, Food: &Food{Hunger: 2, SaturationModifier: 0.3, IsHarmful: true}},
+	{ID: 395, Name: "map", MaxStackSize: 64},
+	{ID: 396, Name: "golden_carrot", MaxStackSize: 64, Food: &Food{Hunger: 6, SaturationModifier: 1.2}},
+	{ID: 397, Name: "skull", Variants: []Variant{{0, "skeleton"}, {1, "wither"}, {2, "zombie"}, {3, "player"}, {4, "creeper"}}, MaxStackSize: 64},
+	{ID: 398, Name: "carrot_on_a_stick", MaxStackSize: 1, Durability: 25},
+	{ID: 399, Name: "nether_star", MaxStackSize: 64},
+	{ID: 400, Name: "pumpkin_pie", MaxStackSize: 64, Food: &Food{Hunger: 8, SaturationModifier: 0.3}},
+	{ID: 401, Name: "fireworks", MaxStackSize: 64},
+	{ID: 402, Name: "firework_charge", MaxStackSize: 64},
+	{ID: 403, Name: "enchanted_book", MaxStackSize: 1},
+	{ID: 404, Name: "comparator", MaxStackSize: 64},
+	{ID: 405, Name: "netherbrick", MaxStackSize: 64},
+	{ID: 406, Name: "quartz", MaxStackSize: 64},
+	{ID: 407, Name: "tnt_minecart", MaxStackSize: 1},
+	{ID: 408, Name: "hopper_minecart", MaxStackSize: 1},
+	{ID: 417, Name: "iron_horse_armor", MaxStackSize: 1},
+	{ID: 418, Name: "golden_horse_armor", MaxStackSize: 1},
+	{ID: 419, Name: "diamond_horse_armor", MaxStackSize: 1},
+	{ID: 420, Name: "lead", MaxStackSize: 64},
+	{ID: 421, Name: "name_tag", MaxStackSize: 64},
+	{ID: 2256, Name: "record_13", MaxStackSize: 1},
+	{ID: 2257, Name: "record_cat", MaxStackSize: 1},
+	{ID: 2258, Name: "record_blocks", MaxStackSize: 1},
+	{ID: 2259, Name: "record_chirp", MaxStackSize: 1},
+	{ID: 2260, Name: "record_far", MaxStackSize: 1},
+	{ID: 2261, Name: "record_mall", MaxStackSize: 1},
+	{ID: 2262, Name: "record_mellohi", MaxStackSize: 1},
+	{ID: 2263, Name: "record_stal", MaxStackSize: 1},
+	{ID: 2264, Name: "record_strad", MaxStackSize: 1},
+	{ID: 2265, Name: "record_ward", MaxStackSize: 1},
+	{ID: 2266, Name: "record_11", MaxStackSize: 1},
+	{ID: 2267, Name: "record_wait", MaxStackSize: 1},
+}
@@ -0,0 +1,228 @@
+// Describes the blocks and items of protocol version 74 (Minecraft 1.6.2).
+package registry
+
+// The hardness of blocks that can't be broken in survival mode.
+const Unbreakable float32 = -1
+
+// The kind of tool an item is, or the kind of tool that breaks a block
+// fastest.
+type ToolKind int
+
+const (
+	NoTool ToolKind = iota
+	Pickaxe
+	Shovel
+	Axe
+	Sword
+	Hoe
+	Shears
+)
+
+func (k ToolKind) String() string {
+	switch k {
+	case Pickaxe:
+		return "pickaxe"
+	case Shovel:
+		return "shovel"
+	case Axe:
+		return "axe"
+	case Sword:
+		return "sword"
+	case Hoe:
+		return "hoe"
+	case Shears:
+		return "shears"
+	}
+	return "none"
+}
+
+// What a tool is made of.
+type ToolMaterial int
+
+const (
+	NoMaterial ToolMaterial = iota
+	Wood
+	Stone
+	Iron
+	Diamond
+	Gold
+)
+
+// Returns the hardest blocks the material can harvest. Blocks that
+// require a material with a higher level drop nothing.
+func (m ToolMaterial) HarvestLevel() int {
+	switch m {
+	case Stone:
+		return 1
+	case Iron:
+		return 2
+	case Diamond:
+		return 3
+	}
+	return 0
+}
+
+// Returns how many times faster than a hand the material breaks the
+// blocks its tool is made for.
+func (m ToolMaterial) Speed() float32 {
+	switch m {
+	case Wood:
+		return 2
+	case Stone:
+		return 4
+	case Iron:
+		return 6
+	case Diamond:
+		return 8
+	case Gold:
+		return 12
+	}
+	return 1
+}
+
+// A named metadata value of a block or damage value of an item.
+type Variant struct {
+	Metadata int16
+	Name     string
+}
+
+type Block struct {
+	ID          int16
+	Name        string
+	Variants    []Variant
+	Solid       bool    // entities collide with it
+	Transparent bool    // light passes through it
+	Hardness    float32 // or Unbreakable
+	Light       byte    // light level it emits
+	Tool        ToolKind
+	// The minimum material of Tool needed for the block to drop
+	// anything. NoMaterial blocks can be harvested by hand.
+	Harvest ToolMaterial
+}
+
+// Returns the name of the block's variant with the given metadata, or
+// the block's name if it doesn't have one.
+func (b *Block) VariantName(metadata int16) string {
+	return variantName(b.Name, b.Variants, metadata)
+}
+
+// Returns true if breaking the block with the item drops anything. Use
+// nil for an empty hand.
+func (b *Block) CanHarvestWith(item *Item) bool {
+	if b.Harvest == NoMaterial {
+		return true
+	}
+	return item != nil && item.Tool == b.Tool &&
+		item.Material.HarvestLevel() >= b.Harvest.HarvestLevel()
+}
+
+// Returns true if the block can be broken in survival mode.
+func (b *Block) IsBreakable() bool {
+	return b.Hardness != Unbreakable
+}
+
+type Item struct {
+	ID           int16
+	Name         string
+	Variants     []Variant
+	MaxStackSize int8
+	Durability   int16 // uses before it breaks, or 0 for items that don't wear
+	Tool         ToolKind
+	Material     ToolMaterial
+	Block        *Block // the block it places, if it is a block
+	Food         *Food  // what eating it does, if it can be eaten
+}
+
+// What eating an item does.
+type Food struct {
+	Hunger             int8    // the food points it restores
+	SaturationModifier float32 // restores Hunger*SaturationModifier*2 saturation
+	IsHarmful          bool    // may poison the player or make it hungry
+}
+
+// Returns the saturation eating the food restores.
+func (f *Food) Saturation() float32 {
+	return float32(f.Hunger) * f.SaturationModifier * 2
+}
+
+// Returns the name of the item's variant with the given damage value,
+// or the item's name if it doesn't have one.
+func (i *Item) VariantName(damage int16) string {
+	return variantName(i.Name, i.Variants, damage)
+}
+
+func (i *Item) IsStackable() bool {
+	return i.MaxStackSize > 1
+}
+
+func (i *Item) IsTool() bool {
+	return i.Tool != NoTool
+}
+
+func variantName(name string, variants []Variant, metadata int16) string {
+	for _, v := range variants {
+		if v.Metadata == metadata {
+			return v.Name
+		}
+	}
+	return name
+}
+
+//////////////////////////////////////////////////////////
+
+var (
+	blocksByID   = make(map[int16]*Block)
+	blocksByName = make(map[string]*Block)
+	itemsByID    = make(map[int16]*Item)
+	itemsByName  = make(map[string]*Item)
+)
+
+func init() {
+	for i := range blocks {
+		b := &blocks[i]
+		blocksByID[b.ID] = b
+		blocksByName[b.Name] = b
+		// every block can be held, even if only in creative mode
+		addItem(&Item{
+			ID:           b.ID,
+			Name:         b.Name,
+			Variants:     b.Variants,
+			MaxStackSize: 64,
+			Block:        b,
+		})
+	}
+	for i := range items {
+		addItem(&items[i])
+	}
+}
+
+func addItem(i *Item) {
+	itemsByID[i.ID] = i
+	itemsByName[i.Name] = i
+}
+
+// Returns the block with the given id, or false if there isn't one.
+func BlockByID(id int16) (*Block, bool) {
+	b, ok := blocksByID[id]
+	return b, ok
+}
+
+// Returns the block with the given name, or false if there isn't one.
+func BlockByName(name string) (*Block, bool) {
+	b, ok := blocksByName[name]
+	return b, ok
+}
+
+// Returns the item with the given id, or false if there isn't one.
+// Blocks are also items.
+func ItemByID(id int16) (*Item, bool) {
+	i, ok := itemsByID[id]
+	return i, ok
+}
+
+// Returns the item with the given name, or false if there isn't one.
+// Blocks are also items.
+func ItemByName(name string) (*Item, bool) {
+	i, ok := itemsByName[name]
+	return i, ok
+}
@@ -0,0 +1,103 @@
+package registry
+
+import (
+	. "github.com/jeffh/goexpect"
+	"testing"
+)
+
+func TestBlocksCanBeFoundByIDAndName(t *testing.T) {
+	it := NewIt(t)
+	stone, ok := BlockByID(1)
+	it.Expects(ok, ToBeTrue)
+	it.Expects(stone.Name, ToEqual, "stone")
+	it.Expects(stone.Solid, ToBeTrue)
+
+	b, ok := BlockByName("stone")
+	it.Expects(ok, ToBeTrue)
+	it.Expects(b, ToBe, stone)
+
+	_, ok = BlockByID(160)
+	it.Expects(ok, Not(ToBeTrue))
+	_, ok = BlockByName("granite")
+	it.Expects(ok, Not(ToBeTrue))
+}
+
+func TestBlocksAreItems(t *testing.T) {
+	it := NewIt(t)
+	dirt, _ := BlockByName("dirt")
+	item, ok := ItemByID(3)
+	it.Expects(ok, ToBeTrue)
+	it.Expects(item.Block, ToBe, dirt)
+	it.Expects(item.MaxStackSize, ToEqual, int8(64))
+
+	// the held item takes the name when a block and item share one
+	bed, _ := ItemByName("bed")
+	it.Expects(bed.ID, ToEqual, int16(355))
+	it.Expects(bed.Block, ToBeNil)
+}
+
+func TestItemsCanBeFoundByIDAndName(t *testing.T) {
+	it := NewIt(t)
+	pickaxe, ok := ItemByName("diamond_pickaxe")
+	it.Expects(ok, ToBeTrue)
+	it.Expects(pickaxe.ID, ToEqual, int16(278))
+	it.Expects(pickaxe.IsTool(), ToBeTrue)
+	it.Expects(pickaxe.IsStackable(), Not(ToBeTrue))
+	it.Expects(pickaxe.Durability, ToEqual, int16(DiamondDurability))
+
+	record, ok := ItemByID(2267)
+	it.Expects(ok, ToBeTrue)
+	it.Expects(record.Name, ToEqual, "record_wait")
+
+	pearl, _ := ItemByName("ender_pearl")
+	it.Expects(pearl.MaxStackSize, ToEqual, int8(16))
+}
+
+func TestSomeItemsAreFood(t *testing.T) {
+	it := NewIt(t)
+	steak, _ := ItemByName("cooked_beef")
+	it.Expects(steak.Food, ToEqual, &Food{Hunger: 8, SaturationModifier: 0.8})
+	it.Expects(steak.Food.Saturation(), ToEqual, float32(12.8))
+	flesh, _ := ItemByName("rotten_flesh")
+	it.Expects(flesh.Food.IsHarmful, ToBeTrue)
+	pickaxe, _ := ItemByName("diamond_pickaxe")
+	it.Expects(pickaxe.Food, ToBeNil)
+}
+
+func TestVariantsAreNamedByMetadata(t *testing.T) {
+	it := NewIt(t)
+	wool, _ := BlockByName("wool")
+	it.Expects(wool.VariantName(14), ToEqual, "red")
+	it.Expects(wool.VariantName(16), ToEqual, "wool")
+
+	dye, _ := ItemByName("dye")
+	it.Expects(dye.VariantName(15), ToEqual, "bone_meal")
+}
+
+func TestBlocksRequireToolsToHarvest(t *testing.T) {
+	it := NewIt(t)
+	obsidian, _ := BlockByName("obsidian")
+	dirt, _ := BlockByName("dirt")
+	iron, _ := ItemByName("iron_pickaxe")
+	diamond, _ := ItemByName("diamond_pickaxe")
+	gold, _ := ItemByName("golden_pickaxe")
+	shovel, _ := ItemByName("diamond_shovel")
+
+	it.Expects(obsidian.CanHarvestWith(diamond), ToBeTrue)
+	it.Expects(obsidian.CanHarvestWith(iron), Not(ToBeTrue))
+	it.Expects(obsidian.CanHarvestWith(gold), Not(ToBeTrue))
+	it.Expects(obsidian.CanHarvestWith(shovel), Not(ToBeTrue))
+	it.Expects(obsidian.CanHarvestWith(nil), Not(ToBeTrue))
+	it.Expects(dirt.CanHarvestWith(nil), ToBeTrue)
+
+	bedrock, _ := BlockByName("bedrock")
+	it.Expects(bedrock.IsBreakable(), Not(ToBeTrue))
+	it.Expects(Gold.Speed(), ToEqual, float32(12))
+}
+
+func TestRegistryNamesAndIDsAreUnique(t *testing.T) {
+	it := NewIt(t)
+	it.Expects(blocksByID, ToBeLengthOf, len(blocks))
+	it.Expects(blocksByName, ToBeLengthOf, len(blocks))
+	it.Expects(itemsByID, ToBeLengthOf, len(blocks)+len(items))
+}
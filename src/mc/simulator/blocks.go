@@ -0,0 +1,87 @@
+package simulator
+
+import (
+	"mc/protocol"
+	"smpm"
+)
+
+// Changes the block at the given coordinates. Returns the block that
+// was replaced, or false if the block's column isn't loaded.
+func (w *World) SetBlock(x, y, z int32, blockType int16, metadata byte) (Block, bool) {
+	old, ok := w.BlockAt(x, y, z)
+	if !ok {
+		return old, false
+	}
+	column := w.ColumnAt(x, z)
+	_, bx := splitBlockCoordinate(x)
+	cy, by := splitBlockCoordinate(y)
+	_, bz := splitBlockCoordinate(z)
+
+	if !column.HasChunk(int(cy)) {
+		// missing chunks are air lit by the sky
+		chunk := smpm.NewChunk()
+		for i := range chunk.Skylight {
+			chunk.Skylight[i] = MaxLightLevel<<4 | MaxLightLevel
+		}
+		column.Chunks[cy] = chunk
+		column.Metadata.PrimaryBitmap |= 1 << uint(cy)
+	}
+	chunk := column.Chunks[cy]
+	i := smpm.BlockIndex(bx, by, bz)
+	chunk.Types[i] = byte(blockType)
+	smpm.SetNibble(chunk.Add, i, byte(blockType>>8))
+	smpm.SetNibble(chunk.Metadata, i, metadata)
+	if blockType>>8 != 0 {
+		column.Metadata.AddBitmap |= 1 << uint(cy)
+	}
+	return old, true
+}
+
+//////////////////////////////////////////////////////////
+
+// Emitted when a block in a loaded column changes.
+type BlockChangeEvent struct {
+	Position Vector3Int
+	Old, New Block
+}
+
+func (s *Simulator) changeBlock(x, y, z int32, blockType int16, metadata byte) {
+	old, ok := s.World.SetBlock(x, y, z, blockType, metadata)
+	if !ok {
+		s.Logger.Printf("Ignoring block change in unloaded column: (%d, %d, %d)", x, y, z)
+		return
+	}
+	updated, _ := s.World.BlockAt(x, y, z)
+	position := Vector3Int{x, y, z}
+	s.emit(&BlockChangeEvent{
+		Position: position,
+		Old:      old,
+		New:      updated,
+	})
+	s.navigationBlockChanged(position)
+	s.diggingBlockChanged(position, updated)
+	s.placingBlockChanged(position, updated)
+}
+
+func (s *Simulator) handleBlockChange(t *protocol.BlockChange) {
+	s.changeBlock(t.X, int32(uint8(t.Y)), t.Z, t.Type, byte(t.Metadata))
+}
+
+func (s *Simulator) handleMultiBlockChange(t *protocol.MultiBlockChange) {
+	for _, r := range t.Records() {
+		x := t.ChunkX<<4 + int32(r.X)
+		z := t.ChunkY<<4 + int32(r.Z)
+		s.changeBlock(x, int32(r.Y), z, r.Type, byte(r.Metadata))
+	}
+}
+
+func (s *Simulator) handleExplosion(t *protocol.Explosion) {
+	for _, b := range t.AffectedBlocks {
+		s.changeBlock(b.X, b.Y, b.Z, 0, 0)
+	}
+	if e := s.World.CurrentPlayer.Entity; e != nil {
+		e.Velocity.X += float64(t.PlayerXVelocity)
+		e.Velocity.Y += float64(t.PlayerYVelocity)
+		e.Velocity.Z += float64(t.PlayerZVelocity)
+	}
+}
@@ -0,0 +1,78 @@
+package simulator
+
+import (
+	. "github.com/jeffh/goexpect"
+	"mc/protocol"
+	"testing"
+)
+
+func TestSimulatorAppliesBlockChanges(t *testing.T) {
+	s, _, events := createSimulator()
+	s.ProcessMessage(chunkData(0, 0, 1))
+	*events = nil
+
+	s.ProcessMessage(&protocol.BlockChange{X: 1, Y: 2, Z: 3, Type: 4, Metadata: 5})
+	block, _ := s.World.BlockAt(1, 2, 3)
+	Expect(t, block, ToEqual, Block{Type: 4, Metadata: 5, Light: 7, Skylight: 15, Biome: 4})
+	Expect(t, (*events)[0], ToEqual, &BlockChangeEvent{
+		Position: Vector3Int{1, 2, 3},
+		Old:      Block{Type: 1, Metadata: 2, Light: 7, Skylight: 15, Biome: 4},
+		New:      block,
+	})
+
+	// blocks above the sent chunks create a new chunk
+	s.ProcessMessage(&protocol.BlockChange{X: 1, Y: -56, Z: 3, Type: 0x1a3})
+	block, _ = s.World.BlockAt(1, 200, 3)
+	Expect(t, block, ToEqual, Block{Type: 0x1a3, Skylight: 15, Biome: 4})
+	block, _ = s.World.BlockAt(1, 201, 3)
+	Expect(t, block, ToEqual, Block{Skylight: 15, Biome: 4})
+
+	// changes in unloaded columns are ignored
+	s.ProcessMessage(&protocol.BlockChange{X: 100, Y: 2, Z: 3, Type: 4})
+	Expect(t, *events, ToBeLengthOf, 2)
+}
+
+func TestSimulatorAppliesMultiBlockChanges(t *testing.T) {
+	s, _, events := createSimulator()
+	s.ProcessMessage(chunkData(1, 2, 1))
+	*events = nil
+
+	s.ProcessMessage(&protocol.MultiBlockChange{
+		ChunkX:      1,
+		ChunkY:      2,
+		RecordCount: 2,
+		Data:        protocol.Int32PrefixedBytes{0x3a, 0x05, 0x01, 0x12, 0xf0, 0x0f, 0x1a, 0x3f},
+	})
+	block, _ := s.World.BlockAt(19, 5, 42)
+	Expect(t, block.Type, ToEqual, int16(0x11))
+	Expect(t, block.Metadata, ToEqual, byte(2))
+	block, _ = s.World.BlockAt(31, 15, 32)
+	Expect(t, block.Type, ToEqual, int16(0x1a3))
+	Expect(t, block.Metadata, ToEqual, byte(15))
+	Expect(t, *events, ToBeLengthOf, 2)
+}
+
+func TestSimulatorAppliesExplosions(t *testing.T) {
+	s, _, events := createSimulator()
+	s.ProcessMessage(chunkData(0, 0, 1))
+	*events = nil
+
+	s.ProcessMessage(&protocol.Explosion{
+		X: 2, Y: 2, Z: 2,
+		Radius: 3,
+		AffectedBlocks: []protocol.BlockPosition{
+			{X: 2, Y: 2, Z: 2},
+			{X: 3, Y: 2, Z: 2},
+		},
+		PlayerXVelocity: 0.5,
+		PlayerYVelocity: 0.25,
+	})
+	block, _ := s.World.BlockAt(2, 2, 2)
+	Expect(t, block.Type, ToEqual, int16(0))
+	block, _ = s.World.BlockAt(3, 2, 2)
+	Expect(t, block.Type, ToEqual, int16(0))
+	block, _ = s.World.BlockAt(4, 2, 2)
+	Expect(t, block.Type, ToEqual, int16(1))
+	Expect(t, *events, ToBeLengthOf, 2)
+	Expect(t, s.World.CurrentPlayer.Entity.Velocity, ToEqual, Vector3Float{0.5, 0.25, 0})
+}
@@ -0,0 +1,146 @@
+package simulator
+
+import (
+	"bytes"
+	"compress/zlib"
+	"mc/protocol"
+	"smpm"
+)
+
+const (
+	ChunkColumnHeight = smpm.ChunksPerColumn * 16 // in blocks
+	MaxLightLevel     = 15
+)
+
+type ChunkDataWrapper struct {
+	ChunkData   *protocol.ChunkData
+	HasSkylight bool
+}
+
+func (w *ChunkDataWrapper) HasSkylightData() bool {
+	return w.HasSkylight
+}
+
+func (w *ChunkDataWrapper) ChunkColumnCount() int16 {
+	return 1
+}
+
+func (w *ChunkDataWrapper) NextMetadata() smpm.ChunkColumnMetadata {
+	return smpm.ChunkColumnMetadata{
+		X:             w.ChunkData.X,
+		Z:             w.ChunkData.Y,
+		PrimaryBitmap: uint16(w.ChunkData.PrimaryBitMap),
+		AddBitmap:     uint16(w.ChunkData.AddBitMap),
+	}
+}
+
+func (w *ChunkDataWrapper) IsGroundUpContinuous() bool {
+	return w.ChunkData.IsGroundUpContinuous
+}
+
+//////////////////////////////////////////////////////////
+
+// Splits a block coordinate into its chunk coordinate and its offset
+// in that chunk.
+func splitBlockCoordinate(v int32) (chunk int32, offset int) {
+	return v >> 4, int(v & 0xf)
+}
+
+// Returns the column containing the given block coordinates, or nil if
+// it isn't loaded.
+func (w *World) ColumnAt(x, z int32) *smpm.ChunkColumn {
+	cx, _ := splitBlockCoordinate(x)
+	cz, _ := splitBlockCoordinate(z)
+	return w.Columns[smpm.ColumnPoint{X: cx, Z: cz}]
+}
+
+// Returns the block at the given coordinates. Returns false if the
+// block's column hasn't been loaded or y is outside of the world.
+//
+// Blocks in chunks the server didn't send are air, lit by the sky.
+func (w *World) BlockAt(x, y, z int32) (Block, bool) {
+	column := w.ColumnAt(x, z)
+	if column == nil || y < 0 || y >= ChunkColumnHeight {
+		return Block{}, false
+	}
+	_, bx := splitBlockCoordinate(x)
+	cy, by := splitBlockCoordinate(y)
+	_, bz := splitBlockCoordinate(z)
+
+	block := Block{Biome: column.BiomeAt(bx, bz)}
+	if !column.HasChunk(int(cy)) {
+		block.Skylight = MaxLightLevel
+		return block, true
+	}
+	chunk := column.Chunks[cy]
+	i := smpm.BlockIndex(bx, by, bz)
+	block.Type = int16(chunk.Types[i]) | int16(smpm.Nibble(chunk.Add, i))<<8
+	block.Metadata = smpm.Nibble(chunk.Metadata, i)
+	block.Light = smpm.Nibble(chunk.Light, i)
+	block.Skylight = smpm.Nibble(chunk.Skylight, i)
+	return block, true
+}
+
+// Stores the parsed column. Columns that aren't ground-up continuous
+// only replace the chunks they contain.
+func (w *World) loadColumn(column smpm.ChunkColumn, groundUp bool) {
+	point := smpm.ColumnPoint{X: column.Metadata.X, Z: column.Metadata.Z}
+	existing, ok := w.Columns[point]
+	if groundUp || !ok {
+		w.Columns[point] = &column
+		return
+	}
+	for i, chunk := range column.Chunks {
+		if column.HasChunk(i) {
+			existing.Chunks[i] = chunk
+		}
+	}
+	existing.Metadata.PrimaryBitmap |= column.Metadata.PrimaryBitmap
+	existing.Metadata.AddBitmap |= column.Metadata.AddBitmap
+}
+
+func (w *World) unloadColumn(x, z int32) {
+	delete(w.Columns, smpm.ColumnPoint{X: x, Z: z})
+}
+
+//////////////////////////////////////////////////////////
+
+func (s *Simulator) parseColumns(data []byte, metadata smpm.Metadata) ([]smpm.ChunkColumn, error) {
+	reader, err := zlib.NewReader(bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return smpm.NewFile(reader, metadata, s.Logger.WrappedLogger()).Parse()
+}
+
+func (s *Simulator) handleMapChunkBulk(t *protocol.MapChunkBulk) {
+	columns, err := s.parseColumns(t.CompressedData, &MapChunkBulkWrapper{MapChunkBulk: t})
+	if err != nil {
+		s.Logger.Printf("Failed to parse chunks: %s", err)
+		return
+	}
+	for _, column := range columns {
+		s.World.loadColumn(column, true)
+	}
+}
+
+func (s *Simulator) handleChunkData(t *protocol.ChunkData) {
+	// an empty, ground-up continuous column tells the client to unload it
+	if t.IsGroundUpContinuous && t.PrimaryBitMap == 0 {
+		s.World.unloadColumn(t.X, t.Y)
+		return
+	}
+	metadata := &ChunkDataWrapper{
+		ChunkData:   t,
+		HasSkylight: s.World.GameDimension.IsOverworld(),
+	}
+	columns, err := s.parseColumns(t.ZlibData, metadata)
+	if err != nil {
+		s.Logger.Printf("Failed to parse chunk: %s", err)
+		return
+	}
+	for _, column := range columns {
+		s.World.loadColumn(column, t.IsGroundUpContinuous)
+	}
+}
@@ -0,0 +1,108 @@
+package simulator
+
+import (
+	"bytes"
+	"compress/zlib"
+	. "github.com/jeffh/goexpect"
+	"mc/protocol"
+	"smpm"
+	"testing"
+)
+
+// Builds the data of a column whose first chunk is filled with the
+// given block. The block at (1, 2, 3) has a light of 7.
+func rawColumn(blockType int16, metadata byte, biome byte) []byte {
+	chunk := smpm.NewChunk()
+	for i := range chunk.Types {
+		chunk.Types[i] = byte(blockType)
+		smpm.SetNibble(chunk.Add, i, byte(blockType>>8))
+		smpm.SetNibble(chunk.Metadata, i, metadata)
+		smpm.SetNibble(chunk.Skylight, i, 15)
+	}
+	smpm.SetNibble(chunk.Light, smpm.BlockIndex(1, 2, 3), 7)
+
+	raw := bytes.NewBuffer([]byte{})
+	raw.Write(chunk.Types)
+	raw.Write(chunk.Metadata)
+	raw.Write(chunk.Light)
+	raw.Write(chunk.Skylight)
+	raw.Write(chunk.Add)
+	raw.Write(bytes.Repeat([]byte{biome}, smpm.ChunkBiomeSize))
+	return raw.Bytes()
+}
+
+func compress(data ...[]byte) []byte {
+	compressed := bytes.NewBuffer([]byte{})
+	w := zlib.NewWriter(compressed)
+	for _, d := range data {
+		w.Write(d)
+	}
+	w.Close()
+	return compressed.Bytes()
+}
+
+func chunkData(x, z int32, blockType int16) *protocol.ChunkData {
+	return &protocol.ChunkData{
+		X:                    x,
+		Y:                    z,
+		IsGroundUpContinuous: true,
+		PrimaryBitMap:        1,
+		AddBitMap:            1,
+		ZlibData:             compress(rawColumn(blockType, 2, 4)),
+	}
+}
+
+func TestSimulatorStoresChunkData(t *testing.T) {
+	s, _, _ := createSimulator()
+	s.ProcessMessage(chunkData(-1, 2, 1))
+
+	block, ok := s.World.BlockAt(-15, 2, 35)
+	Expect(t, ok, ToBeTrue)
+	Expect(t, block, ToEqual, Block{Type: 1, Metadata: 2, Light: 7, Skylight: 15, Biome: 4})
+
+	block, ok = s.World.BlockAt(-16, 3, 32)
+	Expect(t, ok, ToBeTrue)
+	Expect(t, block.Light, ToEqual, byte(0))
+
+	// above the sent chunk is air
+	block, ok = s.World.BlockAt(-16, 100, 32)
+	Expect(t, ok, ToBeTrue)
+	Expect(t, block, ToEqual, Block{Skylight: 15, Biome: 4})
+
+	_, ok = s.World.BlockAt(0, 2, 35)
+	Expect(t, ok, Not(ToBeTrue))
+	_, ok = s.World.BlockAt(-15, 256, 35)
+	Expect(t, ok, Not(ToBeTrue))
+}
+
+func TestSimulatorReadsAddBits(t *testing.T) {
+	s, _, _ := createSimulator()
+	s.ProcessMessage(chunkData(0, 0, 0x1a3))
+	block, _ := s.World.BlockAt(0, 0, 0)
+	Expect(t, block.Type, ToEqual, int16(0x1a3))
+}
+
+func TestSimulatorUnloadsEmptyColumns(t *testing.T) {
+	s, _, _ := createSimulator()
+	s.ProcessMessage(chunkData(0, 0, 1))
+	s.ProcessMessage(&protocol.ChunkData{X: 0, Y: 0, IsGroundUpContinuous: true})
+	Expect(t, s.World.Columns, ToBeEmpty)
+}
+
+func TestSimulatorStoresMapChunkBulks(t *testing.T) {
+	s, _, _ := createSimulator()
+	s.ProcessMessage(&protocol.MapChunkBulk{
+		SkylightSent:   true,
+		CompressedData: compress(rawColumn(1, 0, 1), rawColumn(3, 0, 2)),
+		Metadatas: []protocol.ChunkBulkMetadata{
+			{ChunkX: 0, ChunkY: 0, PrimaryBitmap: 1, AddBitmap: 1},
+			{ChunkX: 1, ChunkY: 0, PrimaryBitmap: 1, AddBitmap: 1},
+		},
+	})
+
+	block, _ := s.World.BlockAt(5, 5, 5)
+	Expect(t, block.Type, ToEqual, int16(1))
+	block, _ = s.World.BlockAt(21, 5, 5)
+	Expect(t, block.Type, ToEqual, int16(3))
+	Expect(t, block.Biome, ToEqual, byte(2))
+}
@@ -0,0 +1,524 @@
+package simulator
+
+import (
+	"bytes"
+	"fmt"
+	"mc/protocol"
+	"mc/registry"
+	"strings"
+)
+
+// Emitted when the server accepts or rejects a click. Rejected clicks
+// are followed by the window's items, which undo the prediction.
+type ClickConfirmedEvent struct {
+	Window       *Window // or nil if it was closed
+	ActionNumber int16
+	Accepted     bool
+}
+
+// Windows that shift clicks from the player's inventory move items into.
+var storageWindowTypes = map[protocol.WindowType]bool{
+	protocol.WindowTypeChest:     true,
+	protocol.WindowTypeDispenser: true,
+	protocol.WindowTypeHopper:    true,
+	protocol.WindowTypeDropper:   true,
+}
+
+// Windows whose input slots shift click into the player's inventory in
+// order, instead of filling the hotbar from its right end first.
+var inputWindowTypes = map[protocol.WindowType]bool{
+	protocol.WindowTypeFurnance: true,
+	protocol.WindowTypeTrade:    true,
+	protocol.WindowTypeAnvil:    true,
+}
+
+// The pieces of armor, in the order of the inventory's armor slots.
+var armorPieces = []string{"helmet", "chestplate", "leggings", "boots"}
+
+// Returns the slot of the inventory window where the item is worn, if
+// it's armor.
+func armorSlot(item protocol.Slot) (int16, bool) {
+	info, ok := registry.ItemByID(item.ID)
+	if !ok || item.IsEmpty() {
+		return 0, false
+	}
+	for i, piece := range armorPieces {
+		if strings.HasSuffix(info.Name, "_"+piece) {
+			return 5 + int16(i), true
+		}
+	}
+	return 0, false
+}
+
+// Returns the number of items that fit in one slot.
+func maxStackSize(item protocol.Slot) int {
+	if i, ok := registry.ItemByID(item.ID); ok {
+		return int(i.MaxStackSize)
+	}
+	return 64
+}
+
+// Returns true if the stacks hold the same kind of item, so they can be
+// merged.
+func isSameItem(a, b protocol.Slot) bool {
+	return a.ID != protocol.EmptySlot.ID && a.ID == b.ID && a.Damage == b.Damage &&
+		bytes.Equal(a.GzippedNBT, b.GzippedNBT)
+}
+
+// Returns the stack with a different count, or an empty slot if there
+// are no items left.
+func withCount(item protocol.Slot, count int) protocol.Slot {
+	if count <= 0 {
+		return protocol.EmptySlot
+	}
+	item.Count = int8(count)
+	return item
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Returns true if items can only be taken out of the slot, like the
+// results of crafting and smelting.
+func (w *Window) isOutput(i int16) bool {
+	switch w.Region(i) {
+	case CraftingResultSlots:
+		return true
+	case ContainerSlots:
+		switch w.Type {
+		case protocol.WindowTypeFurnance, protocol.WindowTypeTrade, protocol.WindowTypeAnvil:
+			return i == 2
+		}
+	}
+	return false
+}
+
+// Takes one item from each slot of the crafting grid, as taking the
+// result does.
+func (w *Window) consumeIngredients() {
+	for _, i := range w.SlotsIn(CraftingSlots) {
+		w.Slots[i] = withCount(w.Slots[i], int(w.Slots[i].Count)-1)
+	}
+}
+
+// Moves the items into the target slots, first onto stacks of the same
+// item, then into the first empty slot. Returns how many didn't fit.
+func (w *Window) merge(item protocol.Slot, targets []int16) int {
+	count, max := int(item.Count), maxStackSize(item)
+	for _, i := range targets {
+		t := w.Slots[i]
+		if count == 0 || max == 1 {
+			break
+		}
+		if isSameItem(t, item) && int(t.Count) < max {
+			n := min(count, max-int(t.Count))
+			w.Slots[i] = withCount(t, int(t.Count)+n)
+			count -= n
+		}
+	}
+	for _, i := range targets {
+		if count == 0 {
+			break
+		}
+		if w.Slots[i].IsEmpty() {
+			w.Slots[i] = withCount(item, count)
+			count = 0
+		}
+	}
+	return count
+}
+
+// Returns the slots a shift click on the slot moves its stack to, in
+// the order they're filled.
+func (w *Window) quickMoveTargets(i int16) []int16 {
+	region := w.Region(i)
+	player := append(w.SlotsIn(MainInventorySlots), w.SlotsIn(HotbarSlots)...)
+	if region == MainInventorySlots || region == HotbarSlots {
+		if armor, ok := armorSlot(w.Slots[i]); ok && w.IsInventory() && w.Slots[armor].IsEmpty() {
+			// putting the armor on
+			return []int16{armor}
+		}
+		if storageWindowTypes[w.Type] && !w.IsInventory() {
+			return w.SlotsIn(ContainerSlots)
+		}
+	}
+	switch {
+	case region == MainInventorySlots:
+		return w.SlotsIn(HotbarSlots)
+	case region == HotbarSlots:
+		return w.SlotsIn(MainInventorySlots)
+	case w.isOutput(i) || region == ContainerSlots && !inputWindowTypes[w.Type]:
+		// filling the hotbar from its right end first
+		for a, b := 0, len(player)-1; a < b; a, b = a+1, b-1 {
+			player[a], player[b] = player[b], player[a]
+		}
+	}
+	return player
+}
+
+//////////////////////////////////////////////////////////
+
+// Clicks a slot of the window, predicting how its slots and the cursor
+// change without waiting for the server. The server rejects clicks it
+// disagrees with and sends the window's items again.
+//
+// Prefer MoveItems, QuickMove and the other operations that send the
+// right clicks for common tasks.
+func (s *Simulator) Click(w *Window, slot int16, button protocol.MouseButton, mode protocol.ClickMode) error {
+	if s.Window(w.ID) != w {
+		return fmt.Errorf("Window %d isn't open", w.ID)
+	}
+	w.actionNumber++
+	s.send(&protocol.ClickWindow{
+		WindowID:     w.ID,
+		Slot:         slot,
+		MouseButton:  button,
+		ActionNumber: w.actionNumber,
+		Mode:         mode,
+		ClickedItem:  w.Slot(slot),
+	})
+
+	switch mode {
+	case protocol.ClickModeNormal:
+		s.predictNormalClick(w, slot, button == protocol.ButtonRightMouse)
+	case protocol.ClickModeShift:
+		s.predictQuickMove(w, slot)
+	case protocol.ClickModeNumberKey:
+		s.predictHotbarSwap(w, slot, int(button))
+	case protocol.ClickModeMiddle:
+		s.predictClone(w, slot)
+	case protocol.ClickModeDrop:
+		s.predictDrop(w, slot, button == protocol.ButtonRightMouse)
+	case protocol.ClickModeDrag:
+		s.predictDrag(w, slot, button)
+	case protocol.ClickModeDoubleClick:
+		s.predictGather(w)
+	}
+	w.updateCraftingResult()
+	s.syncInventory(w)
+	return nil
+}
+
+func (s *Simulator) predictNormalClick(w *Window, slot int16, right bool) {
+	cursor := &s.World.CurrentPlayer.Cursor
+	if slot == protocol.SlotOutsideWindow {
+		// drops the held items
+		if right {
+			*cursor = withCount(*cursor, int(cursor.Count)-1)
+		} else {
+			*cursor = protocol.EmptySlot
+		}
+		return
+	}
+	if w.Region(slot) == NoSlotRegion {
+		return
+	}
+
+	item := w.Slots[slot]
+	switch {
+	case w.isOutput(slot):
+		// results are taken whole, and only if they fit on the cursor
+		if item.IsEmpty() {
+			return
+		}
+		if cursor.IsEmpty() {
+			*cursor = item
+		} else if isSameItem(*cursor, item) && int(cursor.Count)+int(item.Count) <= maxStackSize(item) {
+			*cursor = withCount(*cursor, int(cursor.Count)+int(item.Count))
+		} else {
+			return
+		}
+		w.Slots[slot] = protocol.EmptySlot
+		if w.Region(slot) == CraftingResultSlots {
+			w.consumeIngredients()
+		}
+	case cursor.IsEmpty():
+		// right clicks take the larger half
+		take := int(item.Count)
+		if right {
+			take = (take + 1) / 2
+		}
+		*cursor = withCount(item, take)
+		w.Slots[slot] = withCount(item, int(item.Count)-take)
+	case item.IsEmpty() || isSameItem(*cursor, item):
+		// right clicks put one item
+		put := int(cursor.Count)
+		if right {
+			put = 1
+		}
+		put = min(put, maxStackSize(*cursor)-int(item.Count))
+		w.Slots[slot] = withCount(*cursor, int(item.Count)+put)
+		*cursor = withCount(*cursor, int(cursor.Count)-put)
+	default:
+		w.Slots[slot], *cursor = *cursor, item
+	}
+}
+
+func (s *Simulator) predictQuickMove(w *Window, slot int16) {
+	item := w.Slot(slot)
+	if item.IsEmpty() {
+		return
+	}
+	if w.isOutput(slot) {
+		// results are only moved if all of them fit
+		before := append([]protocol.Slot{}, w.Slots...)
+		if w.merge(item, w.quickMoveTargets(slot)) > 0 {
+			w.Slots = before
+			return
+		}
+		w.Slots[slot] = protocol.EmptySlot
+		if w.Region(slot) == CraftingResultSlots {
+			w.consumeIngredients()
+		}
+		return
+	}
+	w.Slots[slot] = withCount(item, w.merge(item, w.quickMoveTargets(slot)))
+}
+
+func (s *Simulator) predictHotbarSwap(w *Window, slot int16, hotbar int) {
+	hotbarSlots := w.SlotsIn(HotbarSlots)
+	if w.Region(slot) == NoSlotRegion || hotbar < 0 || hotbar >= len(hotbarSlots) {
+		return
+	}
+	other := hotbarSlots[hotbar]
+	item := w.Slots[slot]
+	if w.isOutput(slot) && (!w.Slots[other].IsEmpty() || item.IsEmpty()) {
+		return
+	}
+	w.Slots[slot], w.Slots[other] = w.Slots[other], item
+	if w.Region(slot) == CraftingResultSlots {
+		w.consumeIngredients()
+	}
+}
+
+func (s *Simulator) predictClone(w *Window, slot int16) {
+	player := &s.World.CurrentPlayer
+	item := w.Slot(slot)
+	if s.World.GameMode.IsCreative() && player.Cursor.IsEmpty() && !item.IsEmpty() {
+		player.Cursor = withCount(item, maxStackSize(item))
+	}
+}
+
+func (s *Simulator) predictDrop(w *Window, slot int16, all bool) {
+	item := w.Slot(slot)
+	if item.IsEmpty() || !s.World.CurrentPlayer.Cursor.IsEmpty() {
+		return
+	}
+	if all || w.isOutput(slot) {
+		w.Slots[slot] = protocol.EmptySlot
+	} else {
+		w.Slots[slot] = withCount(item, int(item.Count)-1)
+	}
+	if w.Region(slot) == CraftingResultSlots {
+		w.consumeIngredients()
+	}
+}
+
+func (s *Simulator) predictDrag(w *Window, slot int16, button protocol.MouseButton) {
+	cursor := &s.World.CurrentPlayer.Cursor
+	switch button {
+	case protocol.ButtonStartLeftDrag, protocol.ButtonStartRightDrag:
+		w.dragSlots = make([]int16, 0)
+		w.dragEvenly = button == protocol.ButtonStartLeftDrag
+	case protocol.ButtonAddLeftDrag, protocol.ButtonAddRightDrag:
+		item := w.Slot(slot)
+		if w.dragSlots == nil || w.Region(slot) == NoSlotRegion || w.isOutput(slot) ||
+			!(item.IsEmpty() || isSameItem(item, *cursor)) || int(cursor.Count) <= len(w.dragSlots) {
+			return
+		}
+		for _, i := range w.dragSlots {
+			if i == slot {
+				return
+			}
+		}
+		w.dragSlots = append(w.dragSlots, slot)
+	case protocol.ButtonEndLeftDrag, protocol.ButtonEndRightDrag:
+		slots := w.dragSlots
+		w.dragSlots = nil
+		if len(slots) == 0 || cursor.IsEmpty() {
+			return
+		}
+		each := 1
+		if w.dragEvenly {
+			each = int(cursor.Count) / len(slots)
+		}
+		for _, i := range slots {
+			item := w.Slots[i]
+			put := min(each, maxStackSize(*cursor)-int(item.Count))
+			w.Slots[i] = withCount(*cursor, int(item.Count)+put)
+			*cursor = withCount(*cursor, int(cursor.Count)-put)
+		}
+	}
+}
+
+// Fills the cursor with items like it from the window, taking from
+// partial stacks before full ones.
+func (s *Simulator) predictGather(w *Window) {
+	cursor := &s.World.CurrentPlayer.Cursor
+	if cursor.IsEmpty() {
+		return
+	}
+	max := maxStackSize(*cursor)
+	for pass := 0; pass < 2; pass++ {
+		for i := range w.Slots {
+			item := w.Slots[i]
+			if int(cursor.Count) >= max {
+				return
+			}
+			if w.isOutput(int16(i)) || !isSameItem(item, *cursor) || (pass == 0 && int(item.Count) == max) {
+				continue
+			}
+			take := min(int(item.Count), max-int(cursor.Count))
+			w.Slots[i] = withCount(item, int(item.Count)-take)
+			*cursor = withCount(*cursor, int(cursor.Count)+take)
+		}
+	}
+}
+
+// Copies the player's part of the window to the inventory, or the other
+// way around.
+func (s *Simulator) syncInventory(w *Window) {
+	for i := range w.Slots {
+		if w.IsInventory() {
+			s.copyFromInventory(int16(i))
+		} else {
+			s.copyToInventory(w, int16(i))
+		}
+	}
+}
+
+func (s *Simulator) handleConfirmTransaction(t *protocol.ConfirmTransaction) {
+	if !t.Accepted {
+		// the server ignores clicks until the client apologizes
+		s.send(&protocol.ConfirmTransaction{
+			WindowID:     t.WindowID,
+			ActionNumber: t.ActionNumber,
+			Accepted:     true,
+		})
+	}
+	s.emit(&ClickConfirmedEvent{
+		Window:       s.Window(t.WindowID),
+		ActionNumber: t.ActionNumber,
+		Accepted:     t.Accepted,
+	})
+}
+
+//////////////////////////////////////////////////////////
+
+func (s *Simulator) checkEmptyCursor() error {
+	if !s.World.CurrentPlayer.Cursor.IsEmpty() {
+		return fmt.Errorf("The cursor is holding items")
+	}
+	return nil
+}
+
+// Moves count items from one slot of the window to another, which has
+// to be empty or hold the same item. The cursor must be empty.
+func (s *Simulator) MoveItems(w *Window, from, to int16, count int) error {
+	if err := s.checkEmptyCursor(); err != nil {
+		return err
+	}
+	item, target := w.Slot(from), w.Slot(to)
+	switch {
+	case count <= 0 || int(item.Count) < count:
+		return fmt.Errorf("Slot %d doesn't have %d items", from, count)
+	case w.isOutput(from) && int(item.Count) != count:
+		return fmt.Errorf("Slot %d can only be emptied", from)
+	case w.Region(to) == NoSlotRegion || w.isOutput(to):
+		return fmt.Errorf("Items can't be put into slot %d", to)
+	case !target.IsEmpty() && !isSameItem(item, target):
+		return fmt.Errorf("Slot %d holds a different item", to)
+	case maxStackSize(item)-int(target.Count) < count:
+		return fmt.Errorf("Slot %d doesn't have room for %d items", to, count)
+	}
+
+	if err := s.Click(w, from, protocol.ButtonLeftMouse, protocol.ClickModeNormal); err != nil {
+		return err
+	}
+	if count == int(item.Count) {
+		return s.Click(w, to, protocol.ButtonLeftMouse, protocol.ClickModeNormal)
+	}
+	for i := 0; i < count; i++ {
+		if err := s.Click(w, to, protocol.ButtonRightMouse, protocol.ClickModeNormal); err != nil {
+			return err
+		}
+	}
+	// putting the rest back
+	return s.Click(w, from, protocol.ButtonLeftMouse, protocol.ClickModeNormal)
+}
+
+// Shift clicks the slot, which moves its stack to another region of the
+// window, such as from a chest to the player's inventory.
+func (s *Simulator) QuickMove(w *Window, slot int16) error {
+	if item := w.Slot(slot); item.IsEmpty() {
+		return fmt.Errorf("Slot %d is empty", slot)
+	}
+	return s.Click(w, slot, protocol.ButtonLeftMouse, protocol.ClickModeShift)
+}
+
+// Swaps the slot with a slot of the hotbar, numbered from 0 to 8.
+func (s *Simulator) SwapWithHotbar(w *Window, slot int16, hotbar int) error {
+	if hotbar < 0 || hotbar >= HotbarSize {
+		return fmt.Errorf("Hotbar slot %d doesn't exist", hotbar)
+	}
+	return s.Click(w, slot, protocol.MouseButton(hotbar), protocol.ClickModeNumberKey)
+}
+
+// Drops one item of the slot, or all of them.
+func (s *Simulator) DropItems(w *Window, slot int16, all bool) error {
+	if err := s.checkEmptyCursor(); err != nil {
+		return err
+	}
+	button := protocol.ButtonLeftMouse
+	if all {
+		button = protocol.ButtonRightMouse
+	}
+	return s.Click(w, slot, button, protocol.ClickModeDrop)
+}
+
+// Spreads the items on the cursor over the slots by dragging, either
+// evenly or one item each. Leftover items stay on the cursor.
+func (s *Simulator) Distribute(w *Window, slots []int16, evenly bool) error {
+	cursor := s.World.CurrentPlayer.Cursor
+	if cursor.IsEmpty() {
+		return fmt.Errorf("The cursor isn't holding items")
+	}
+	if len(slots) > int(cursor.Count) {
+		return fmt.Errorf("Can't spread %d items over %d slots", cursor.Count, len(slots))
+	}
+	start, add, end := protocol.ButtonStartRightDrag, protocol.ButtonAddRightDrag, protocol.ButtonEndRightDrag
+	if evenly {
+		start, add, end = protocol.ButtonStartLeftDrag, protocol.ButtonAddLeftDrag, protocol.ButtonEndLeftDrag
+	}
+	if err := s.Click(w, protocol.SlotOutsideWindow, start, protocol.ClickModeDrag); err != nil {
+		return err
+	}
+	for _, slot := range slots {
+		if err := s.Click(w, slot, add, protocol.ClickModeDrag); err != nil {
+			return err
+		}
+	}
+	return s.Click(w, protocol.SlotOutsideWindow, end, protocol.ClickModeDrag)
+}
+
+// Fills up the stack in the slot with the same items from the rest of
+// the window by double clicking it.
+func (s *Simulator) GatherStack(w *Window, slot int16) error {
+	if err := s.checkEmptyCursor(); err != nil {
+		return err
+	}
+	if item := w.Slot(slot); item.IsEmpty() || w.isOutput(slot) {
+		return fmt.Errorf("Slot %d has nothing to gather", slot)
+	}
+	if err := s.Click(w, slot, protocol.ButtonLeftMouse, protocol.ClickModeNormal); err != nil {
+		return err
+	}
+	if err := s.Click(w, slot, protocol.ButtonLeftMouse, protocol.ClickModeDoubleClick); err != nil {
+		return err
+	}
+	return s.Click(w, slot, protocol.ButtonLeftMouse, protocol.ClickModeNormal)
+}
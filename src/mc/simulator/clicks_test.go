@@ -0,0 +1,254 @@
+package simulator
+
+import (
+	. "github.com/jeffh/goexpect"
+	"mc/protocol"
+	"testing"
+)
+
+var (
+	stone = protocol.Slot{ID: 1, Count: 64}
+	dirt  = protocol.Slot{ID: 3, Count: 10}
+	sword = protocol.Slot{ID: 276, Count: 1}
+)
+
+func stack(item protocol.Slot, count int8) protocol.Slot {
+	item.Count = count
+	return item
+}
+
+func createChestSimulator(items map[int]protocol.Slot) (*Simulator, chan interface{}, *Window) {
+	s, outbox, _ := createSimulator()
+	s.ProcessMessage(&protocol.OpenWindow{WindowID: 2, InventoryType: protocol.WindowTypeChest, NumSlots: 27})
+	s.ProcessMessage(&protocol.SetWindowItems{WindowID: 2, Slots: slots(63, items)})
+	return s, outbox, s.World.CurrentPlayer.Window
+}
+
+func TestClickSendsTheClickedItem(t *testing.T) {
+	s, outbox, w := createChestSimulator(map[int]protocol.Slot{0: dirt})
+	Expect(t, s.Click(w, 0, protocol.ButtonLeftMouse, protocol.ClickModeNormal), ToBeNil)
+	Expect(t, s.Click(w, 1, protocol.ButtonLeftMouse, protocol.ClickModeNormal), ToBeNil)
+	Expect(t, sentPackets(outbox, (*protocol.ClickWindow)(nil)), ToEqual, []interface{}{
+		&protocol.ClickWindow{WindowID: 2, Slot: 0, ActionNumber: 1, ClickedItem: dirt},
+		&protocol.ClickWindow{WindowID: 2, Slot: 1, ActionNumber: 2, ClickedItem: protocol.EmptySlot},
+	})
+	Expect(t, w.Slot(1), ToEqual, dirt)
+
+	s.ProcessMessage(&protocol.CloseWindow{WindowID: 2})
+	Expect(t, s.Click(w, 1, protocol.ButtonLeftMouse, protocol.ClickModeNormal), Not(ToBeNil))
+}
+
+func TestClickPredictsLeftAndRightClicks(t *testing.T) {
+	s, _, w := createChestSimulator(map[int]protocol.Slot{0: stack(stone, 7), 1: stack(stone, 60), 2: dirt})
+	cursor := &s.World.CurrentPlayer.Cursor
+
+	// right clicks take the larger half
+	s.Click(w, 0, protocol.ButtonRightMouse, protocol.ClickModeNormal)
+	Expect(t, *cursor, ToEqual, stack(stone, 4))
+	Expect(t, w.Slot(0), ToEqual, stack(stone, 3))
+
+	// and put single items
+	s.Click(w, 3, protocol.ButtonRightMouse, protocol.ClickModeNormal)
+	Expect(t, w.Slot(3), ToEqual, stack(stone, 1))
+	Expect(t, *cursor, ToEqual, stack(stone, 3))
+
+	// stacks only grow to their maximum size
+	s.Click(w, 1, protocol.ButtonLeftMouse, protocol.ClickModeNormal)
+	Expect(t, w.Slot(1), ToEqual, stack(stone, 63))
+	Expect(t, *cursor, ToEqual, protocol.EmptySlot)
+
+	// different items are swapped
+	s.Click(w, 0, protocol.ButtonLeftMouse, protocol.ClickModeNormal)
+	s.Click(w, 2, protocol.ButtonLeftMouse, protocol.ClickModeNormal)
+	Expect(t, w.Slot(2), ToEqual, stack(stone, 3))
+	Expect(t, *cursor, ToEqual, dirt)
+
+	// clicking outside drops the cursor
+	s.Click(w, protocol.SlotOutsideWindow, protocol.ButtonRightMouse, protocol.ClickModeNormal)
+	Expect(t, *cursor, ToEqual, stack(dirt, 9))
+	s.Click(w, protocol.SlotOutsideWindow, protocol.ButtonLeftMouse, protocol.ClickModeNormal)
+	Expect(t, *cursor, ToEqual, protocol.EmptySlot)
+}
+
+func TestClickPredictsShiftClicks(t *testing.T) {
+	s, _, w := createChestSimulator(map[int]protocol.Slot{0: dirt, 1: stack(stone, 32), 30: stack(stone, 40)})
+	// from the chest to the hotbar's right end
+	s.Click(w, 0, protocol.ButtonLeftMouse, protocol.ClickModeShift)
+	Expect(t, w.Slot(0), ToEqual, protocol.EmptySlot)
+	Expect(t, w.Slot(62), ToEqual, dirt)
+	Expect(t, s.World.CurrentPlayer.Inventory.Slot(44), ToEqual, dirt)
+
+	// existing stacks are filled first
+	s.Click(w, 1, protocol.ButtonLeftMouse, protocol.ClickModeShift)
+	Expect(t, w.Slot(30), ToEqual, stack(stone, 64))
+	Expect(t, w.Slot(61), ToEqual, stack(stone, 8))
+
+	// from the player's inventory into the chest
+	s.Click(w, 30, protocol.ButtonLeftMouse, protocol.ClickModeShift)
+	Expect(t, w.Slot(0), ToEqual, stone)
+	Expect(t, w.Slot(30), ToEqual, protocol.EmptySlot)
+}
+
+func TestClickPredictsShiftClicksInTheInventory(t *testing.T) {
+	s, _, _ := createSimulator()
+	planks := protocol.Slot{ID: 5, Count: 4}
+	s.ProcessMessage(&protocol.SetWindowItems{WindowID: 0, Slots: slots(45, map[int]protocol.Slot{
+		0: planks, 1: protocol.Slot{ID: 17, Count: 10}, 9: sword,
+	})})
+	w := s.World.CurrentPlayer.Inventory
+	s.Click(w, 9, protocol.ButtonLeftMouse, protocol.ClickModeShift)
+	Expect(t, w.Slot(36), ToEqual, sword)
+	s.Click(w, 36, protocol.ButtonLeftMouse, protocol.ClickModeShift)
+	Expect(t, w.Slot(9), ToEqual, sword)
+
+	// taking the crafting result uses up the ingredients
+	s.Click(w, 0, protocol.ButtonLeftMouse, protocol.ClickModeShift)
+	Expect(t, w.Slot(44), ToEqual, planks)
+	Expect(t, w.Slot(1), ToEqual, protocol.Slot{ID: 17, Count: 9})
+	Expect(t, w.Slot(0), ToEqual, planks)
+}
+
+func TestClickPredictsShiftClicksFromFurnaces(t *testing.T) {
+	s, _, _ := createSimulator()
+	s.ProcessMessage(&protocol.OpenWindow{WindowID: 3, InventoryType: protocol.WindowTypeFurnance, NumSlots: 3})
+	s.ProcessMessage(&protocol.SetWindowItems{WindowID: 3, Slots: slots(39, map[int]protocol.Slot{0: dirt, 2: stone})})
+	w := s.World.CurrentPlayer.Window
+	// inputs go to the start of the main inventory
+	s.Click(w, 0, protocol.ButtonLeftMouse, protocol.ClickModeShift)
+	Expect(t, w.Slot(3), ToEqual, dirt)
+	// results go to the hotbar's right end
+	s.Click(w, 2, protocol.ButtonLeftMouse, protocol.ClickModeShift)
+	Expect(t, w.Slot(38), ToEqual, stone)
+}
+
+func TestClickPredictsShiftClickingArmorOn(t *testing.T) {
+	s, _, _ := createSimulator()
+	helmet := protocol.Slot{ID: 306, Count: 1}
+	boots := protocol.Slot{ID: 309, Count: 1}
+	s.ProcessMessage(&protocol.SetWindowItems{WindowID: 0, Slots: slots(45, map[int]protocol.Slot{
+		9: helmet, 10: helmet, 36: boots,
+	})})
+	w := s.World.CurrentPlayer.Inventory
+	s.Click(w, 9, protocol.ButtonLeftMouse, protocol.ClickModeShift)
+	Expect(t, w.Slot(5), ToEqual, helmet)
+	s.Click(w, 36, protocol.ButtonLeftMouse, protocol.ClickModeShift)
+	Expect(t, w.Slot(8), ToEqual, boots)
+
+	// already wearing a helmet
+	s.Click(w, 10, protocol.ButtonLeftMouse, protocol.ClickModeShift)
+	Expect(t, w.Slot(10), ToEqual, protocol.EmptySlot)
+	Expect(t, w.Slot(36), ToEqual, helmet)
+}
+
+func TestClickPredictsHotbarSwaps(t *testing.T) {
+	s, _, w := createChestSimulator(map[int]protocol.Slot{0: dirt, 55: sword})
+	Expect(t, s.SwapWithHotbar(w, 0, 1), ToBeNil)
+	Expect(t, w.Slot(0), ToEqual, sword)
+	Expect(t, w.Slot(55), ToEqual, dirt)
+	Expect(t, s.SwapWithHotbar(w, 0, 9), Not(ToBeNil))
+}
+
+func TestClickPredictsDrops(t *testing.T) {
+	s, outbox, w := createChestSimulator(map[int]protocol.Slot{0: dirt})
+	Expect(t, s.DropItems(w, 0, false), ToBeNil)
+	Expect(t, w.Slot(0), ToEqual, stack(dirt, 9))
+	Expect(t, s.DropItems(w, 0, true), ToBeNil)
+	Expect(t, w.Slot(0), ToEqual, protocol.EmptySlot)
+	clicks := sentPackets(outbox, (*protocol.ClickWindow)(nil))
+	Expect(t, clicks[1].(*protocol.ClickWindow).Mode, ToEqual, protocol.ClickModeDrop)
+	Expect(t, clicks[1].(*protocol.ClickWindow).MouseButton, ToEqual, protocol.ButtonRightMouse)
+}
+
+func TestClickPredictsCloningInCreativeMode(t *testing.T) {
+	s, _, w := createChestSimulator(map[int]protocol.Slot{0: dirt})
+	s.Click(w, 0, protocol.ButtonMiddleMouse, protocol.ClickModeMiddle)
+	Expect(t, s.World.CurrentPlayer.Cursor, ToEqual, protocol.EmptySlot)
+
+	s.World.GameMode = protocol.GameModeCreative
+	s.Click(w, 0, protocol.ButtonMiddleMouse, protocol.ClickModeMiddle)
+	Expect(t, s.World.CurrentPlayer.Cursor, ToEqual, stack(dirt, 64))
+	Expect(t, w.Slot(0), ToEqual, dirt)
+}
+
+func TestMoveItems(t *testing.T) {
+	s, outbox, w := createChestSimulator(map[int]protocol.Slot{0: dirt, 1: stack(dirt, 60), 2: stone})
+	Expect(t, s.MoveItems(w, 0, 5, 3), ToBeNil)
+	Expect(t, w.Slot(0), ToEqual, stack(dirt, 7))
+	Expect(t, w.Slot(5), ToEqual, stack(dirt, 3))
+	Expect(t, s.World.CurrentPlayer.Cursor, ToEqual, protocol.EmptySlot)
+
+	clicks := sentPackets(outbox, (*protocol.ClickWindow)(nil))
+	Expect(t, clicks, ToBeLengthOf, 5)
+	Expect(t, clicks[0].(*protocol.ClickWindow).MouseButton, ToEqual, protocol.ButtonLeftMouse)
+	Expect(t, clicks[1].(*protocol.ClickWindow).MouseButton, ToEqual, protocol.ButtonRightMouse)
+	Expect(t, clicks[4].(*protocol.ClickWindow).Slot, ToEqual, int16(0))
+
+	Expect(t, s.MoveItems(w, 0, 6, 7), ToBeNil)
+	Expect(t, w.Slot(0), ToEqual, protocol.EmptySlot)
+	Expect(t, w.Slot(6), ToEqual, stack(dirt, 7))
+	Expect(t, sentPackets(outbox, (*protocol.ClickWindow)(nil)), ToBeLengthOf, 2)
+
+	Expect(t, s.MoveItems(w, 6, 2, 1), Not(ToBeNil))
+	Expect(t, s.MoveItems(w, 6, 1, 7), Not(ToBeNil))
+	Expect(t, s.MoveItems(w, 6, 7, 8), Not(ToBeNil))
+	Expect(t, s.MoveItems(w, 3, 7, 1), Not(ToBeNil))
+	Expect(t, sentPackets(outbox, (*protocol.ClickWindow)(nil)), ToBeEmpty)
+}
+
+func TestDistribute(t *testing.T) {
+	s, outbox, w := createChestSimulator(map[int]protocol.Slot{0: dirt, 2: stack(dirt, 1)})
+	s.Click(w, 0, protocol.ButtonLeftMouse, protocol.ClickModeNormal)
+	sentPackets(outbox, (*protocol.ClickWindow)(nil))
+
+	Expect(t, s.Distribute(w, []int16{1, 2, 3}, true), ToBeNil)
+	Expect(t, w.Slot(1), ToEqual, stack(dirt, 3))
+	Expect(t, w.Slot(2), ToEqual, stack(dirt, 4))
+	Expect(t, w.Slot(3), ToEqual, stack(dirt, 3))
+	Expect(t, s.World.CurrentPlayer.Cursor, ToEqual, stack(dirt, 1))
+
+	clicks := sentPackets(outbox, (*protocol.ClickWindow)(nil))
+	Expect(t, clicks, ToBeLengthOf, 5)
+	Expect(t, clicks[0].(*protocol.ClickWindow).Slot, ToEqual, protocol.SlotOutsideWindow)
+	Expect(t, clicks[0].(*protocol.ClickWindow).MouseButton, ToEqual, protocol.ButtonStartLeftDrag)
+	Expect(t, clicks[2].(*protocol.ClickWindow).MouseButton, ToEqual, protocol.ButtonAddLeftDrag)
+	Expect(t, clicks[4].(*protocol.ClickWindow).MouseButton, ToEqual, protocol.ButtonEndLeftDrag)
+
+	Expect(t, s.Distribute(w, []int16{4}, false), ToBeNil)
+	Expect(t, w.Slot(4), ToEqual, stack(dirt, 1))
+	Expect(t, s.World.CurrentPlayer.Cursor, ToEqual, protocol.EmptySlot)
+	Expect(t, s.Distribute(w, []int16{5}, false), Not(ToBeNil))
+}
+
+func TestGatherStack(t *testing.T) {
+	s, outbox, w := createChestSimulator(map[int]protocol.Slot{
+		0: stack(stone, 20), 1: stone, 2: stack(stone, 30), 3: dirt,
+	})
+	Expect(t, s.GatherStack(w, 0), ToBeNil)
+	// partial stacks are taken before full ones
+	Expect(t, w.Slot(0), ToEqual, stone)
+	Expect(t, w.Slot(1), ToEqual, stack(stone, 50))
+	Expect(t, w.Slot(2), ToEqual, protocol.EmptySlot)
+	Expect(t, w.Slot(3), ToEqual, dirt)
+	Expect(t, sentPackets(outbox, (*protocol.ClickWindow)(nil))[1].(*protocol.ClickWindow).Mode, ToEqual, protocol.ClickModeDoubleClick)
+}
+
+func TestRejectedClicksAreReconciled(t *testing.T) {
+	s, outbox, w := createChestSimulator(map[int]protocol.Slot{0: dirt})
+	events := make([]interface{}, 0)
+	s.Listen(func(event interface{}) { events = append(events, event) })
+
+	s.QuickMove(w, 0)
+	sentPackets(outbox, (*protocol.ClickWindow)(nil))
+	s.ProcessMessage(&protocol.ConfirmTransaction{WindowID: 2, ActionNumber: 1, Accepted: false})
+	Expect(t, <-outbox, ToEqual, &protocol.ConfirmTransaction{WindowID: 2, ActionNumber: 1, Accepted: true})
+	Expect(t, events[0], ToEqual, &ClickConfirmedEvent{Window: w, ActionNumber: 1, Accepted: false})
+
+	// the server sends what the window really holds
+	s.ProcessMessage(&protocol.SetWindowItems{WindowID: 2, Slots: slots(63, map[int]protocol.Slot{0: dirt})})
+	Expect(t, w.Slot(0), ToEqual, dirt)
+	Expect(t, w.Slot(62), ToEqual, protocol.EmptySlot)
+	Expect(t, s.World.CurrentPlayer.Inventory.Slot(44), ToEqual, protocol.EmptySlot)
+
+	s.ProcessMessage(&protocol.ConfirmTransaction{WindowID: 2, ActionNumber: 2, Accepted: true})
+	Expect(t, len(outbox), ToEqual, 0)
+}
@@ -0,0 +1,234 @@
+package simulator
+
+import (
+	"math"
+	"mc/registry"
+)
+
+// An axis-aligned box, in blocks.
+type BoundingBox struct {
+	Min, Max Vector3Float
+}
+
+func NewBoundingBox(x1, y1, z1, x2, y2, z2 float64) BoundingBox {
+	return BoundingBox{Vector3Float{x1, y1, z1}, Vector3Float{x2, y2, z2}}
+}
+
+// Returns the box moved by the given amount.
+func (b BoundingBox) Offset(dx, dy, dz float64) BoundingBox {
+	return NewBoundingBox(b.Min.X+dx, b.Min.Y+dy, b.Min.Z+dz, b.Max.X+dx, b.Max.Y+dy, b.Max.Z+dz)
+}
+
+// Returns the box grown to include everything it passes through when
+// moving by the given amount.
+func (b BoundingBox) Extend(dx, dy, dz float64) BoundingBox {
+	e := b
+	if dx < 0 {
+		e.Min.X += dx
+	} else {
+		e.Max.X += dx
+	}
+	if dy < 0 {
+		e.Min.Y += dy
+	} else {
+		e.Max.Y += dy
+	}
+	if dz < 0 {
+		e.Min.Z += dz
+	} else {
+		e.Max.Z += dz
+	}
+	return e
+}
+
+// Returns the box shrunk by the given amount on every side.
+func (b BoundingBox) Contract(x, y, z float64) BoundingBox {
+	return NewBoundingBox(b.Min.X+x, b.Min.Y+y, b.Min.Z+z, b.Max.X-x, b.Max.Y-y, b.Max.Z-z)
+}
+
+// Returns the distance from the point to the closest point of the box.
+func (b BoundingBox) DistanceTo(p Vector3Float) float64 {
+	closest := func(p, min, max float64) float64 {
+		return math.Max(min, math.Min(p, max)) - p
+	}
+	dx := closest(p.X, b.Min.X, b.Max.X)
+	dy := closest(p.Y, b.Min.Y, b.Max.Y)
+	dz := closest(p.Z, b.Min.Z, b.Max.Z)
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
+
+func (b BoundingBox) Intersects(o BoundingBox) bool {
+	return b.Max.X > o.Min.X && b.Min.X < o.Max.X &&
+		b.Max.Y > o.Min.Y && b.Min.Y < o.Max.Y &&
+		b.Max.Z > o.Min.Z && b.Min.Z < o.Max.Z
+}
+
+// Returns how far the box can move along the x axis, up to dx, before
+// hitting the obstacle.
+func (b BoundingBox) clipX(o BoundingBox, dx float64) float64 {
+	if b.Max.Y <= o.Min.Y || b.Min.Y >= o.Max.Y || b.Max.Z <= o.Min.Z || b.Min.Z >= o.Max.Z {
+		return dx
+	}
+	if dx > 0 && b.Max.X <= o.Min.X {
+		dx = math.Min(dx, o.Min.X-b.Max.X)
+	} else if dx < 0 && b.Min.X >= o.Max.X {
+		dx = math.Max(dx, o.Max.X-b.Min.X)
+	}
+	return dx
+}
+
+func (b BoundingBox) clipY(o BoundingBox, dy float64) float64 {
+	if b.Max.X <= o.Min.X || b.Min.X >= o.Max.X || b.Max.Z <= o.Min.Z || b.Min.Z >= o.Max.Z {
+		return dy
+	}
+	if dy > 0 && b.Max.Y <= o.Min.Y {
+		dy = math.Min(dy, o.Min.Y-b.Max.Y)
+	} else if dy < 0 && b.Min.Y >= o.Max.Y {
+		dy = math.Max(dy, o.Max.Y-b.Min.Y)
+	}
+	return dy
+}
+
+func (b BoundingBox) clipZ(o BoundingBox, dz float64) float64 {
+	if b.Max.X <= o.Min.X || b.Min.X >= o.Max.X || b.Max.Y <= o.Min.Y || b.Min.Y >= o.Max.Y {
+		return dz
+	}
+	if dz > 0 && b.Max.Z <= o.Min.Z {
+		dz = math.Min(dz, o.Min.Z-b.Max.Z)
+	} else if dz < 0 && b.Min.Z >= o.Max.Z {
+		dz = math.Max(dz, o.Max.Z-b.Min.Z)
+	}
+	return dz
+}
+
+//////////////////////////////////////////////////////////
+
+// Returns the boxes entities collide with for a block at the origin.
+// Blocks that don't fill their space, like slabs and fences, have
+// smaller or taller boxes. Boxes that depend on neighboring blocks,
+// like those of stairs and panes, are approximated as full blocks.
+func (b Block) BoundingBoxes() []BoundingBox {
+	info, ok := registry.BlockByID(b.Type)
+	if !ok || !info.Solid {
+		return nil
+	}
+	const pixel = 1.0 / 16
+	full := NewBoundingBox(0, 0, 0, 1, 1, 1)
+	switch info.Name {
+	case "stone_slab", "wooden_slab":
+		if b.Metadata&0x8 != 0 {
+			return []BoundingBox{NewBoundingBox(0, 0.5, 0, 1, 1, 1)}
+		}
+		return []BoundingBox{NewBoundingBox(0, 0, 0, 1, 0.5, 1)}
+	case "fence", "nether_brick_fence", "cobblestone_wall":
+		return []BoundingBox{NewBoundingBox(0, 0, 0, 1, 1.5, 1)}
+	case "fence_gate":
+		if b.Metadata&0x4 != 0 { // open
+			return nil
+		}
+		return []BoundingBox{NewBoundingBox(0, 0, 0, 1, 1.5, 1)}
+	case "wooden_door", "iron_door":
+		// open doors swing out of the way
+		if b.Metadata&0x8 == 0 && b.Metadata&0x4 != 0 {
+			return nil
+		}
+	case "trapdoor":
+		if b.Metadata&0x4 != 0 {
+			return nil
+		}
+		if b.Metadata&0x8 != 0 {
+			return []BoundingBox{NewBoundingBox(0, 1-3*pixel, 0, 1, 1, 1)}
+		}
+		return []BoundingBox{NewBoundingBox(0, 0, 0, 1, 3*pixel, 1)}
+	case "farmland":
+		return []BoundingBox{NewBoundingBox(0, 0, 0, 1, 15*pixel, 1)}
+	case "soul_sand":
+		return []BoundingBox{NewBoundingBox(0, 0, 0, 1, 14*pixel, 1)}
+	case "cactus":
+		return []BoundingBox{NewBoundingBox(pixel, 0, pixel, 1-pixel, 15*pixel, 1-pixel)}
+	case "cake":
+		return []BoundingBox{NewBoundingBox(pixel, 0, pixel, 1-pixel, 0.5, 1-pixel)}
+	case "bed":
+		return []BoundingBox{NewBoundingBox(0, 0, 0, 1, 9*pixel, 1)}
+	case "chest", "trapped_chest", "ender_chest":
+		return []BoundingBox{NewBoundingBox(pixel, 0, pixel, 1-pixel, 14*pixel, 1-pixel)}
+	case "enchanting_table":
+		return []BoundingBox{NewBoundingBox(0, 0, 0, 1, 0.75, 1)}
+	case "end_portal_frame":
+		return []BoundingBox{NewBoundingBox(0, 0, 0, 1, 13*pixel, 1)}
+	case "daylight_detector":
+		return []BoundingBox{NewBoundingBox(0, 0, 0, 1, 6*pixel, 1)}
+	case "unpowered_repeater", "powered_repeater", "unpowered_comparator", "powered_comparator":
+		return []BoundingBox{NewBoundingBox(0, 0, 0, 1, 2*pixel, 1)}
+	case "carpet":
+		return []BoundingBox{NewBoundingBox(0, 0, 0, 1, pixel, 1)}
+	case "waterlily":
+		return []BoundingBox{NewBoundingBox(0, 0, 0, 1, pixel/4, 1)}
+	}
+	return []BoundingBox{full}
+}
+
+// Returns the boxes of the blocks that intersect the given box. Blocks
+// in unloaded columns are solid, so nothing walks into them.
+func (w *World) CollisionBoxes(box BoundingBox) []BoundingBox {
+	boxes := make([]BoundingBox, 0)
+	minX, maxX := int32(math.Floor(box.Min.X)), int32(math.Floor(box.Max.X))
+	minY, maxY := int32(math.Floor(box.Min.Y))-1, int32(math.Floor(box.Max.Y))
+	minZ, maxZ := int32(math.Floor(box.Min.Z)), int32(math.Floor(box.Max.Z))
+	for x := minX; x <= maxX; x++ {
+		for z := minZ; z <= maxZ; z++ {
+			if w.ColumnAt(x, z) == nil {
+				boxes = append(boxes, NewBoundingBox(float64(x), -math.MaxFloat32, float64(z), float64(x+1), math.MaxFloat32, float64(z+1)))
+				continue
+			}
+			for y := minY; y <= maxY; y++ {
+				block, ok := w.collisionBlock(x, y, z)
+				if !ok {
+					continue
+				}
+				for _, b := range block.BoundingBoxes() {
+					b = b.Offset(float64(x), float64(y), float64(z))
+					if b.Intersects(box) {
+						boxes = append(boxes, b)
+					}
+				}
+			}
+		}
+	}
+	return boxes
+}
+
+// Returns the block at the given coordinates, with the metadata its
+// bounding boxes depend on. The top halves of doors only know if the
+// door is open from the bottom half.
+func (w *World) collisionBlock(x, y, z int32) (Block, bool) {
+	block, ok := w.BlockAt(x, y, z)
+	if ok && isOneOf(block.Type, doorBlocks) && block.Metadata&0x8 != 0 {
+		if bottom, _ := w.BlockAt(x, y-1, z); bottom.Type == block.Type {
+			block.Metadata = bottom.Metadata
+		}
+	}
+	return block, ok
+}
+
+// Returns true if any block intersecting the box is one of the given
+// types.
+func (w *World) containsBlock(box BoundingBox, types ...int16) bool {
+	minX, maxX := int32(math.Floor(box.Min.X)), int32(math.Floor(box.Max.X))
+	minY, maxY := int32(math.Floor(box.Min.Y)), int32(math.Floor(box.Max.Y))
+	minZ, maxZ := int32(math.Floor(box.Min.Z)), int32(math.Floor(box.Max.Z))
+	for x := minX; x <= maxX; x++ {
+		for y := minY; y <= maxY; y++ {
+			for z := minZ; z <= maxZ; z++ {
+				block, ok := w.BlockAt(x, y, z)
+				if !ok {
+					continue
+				}
+				if isOneOf(block.Type, types) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
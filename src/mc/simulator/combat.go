@@ -0,0 +1,332 @@
+package simulator
+
+import (
+	"math"
+	"math/rand"
+	"mc/protocol"
+	"mc/registry"
+	"time"
+)
+
+const (
+	// how far from the player's eyes entities can be hit
+	AttackReach = 3.0
+	// how far away targets are looked for
+	combatRange = 16
+	// how close something has to be to have hurt the player in melee
+	attackerRange = 5
+	// ticks that something that hurt the player is remembered
+	attackerMemoryTicks = 200
+	// ticks before hitting a new target, like a person reacting
+	reactionTicks = 5
+	// entities can only be hurt once every 10 ticks, so hits are spread
+	// out by this many ticks plus some randomness
+	attackTicks       = 10
+	attackJitterTicks = 4
+)
+
+// Mobs that attack players on sight.
+var hostileMobs = map[protocol.MobType]bool{
+	protocol.MobCreeper:     true,
+	protocol.MobSkeleton:    true,
+	protocol.MobSpider:      true,
+	protocol.MobGiantZombie: true,
+	protocol.MobZombie:      true,
+	protocol.MobSlime:       true,
+	protocol.MobGhast:       true,
+	protocol.MobCaveSpider:  true,
+	protocol.MobSilverFish:  true,
+	protocol.MobBlaze:       true,
+	protocol.MobMagmaCube:   true,
+	protocol.MobEnderDragon: true,
+	protocol.MobWither:      true,
+	protocol.MobWitch:       true,
+}
+
+// Returns true if the entity is a mob that attacks players on sight.
+func (e *Entity) IsHostile() bool {
+	return e.Kind == MobEntity && hostileMobs[e.MobType]
+}
+
+// Which entities the current player fights.
+type TargetPolicy int
+
+const (
+	TargetHostiles  TargetPolicy = iota // the nearest hostile mob
+	TargetPlayer                        // the player named by Combat.PlayerName
+	TargetAttackers                     // the nearest entity that hurt the player
+)
+
+// Emitted when the current player picks a new target, or loses its
+// target, in which case Target is nil.
+type TargetChangedEvent struct {
+	Target *Entity
+}
+
+// Emitted when the current player loses health.
+type DamageTakenEvent struct {
+	Amount   float32
+	Health   float32 // what's left
+	Attacker *Entity // the entity that most likely hurt the player, or nil
+}
+
+// Picks targets for the current player, faces them and attacks them
+// with the best weapon in the inventory every Tick.
+type Combat struct {
+	IsFighting bool
+	Policy     TargetPolicy
+	PlayerName string // for TargetPlayer
+	Target     *Entity
+	// Walks towards targets that are out of reach by changing the
+	// simulator's Controls.
+	Chase bool
+
+	cooldown  int           // ticks before the next hit
+	attackers map[int32]int // ticks since each entity hurt the player
+	random    *rand.Rand
+}
+
+func NewCombat() *Combat {
+	return &Combat{
+		attackers: make(map[int32]int),
+		random:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Fights the nearest hostile mobs.
+func (s *Simulator) FightHostiles() {
+	s.fight(TargetHostiles, "")
+}
+
+// Fights the player with the given name.
+func (s *Simulator) FightPlayer(name string) {
+	s.fight(TargetPlayer, name)
+}
+
+// Fights back against whatever hurts the current player.
+func (s *Simulator) FightAttackers() {
+	s.fight(TargetAttackers, "")
+}
+
+func (s *Simulator) fight(policy TargetPolicy, name string) {
+	c := s.Combat
+	c.IsFighting = true
+	c.Policy = policy
+	c.PlayerName = name
+}
+
+// Stops fighting, releasing the controls if chasing.
+func (s *Simulator) StopFighting() {
+	c := s.Combat
+	c.IsFighting = false
+	if c.Chase {
+		s.Controls = Controls{}
+	}
+	s.changeTarget(nil)
+}
+
+// Returns the entities that hurt the current player recently.
+func (s *Simulator) Attackers() []*Entity {
+	attackers := make([]*Entity, 0, len(s.Combat.attackers))
+	for id := range s.Combat.attackers {
+		if e := s.World.EntityByID(id); e != nil {
+			attackers = append(attackers, e)
+		}
+	}
+	return attackers
+}
+
+func (s *Simulator) changeTarget(e *Entity) {
+	c := s.Combat
+	if e == c.Target {
+		return
+	}
+	c.Target = e
+	c.cooldown = reactionTicks
+	s.emit(&TargetChangedEvent{Target: e})
+}
+
+func (s *Simulator) tickCombat() {
+	c := s.Combat
+	for id, ticks := range c.attackers {
+		if ticks >= attackerMemoryTicks || s.World.EntityByID(id) == nil {
+			delete(c.attackers, id)
+		} else {
+			c.attackers[id] = ticks + 1
+		}
+	}
+	player := &s.World.CurrentPlayer
+	if !c.IsFighting || player.Entity == nil || player.IsDead {
+		return
+	}
+
+	s.changeTarget(s.selectTarget())
+	target := c.Target
+	if target == nil {
+		if c.Chase {
+			s.Controls = Controls{}
+		}
+		return
+	}
+	s.lookAt(entityCenter(target))
+	aimed, _ := s.TargetEntity()
+	inReach := aimed == target
+	if c.Chase {
+		s.Controls = Controls{Forward: 1}
+		if inReach {
+			s.Controls = Controls{}
+		}
+	}
+	if c.cooldown > 0 {
+		c.cooldown--
+		return
+	}
+	if !inReach || s.eating.isEating {
+		return
+	}
+	if err := s.holdBestWeapon(); err != nil {
+		s.Logger.Printf("Can't switch weapons: %s", err)
+	}
+	s.swingArm()
+	s.send(&protocol.UseEntity{
+		User:              player.Entity.ID,
+		Target:            target.ID,
+		IsLeftMouseButton: true,
+	})
+	// this tick counts towards the next hit
+	c.cooldown = attackTicks - 1 + c.random.Intn(attackJitterTicks+1)
+}
+
+// Returns the target the policy picks. The current target is kept while
+// it's still a valid choice.
+func (s *Simulator) selectTarget() *Entity {
+	c := s.Combat
+	if c.Target != nil && s.isTarget(c.Target) {
+		return c.Target
+	}
+	var nearest *Entity
+	best := math.MaxFloat64
+	p := s.World.CurrentPlayer.Entity.Position
+	for _, e := range s.World.Entities {
+		if !s.isTarget(e) {
+			continue
+		}
+		if d := pointDistance(p, e.Position); d < best {
+			nearest, best = e, d
+		}
+	}
+	return nearest
+}
+
+// Returns true if the policy allows fighting the entity.
+func (s *Simulator) isTarget(e *Entity) bool {
+	c := s.Combat
+	player := s.World.CurrentPlayer.Entity
+	if e == player || e.IsDead || s.World.EntityByID(e.ID) != e ||
+		pointDistance(player.Position, e.Position) > combatRange {
+		return false
+	}
+	switch c.Policy {
+	case TargetHostiles:
+		return e.IsHostile()
+	case TargetPlayer:
+		return e.Kind == PlayerEntity && e.Name == c.PlayerName
+	case TargetAttackers:
+		if e.Kind == PlayerEntity && s.World.IsProtectedTeammate(e.Name) {
+			return false
+		}
+		_, ok := c.attackers[e.ID]
+		return ok
+	}
+	return false
+}
+
+// Remembers what most likely hurt the current player: the shooter of a
+// nearby arrow, or else the closest mob or player. Returns nil if
+// nothing could have.
+func (s *Simulator) recordAttacker() *Entity {
+	me := s.World.CurrentPlayer.Entity
+	if me == nil {
+		return nil
+	}
+	var attacker *Entity
+	best := float64(attackerRange)
+	for _, e := range s.World.Entities {
+		if e.Kind == ObjectEntity && e.Type == protocol.EntityArrow && pointDistance(me.Position, e.Position) < 2 {
+			if shooter := s.World.EntityByID(e.OwnerID); shooter != nil && shooter != me {
+				attacker = shooter
+				break
+			}
+		}
+		if e == me || e.Kind != MobEntity && e.Kind != PlayerEntity {
+			continue
+		}
+		if d := pointDistance(me.Position, e.Position); d < best {
+			attacker, best = e, d
+		}
+	}
+	if attacker != nil {
+		s.Combat.attackers[attacker.ID] = 0
+	}
+	return attacker
+}
+
+func (s *Simulator) tookDamage(amount, health float32) {
+	s.emit(&DamageTakenEvent{
+		Amount:   amount,
+		Health:   health,
+		Attacker: s.recordAttacker(),
+	})
+}
+
+//////////////////////////////////////////////////////////
+
+// Returns the damage a hit with the item does, before armor.
+func attackDamage(item protocol.Slot) float32 {
+	damage := float32(1)
+	if tool := toolOf(item); tool != nil {
+		var bonus float32
+		switch tool.Material {
+		case registry.Stone:
+			bonus = 1
+		case registry.Iron:
+			bonus = 2
+		case registry.Diamond:
+			bonus = 3
+		}
+		switch tool.Tool {
+		case registry.Sword:
+			damage += 4 + bonus
+		case registry.Axe:
+			damage += 3 + bonus
+		case registry.Pickaxe:
+			damage += 2 + bonus
+		case registry.Shovel:
+			damage += 1 + bonus
+		}
+	}
+	if level := Enchantments(item)[EnchantmentSharpness]; level > 0 {
+		damage += float32(level) * 1.25
+	}
+	return damage
+}
+
+// Switches to the item of the player's inventory that does the most
+// damage, keeping the held item if nothing is better.
+func (s *Simulator) holdBestWeapon() error {
+	player := &s.World.CurrentPlayer
+	inventory := player.Inventory
+	best := inventory.HotbarSlot(player.HeldItemSlot)
+	damage := attackDamage(inventory.Slot(best))
+	for _, slot := range s.usableSlots() {
+		if d := attackDamage(inventory.Slot(slot)); d > damage {
+			best, damage = slot, d
+		}
+	}
+	return s.holdSlot(best)
+}
+
+// Returns the middle of the entity, which is aimed at.
+func entityCenter(e *Entity) Vector3Float {
+	return Vector3Float(EntityBoundingBox(e).box().Center())
+}
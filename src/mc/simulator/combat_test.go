@@ -0,0 +1,155 @@
+package simulator
+
+import (
+	. "github.com/jeffh/goexpect"
+	"mc/protocol"
+	"testing"
+)
+
+var (
+	woodenSword  = protocol.Slot{ID: 268, Count: 1}
+	diamondSword = protocol.Slot{ID: 276, Count: 1}
+)
+
+func spawnMob(s *Simulator, id int32, mob protocol.MobType, x, z float64) *Entity {
+	s.ProcessMessage(&protocol.SpawnMob{EntityID: id, Type: mob, X: int32(x * 32), Y: 16 * 32, Z: int32(z * 32)})
+	return s.World.EntityByID(id)
+}
+
+func spawnPlayer(s *Simulator, id int32, name string, x, z float64) *Entity {
+	s.ProcessMessage(&protocol.SpawnNamedEntity{EntityID: id, PlayerName: name, X: int32(x * 32), Y: 16 * 32, Z: int32(z * 32)})
+	return s.World.EntityByID(id)
+}
+
+// Ticks the simulator, returning the tick of each attack and the
+// entities attacked.
+func tickCombat(s *Simulator, outbox chan interface{}, ticks int) ([]int, []int32) {
+	at, targets := make([]int, 0), make([]int32, 0)
+	for i := 0; i < ticks; i++ {
+		s.Tick()
+		for _, p := range sentPackets(outbox, (*protocol.UseEntity)(nil)) {
+			at = append(at, i)
+			targets = append(targets, p.(*protocol.UseEntity).Target)
+		}
+	}
+	return at, targets
+}
+
+func TestAttackDamageDependsOnTheWeapon(t *testing.T) {
+	it := NewIt(t)
+	it.Expects(attackDamage(protocol.EmptySlot), ToEqual, float32(1))
+	it.Expects(attackDamage(stack(dirt, 1)), ToEqual, float32(1))
+	it.Expects(attackDamage(woodenSword), ToEqual, float32(5))
+	it.Expects(attackDamage(diamondSword), ToEqual, float32(8))
+	it.Expects(attackDamage(protocol.Slot{ID: 258, Count: 1}), ToEqual, float32(6)) // iron axe
+	it.Expects(attackDamage(enchanted(diamondSword, EnchantmentSharpness, 2)), ToEqual, float32(10.5))
+}
+
+func TestCombatAttacksTheNearestHostile(t *testing.T) {
+	it := NewIt(t)
+	s, outbox := createStandingSimulator(map[int]protocol.Slot{36: woodenSword, 20: diamondSword})
+	events := recordEvents(s, (*TargetChangedEvent)(nil), (*DamageTakenEvent)(nil))
+	spawnMob(s, 20, protocol.MobPig, 3.5, 2.5)
+	zombie := spawnMob(s, 21, protocol.MobZombie, 4.5, 2.5)
+	spawnMob(s, 22, protocol.MobZombie, 6.5, 2.5)
+	s.FightHostiles()
+
+	s.Tick()
+	it.Expects(*events, ToEqual, []interface{}{&TargetChangedEvent{zombie}})
+	it.Expects(s.World.CurrentPlayer.Entity.Facing.Yaw, ToEqual, float32(-90))
+
+	at, targets := tickCombat(s, outbox, 60)
+	it.Expects(at[0], ToEqual, reactionTicks-1)
+	for i := 1; i < len(at); i++ {
+		it.Expects(at[i]-at[i-1] >= attackTicks, ToBeTrue)
+		it.Expects(at[i]-at[i-1] <= attackTicks+attackJitterTicks, ToBeTrue)
+	}
+	it.Expects(len(at) >= 4, ToBeTrue)
+	it.Expects(targets[0], ToEqual, int32(21))
+	it.Expects(s.heldItem(), ToEqual, diamondSword)
+
+	// the next zombie is out of reach
+	s.ProcessMessage(&protocol.EntityStatus{EntityID: 21, Status: protocol.EntityStatusDead})
+	at, _ = tickCombat(s, outbox, 30)
+	it.Expects(at, ToBeEmpty)
+	it.Expects(s.Combat.Target, ToEqual, s.World.EntityByID(22))
+
+	s.StopFighting()
+	it.Expects(s.Combat.Target, ToBeNil)
+	it.Expects((*events)[len(*events)-1], ToEqual, &TargetChangedEvent{})
+}
+
+func TestCombatSwingsBeforeAttacking(t *testing.T) {
+	it := NewIt(t)
+	s, outbox := createStandingSimulator(nil)
+	spawnMob(s, 21, protocol.MobZombie, 4.5, 2.5)
+	s.FightHostiles()
+	for i := 0; i < reactionTicks+1; i++ {
+		s.Tick()
+	}
+	sent := sentPackets(outbox, (*protocol.UseEntity)(nil), (*protocol.Animation)(nil))
+	it.Expects(sent, ToEqual, []interface{}{
+		&protocol.Animation{EntityID: 7, Type: protocol.AnimationSwingArm},
+		&protocol.UseEntity{User: 7, Target: 21, IsLeftMouseButton: true},
+	})
+}
+
+func TestCombatFightsASpecificPlayer(t *testing.T) {
+	it := NewIt(t)
+	s, outbox := createStandingSimulator(nil)
+	spawnMob(s, 21, protocol.MobZombie, 3.5, 2.5)
+	spawnPlayer(s, 30, "Alex", 2.5, 3.5)
+	spawnPlayer(s, 31, "Steve", 2.5, 4.5)
+	s.FightPlayer("Steve")
+	_, targets := tickCombat(s, outbox, 20)
+	it.Expects(targets, ToBeEmpty) // Alex is in the way
+
+	s.World.EntityByID(30).Position.X = 4.5
+	_, targets = tickCombat(s, outbox, 20)
+	it.Expects(targets, Not(ToBeEmpty))
+	for _, id := range targets {
+		it.Expects(id, ToEqual, int32(31))
+	}
+}
+
+func TestCombatFightsBackAgainstAttackers(t *testing.T) {
+	it := NewIt(t)
+	s, outbox := createStandingSimulator(nil)
+	events := recordEvents(s, (*TargetChangedEvent)(nil), (*DamageTakenEvent)(nil))
+	spawnMob(s, 20, protocol.MobPig, 2.5, 5.5)
+	steve := spawnPlayer(s, 31, "Steve", 2.5, 4)
+	s.FightAttackers()
+	_, targets := tickCombat(s, outbox, 20)
+	it.Expects(targets, ToBeEmpty)
+
+	s.ProcessMessage(&protocol.EntityStatus{EntityID: 7, Status: protocol.EntityStatusHurt})
+	s.ProcessMessage(&protocol.UpdateHealth{Health: 17})
+	it.Expects(*events, ToEqual, []interface{}{&DamageTakenEvent{Amount: 3, Health: 17, Attacker: steve}})
+	it.Expects(s.Attackers(), ToEqual, []*Entity{steve})
+	_, targets = tickCombat(s, outbox, 20)
+	it.Expects(targets, Not(ToBeEmpty))
+	it.Expects(targets[0], ToEqual, int32(31))
+}
+
+func TestCombatBlamesArrowsOnTheirShooter(t *testing.T) {
+	it := NewIt(t)
+	s, _ := createStandingSimulator(nil)
+	events := recordEvents(s, (*TargetChangedEvent)(nil), (*DamageTakenEvent)(nil))
+	skeleton := spawnMob(s, 20, protocol.MobSkeleton, 12.5, 2.5)
+	spawnMob(s, 21, protocol.MobPig, 3.5, 2.5)
+	s.ProcessMessage(&protocol.SpawnObject{EntityID: 40, Type: protocol.EntityArrow, X: 3 * 32, Y: 17 * 32, Z: 2 * 32, OwnerEntityID: 20})
+	s.ProcessMessage(&protocol.UpdateHealth{Health: 16})
+	it.Expects((*events)[0].(*DamageTakenEvent).Attacker, ToEqual, skeleton)
+}
+
+func TestCombatChasesTargetsOutOfReach(t *testing.T) {
+	it := NewIt(t)
+	s, outbox := createStandingSimulator(nil)
+	spawnMob(s, 21, protocol.MobZombie, 2.5, 8.5)
+	s.Combat.Chase = true
+	s.FightHostiles()
+	at, _ := tickCombat(s, outbox, 60)
+	it.Expects(at, Not(ToBeEmpty))
+	it.Expects(s.World.CurrentPlayer.Entity.Position.Z > 4, ToBeTrue)
+	it.Expects(s.Controls, ToEqual, Controls{})
+}
@@ -0,0 +1,212 @@
+package simulator
+
+import (
+	"fmt"
+	"mc/protocol"
+	"mc/recipes"
+)
+
+// The slot that crafted items are taken from in the inventory and in
+// workbenches.
+const craftingResultSlot int16 = 0
+
+func itemOf(slot protocol.Slot) recipes.Item {
+	if slot.IsEmpty() {
+		return recipes.None
+	}
+	return recipes.Item{ID: slot.ID, Damage: slot.Damage}
+}
+
+// Puts what the crafting grid makes in the result slot, as the server
+// does.
+func (w *Window) updateCraftingResult() {
+	grid := w.SlotsIn(CraftingSlots)
+	if len(grid) == 0 {
+		return
+	}
+	items := make([]recipes.Item, len(grid))
+	for i, slot := range grid {
+		items[i] = itemOf(w.Slots[slot])
+	}
+	result := protocol.EmptySlot
+	if r := recipes.Match(items, craftingGridSize(w)); r != nil {
+		result = protocol.Slot{ID: r.Result.ID, Count: int8(r.Count), Damage: r.Result.Damage}
+	}
+	w.Slots[craftingResultSlot] = result
+}
+
+// Returns the width of the window's crafting grid.
+func craftingGridSize(w *Window) int {
+	if w.IsInventory() {
+		return 2
+	}
+	return 3
+}
+
+// Returns the counts of the items in the player's main inventory and
+// hotbar.
+func (s *Simulator) InventoryItems() recipes.Items {
+	items := make(recipes.Items)
+	inventory := s.World.CurrentPlayer.Inventory
+	for _, region := range []SlotRegion{MainInventorySlots, HotbarSlots} {
+		for _, i := range inventory.SlotsIn(region) {
+			if item := inventory.Slots[i]; !item.IsEmpty() {
+				items.Add(itemOf(item), int(item.Count))
+			}
+		}
+	}
+	return items
+}
+
+// Returns the opened workbench, or the inventory if no window is open.
+func (s *Simulator) craftingWindow() (*Window, error) {
+	player := &s.World.CurrentPlayer
+	switch {
+	case player.Window == nil:
+		return player.Inventory, nil
+	case player.Window.Type == protocol.WindowTypeWorkbench:
+		return player.Window, nil
+	}
+	return nil, fmt.Errorf("Can't craft in window %d", player.Window.ID)
+}
+
+// Crafts the recipe the given number of times, in the opened workbench
+// or in the inventory's crafting grid if no window is open. Ingredients
+// are taken from the player's main inventory and hotbar, and the
+// results are put back there.
+func (s *Simulator) Craft(r *recipes.Recipe, times int) error {
+	w, err := s.craftingWindow()
+	if err != nil {
+		return err
+	}
+	width := craftingGridSize(w)
+	if r.GridSize() > width {
+		return fmt.Errorf("Crafting %s needs a workbench", r.Result)
+	}
+	if err := s.checkEmptyCursor(); err != nil {
+		return err
+	}
+	for _, i := range w.SlotsIn(CraftingSlots) {
+		if item := w.Slots[i]; !item.IsEmpty() {
+			return fmt.Errorf("The crafting grid isn't empty")
+		}
+	}
+	items := s.InventoryItems()
+	batch := times
+	for _, need := range r.Needs() {
+		if items.Count(need.Item) < need.Count*times {
+			return &recipes.MissingItemsError{Missing: recipes.Items{
+				need.Item: need.Count*times - items.Count(need.Item),
+			}}
+		}
+		// each cell of the grid holds a stack of an ingredient
+		batch = min(batch, maxStackSize(protocol.Slot{ID: need.Item.ID}))
+	}
+
+	for times > 0 {
+		n := min(times, batch)
+		if err := s.craftBatch(w, r, width, n); err != nil {
+			return err
+		}
+		times -= n
+	}
+	return nil
+}
+
+// Fills the crafting grid with enough ingredients for n crafts, then
+// takes the results one craft at a time.
+func (s *Simulator) craftBatch(w *Window, r *recipes.Recipe, width, n int) error {
+	grid := w.SlotsIn(CraftingSlots)
+	for i, ingredient := range r.Ingredients {
+		if ingredient == recipes.None {
+			continue
+		}
+		cell := i
+		if r.IsShaped() {
+			cell = i/r.Width*width + i%r.Width
+		}
+		if err := s.fillCell(w, grid[cell], ingredient, n); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < n; i++ {
+		if result := w.Slot(craftingResultSlot); result.IsEmpty() {
+			return fmt.Errorf("Nothing to craft in the crafting grid")
+		}
+		if err := s.Click(w, craftingResultSlot, protocol.ButtonLeftMouse, protocol.ClickModeNormal); err != nil {
+			return err
+		}
+		if err := s.stowCursor(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Moves n items matching the ingredient from the player's slots to a
+// cell of the crafting grid.
+func (s *Simulator) fillCell(w *Window, cell int16, ingredient recipes.Item, n int) error {
+	for _, slot := range append(w.SlotsIn(MainInventorySlots), w.SlotsIn(HotbarSlots)...) {
+		if n == 0 {
+			break
+		}
+		item, target := w.Slots[slot], w.Slots[cell]
+		// a cell can only hold one variant of the ingredient
+		if !ingredient.Matches(itemOf(item)) || !target.IsEmpty() && !isSameItem(item, target) {
+			continue
+		}
+		count := min(n, int(item.Count))
+		if err := s.MoveItems(w, slot, cell, count); err != nil {
+			return err
+		}
+		n -= count
+	}
+	if n > 0 {
+		return fmt.Errorf("Not enough %s in one stack", ingredient)
+	}
+	return nil
+}
+
+// Puts the items on the cursor into the player's main inventory or
+// hotbar, onto stacks of the same item first.
+func (s *Simulator) stowCursor(w *Window) error {
+	cursor := &s.World.CurrentPlayer.Cursor
+	player := append(w.SlotsIn(MainInventorySlots), w.SlotsIn(HotbarSlots)...)
+	for _, sameItem := range []bool{true, false} {
+		for _, slot := range player {
+			if cursor.IsEmpty() {
+				return nil
+			}
+			item := w.Slots[slot]
+			if sameItem && isSameItem(item, *cursor) && int(item.Count) < maxStackSize(item) ||
+				!sameItem && item.IsEmpty() {
+				if err := s.Click(w, slot, protocol.ButtonLeftMouse, protocol.ClickModeNormal); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if !cursor.IsEmpty() {
+		return fmt.Errorf("No room in the inventory for %s", itemOf(*cursor))
+	}
+	return nil
+}
+
+// Crafts count of the item, and the intermediate items it needs, from
+// what's in the player's inventory.
+func (s *Simulator) CraftItem(item recipes.Item, count int) error {
+	w, err := s.craftingWindow()
+	if err != nil {
+		return err
+	}
+	steps, err := recipes.Plan(item, count, s.InventoryItems(), craftingGridSize(w))
+	if err != nil {
+		return err
+	}
+	for _, step := range steps {
+		if err := s.Craft(step.Recipe, step.Times); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package simulator
+
+import (
+	. "github.com/jeffh/goexpect"
+	"mc/protocol"
+	"mc/recipes"
+	"testing"
+)
+
+var (
+	logs   = protocol.Slot{ID: 17, Count: 3}
+	planks = protocol.Slot{ID: 5, Count: 4}
+
+	logItem    = recipes.Item{ID: 17}
+	planksItem = recipes.Item{ID: 5}
+	stickItem  = recipes.Item{ID: 280}
+)
+
+func createCraftingSimulator(items map[int]protocol.Slot) (*Simulator, chan interface{}) {
+	s, outbox, _ := createSimulator()
+	s.ProcessMessage(&protocol.SetWindowItems{WindowID: 0, Slots: slots(45, items)})
+	return s, outbox
+}
+
+func TestCraftingResultsArePredicted(t *testing.T) {
+	s, _ := createCraftingSimulator(map[int]protocol.Slot{36: logs})
+	w := s.World.CurrentPlayer.Inventory
+	s.MoveItems(w, 36, 4, 1)
+	Expect(t, w.Slot(0), ToEqual, planks)
+	s.MoveItems(w, 4, 36, 1)
+	Expect(t, w.Slot(0), ToEqual, protocol.EmptySlot)
+}
+
+func TestCraftInTheInventory(t *testing.T) {
+	s, outbox := createCraftingSimulator(map[int]protocol.Slot{36: logs})
+	r := recipes.For(planksItem)[0]
+	Expect(t, s.Craft(r, 2), ToBeNil)
+
+	w := s.World.CurrentPlayer.Inventory
+	Expect(t, w.Slot(36), ToEqual, stack(logs, 1))
+	Expect(t, w.Slot(9), ToEqual, stack(planks, 8))
+	Expect(t, w.SlotsIn(CraftingSlots), ToEqual, []int16{1, 2, 3, 4})
+	for _, i := range w.SlotsIn(CraftingSlots) {
+		Expect(t, w.Slot(i), ToEqual, protocol.EmptySlot)
+	}
+	Expect(t, s.World.CurrentPlayer.Cursor, ToEqual, protocol.EmptySlot)
+	Expect(t, s.InventoryItems(), ToEqual, recipes.Items{logItem: 1, planksItem: 8})
+
+	clicks := sentPackets(outbox, (*protocol.ClickWindow)(nil))
+	Expect(t, clicks[len(clicks)-2].(*protocol.ClickWindow).Slot, ToEqual, int16(0))
+	Expect(t, clicks[len(clicks)-2].(*protocol.ClickWindow).ClickedItem, ToEqual, planks)
+}
+
+func TestCraftNeedsAWorkbenchForLargeRecipes(t *testing.T) {
+	s, _ := createCraftingSimulator(map[int]protocol.Slot{9: stack(planks, 3), 10: {ID: 280, Count: 2}})
+	pickaxe := recipes.Item{ID: 270}
+	r := recipes.For(pickaxe)[0]
+	Expect(t, s.Craft(r, 1), Not(ToBeNil))
+
+	s.ProcessMessage(&protocol.OpenWindow{WindowID: 1, InventoryType: protocol.WindowTypeWorkbench, NumSlots: 9})
+	s.ProcessMessage(&protocol.SetWindowItems{WindowID: 1, Slots: slots(46, map[int]protocol.Slot{
+		10: stack(planks, 3), 11: {ID: 280, Count: 2},
+	})})
+	Expect(t, s.Craft(r, 1), ToBeNil)
+	Expect(t, s.InventoryItems(), ToEqual, recipes.Items{pickaxe: 1})
+}
+
+func TestCraftItemCraftsIntermediateItems(t *testing.T) {
+	s, _ := createCraftingSimulator(map[int]protocol.Slot{36: stack(logs, 2)})
+	torch := recipes.Item{ID: 50}
+	Expect(t, s.CraftItem(torch, 4), Not(ToBeNil))
+
+	s.ProcessMessage(&protocol.SetSlot{WindowID: 0, Slot: 37, Data: protocol.Slot{ID: 263, Count: 1}})
+	Expect(t, s.CraftItem(torch, 4), ToBeNil)
+	Expect(t, s.InventoryItems(), ToEqual, recipes.Items{
+		logItem:    1,
+		planksItem: 2,
+		stickItem:  3,
+		torch:      4,
+	})
+}
+
+func TestCraftChecksForIngredients(t *testing.T) {
+	s, outbox := createCraftingSimulator(map[int]protocol.Slot{36: logs})
+	r := recipes.For(planksItem)[0]
+	err := s.Craft(r, 4)
+	Expect(t, err, ToEqual, &recipes.MissingItemsError{Missing: recipes.Items{logItem: 1}})
+	Expect(t, sentPackets(outbox, (*protocol.ClickWindow)(nil)), ToBeEmpty)
+}
@@ -0,0 +1,374 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"mc/protocol"
+	"mc/registry"
+)
+
+const (
+	// how far from the player's eyes blocks can be reached
+	PlayerReach = 4.5
+	// ticks to wait after breaking a block before digging the next one
+	digDelayTicks = 5
+	// ticks to wait for the server to confirm a block was broken
+	digConfirmTicks = 20
+)
+
+// Emitted when the server confirms the block being dug was broken.
+type DiggingFinishedEvent struct {
+	Position Vector3Int
+	Block    Block // the block that was broken
+}
+
+// Emitted when digging stops before the block is broken, or the server
+// doesn't break it.
+type DiggingFailedEvent struct {
+	Position Vector3Int
+	Err      error
+}
+
+// Tracks the block the current player is breaking.
+type diggingState struct {
+	isDigging bool
+	target    Vector3Int
+	block     Block
+	face      protocol.Face
+	started   bool    // the server was told digging started
+	finished  bool    // waiting for the server to confirm the break
+	progress  float32 // 1 breaks the block
+	ticks     int     // since finishing
+	delay     int     // ticks left before digging can start
+}
+
+// Returns the position of the current player's eyes, which are at the
+// height of the stance.
+func (s *Simulator) EyePosition() Vector3Float {
+	player := &s.World.CurrentPlayer
+	p := player.Entity.Position
+	return Vector3Float{p.X, player.Stance, p.Z}
+}
+
+// Returns true while the current player is breaking a block.
+func (s *Simulator) IsDigging() bool {
+	return s.digging.isDigging
+}
+
+// Starts breaking the block at the given position with the best tool
+// in the player's inventory. Digging takes as long as it does for a
+// vanilla client, and finishes when the server changes the block.
+func (s *Simulator) Dig(v Vector3Int) error {
+	e := s.World.CurrentPlayer.Entity
+	if e == nil {
+		return fmt.Errorf("The player hasn't spawned")
+	}
+	block, ok := s.World.BlockAt(v.X, v.Y, v.Z)
+	if !ok {
+		return fmt.Errorf("Block at %v isn't loaded", v)
+	}
+	info, ok := registry.BlockByID(block.Type)
+	if !ok || block.Type == 0 || !info.IsBreakable() ||
+		isOneOf(block.Type, waterBlocks) || isOneOf(block.Type, lavaBlocks) {
+		return fmt.Errorf("Block at %v can't be broken", v)
+	}
+	eye := s.EyePosition()
+	if blockBox(v).DistanceTo(eye) > PlayerReach {
+		return fmt.Errorf("Block at %v is out of reach", v)
+	}
+	if s.digging.isDigging {
+		s.StopDigging()
+	}
+	s.StopEating()
+	if err := s.holdBestTool(block); err != nil {
+		return err
+	}
+	s.lookAt(blockCenter(v))
+	face := faceTowards(eye, v)
+	if hit, ok := s.TargetBlock(); ok {
+		if hit.Position != v {
+			return fmt.Errorf("Block at %v is in the way", hit.Position)
+		}
+		face = hit.Face
+	}
+	s.digging = diggingState{
+		isDigging: true,
+		target:    v,
+		block:     block,
+		face:      face,
+		delay:     s.digging.delay,
+	}
+	return nil
+}
+
+// Stops breaking the block, telling the server if it had started.
+func (s *Simulator) StopDigging() {
+	d := &s.digging
+	if d.started && !d.finished {
+		s.sendDigging(protocol.PlayerCancelledDigging)
+	}
+	*d = diggingState{delay: d.delay}
+}
+
+func (s *Simulator) tickDigging() {
+	d := &s.digging
+	if d.delay > 0 {
+		d.delay--
+		return
+	}
+	if !d.isDigging || s.World.CurrentPlayer.Entity == nil {
+		return
+	}
+	switch {
+	case d.finished:
+		if d.ticks++; d.ticks > digConfirmTicks {
+			s.failDigging(fmt.Errorf("The server didn't break the block"))
+		}
+		return
+	case !d.started:
+		d.started = true
+		s.sendDigging(protocol.PlayerStartedDigging)
+		// blocks that break instantly don't need finishing
+		if s.digProgress(d.block, s.heldItem()) >= 1 {
+			d.finished = true
+		}
+	default:
+		d.progress += s.digProgress(d.block, s.heldItem())
+		if d.progress >= 1 {
+			d.finished = true
+			s.sendDigging(protocol.PlayerFinishedDigging)
+		}
+	}
+	s.swingArm()
+}
+
+func (s *Simulator) failDigging(err error) {
+	v := s.digging.target
+	s.StopDigging()
+	s.emit(&DiggingFailedEvent{Position: v, Err: err})
+}
+
+// Finishes digging when the server changes the block being broken. The
+// server sends the old block back if it refuses to break it.
+func (s *Simulator) diggingBlockChanged(v Vector3Int, updated Block) {
+	d := &s.digging
+	if !d.isDigging || v != d.target {
+		return
+	}
+	switch {
+	case updated.Type != d.block.Type && d.finished:
+		broken := d.block
+		*d = diggingState{delay: digDelayTicks}
+		s.emit(&DiggingFinishedEvent{Position: v, Block: broken})
+	case updated.Type != d.block.Type:
+		s.failDigging(fmt.Errorf("Block at %v changed before it was broken", v))
+	case d.finished:
+		s.failDigging(fmt.Errorf("The server refused to break the block at %v", v))
+	}
+}
+
+func (s *Simulator) sendDigging(status protocol.PlayerDiggingStatus) {
+	d := &s.digging
+	s.send(&protocol.PlayerDigging{
+		Status: status,
+		X:      d.target.X,
+		Y:      int8(d.target.Y),
+		Z:      d.target.Z,
+		Face:   d.face,
+	})
+}
+
+func (s *Simulator) swingArm() {
+	s.send(&protocol.Animation{
+		EntityID: s.World.CurrentPlayer.Entity.ID,
+		Type:     protocol.AnimationSwingArm,
+	})
+}
+
+//////////////////////////////////////////////////////////
+
+// Returns the kind of item in the slot, or nil for an empty hand.
+func toolOf(item protocol.Slot) *registry.Item {
+	if item.IsEmpty() {
+		return nil
+	}
+	tool, _ := registry.ItemByID(item.ID)
+	return tool
+}
+
+// Returns how many times faster than a hand the tool breaks the block.
+func toolSpeed(tool *registry.Item, block *registry.Block) float32 {
+	switch {
+	case tool == nil:
+		return 1
+	case tool.Tool == registry.Shears:
+		switch block.Name {
+		case "web", "leaves":
+			return 15
+		case "wool":
+			return 5
+		}
+	case tool.Tool == registry.Sword:
+		switch block.Name {
+		case "web":
+			return 15
+		case "leaves", "vine", "pumpkin":
+			return 1.5
+		}
+	case tool.Tool != registry.NoTool && tool.Tool == block.Tool:
+		return tool.Material.Speed()
+	}
+	return 1
+}
+
+// Returns how much of the block the item breaks each tick, where 1
+// breaks it. It's computed like the vanilla client does.
+func (s *Simulator) digProgress(b Block, item protocol.Slot) float32 {
+	block, ok := registry.BlockByID(b.Type)
+	if !ok || !block.IsBreakable() {
+		return 0
+	}
+	player := &s.World.CurrentPlayer
+	if player.Entity == nil {
+		// not spawned yet
+		return 0
+	}
+	if s.World.GameMode.IsCreative() || block.Hardness == 0 {
+		return 1
+	}
+	tool := toolOf(item)
+	speed := toolSpeed(tool, block)
+	if level := Enchantments(item)[EnchantmentEfficiency]; level > 0 {
+		bonus := float32(level*level + 1)
+		// tools that aren't meant for the block get a small bonus
+		if speed <= 1 && !(block.Harvest != registry.NoMaterial && block.CanHarvestWith(tool)) {
+			bonus *= 0.08
+		}
+		speed += bonus
+	}
+	if haste, ok := player.Entity.Effect(EffectHaste); ok {
+		speed *= 1 + float32(haste.Amplifier+1)*0.2
+	}
+	if fatigue, ok := player.Entity.Effect(EffectMiningFatigue); ok {
+		speed *= 1 - float32(fatigue.Amplifier+1)*0.2
+	}
+	helmet := player.Inventory.SlotsIn(ArmorSlots)[0]
+	eye := s.EyePosition()
+	head, _ := s.World.BlockAt(int32(math.Floor(eye.X)), int32(math.Floor(eye.Y)), int32(math.Floor(eye.Z)))
+	if isOneOf(head.Type, waterBlocks) && Enchantments(player.Inventory.Slot(helmet))[EnchantmentAquaAffinity] == 0 {
+		speed /= 5
+	}
+	if !player.IsOnGround {
+		speed /= 5
+	}
+	if !block.CanHarvestWith(tool) {
+		return speed / block.Hardness / 100
+	}
+	return speed / block.Hardness / 30
+}
+
+// Returns the number of ticks it takes the item to break the block, or
+// -1 if it can't be broken or the player hasn't spawned.
+func (s *Simulator) BreakTicks(b Block, item protocol.Slot) int {
+	progress := s.digProgress(b, item)
+	if progress <= 0 {
+		return -1
+	}
+	if progress >= 1 {
+		return 0
+	}
+	ticks := 0
+	for done := float32(0); done < 1; done += progress {
+		ticks++
+	}
+	return ticks
+}
+
+// Returns the inventory slots whose items the player can switch to,
+// starting with the held item. Items in the main inventory have to be
+// swapped into the hotbar, so they're only included when no window is
+// open and the cursor is empty.
+func (s *Simulator) usableSlots() []int16 {
+	player := &s.World.CurrentPlayer
+	inventory := player.Inventory
+	slots := append([]int16{inventory.HotbarSlot(player.HeldItemSlot)}, inventory.SlotsIn(HotbarSlots)...)
+	if player.Window == nil && player.Cursor.IsEmpty() {
+		slots = append(slots, inventory.SlotsIn(MainInventorySlots)...)
+	}
+	return slots
+}
+
+// Switches to the item of the player's inventory that breaks the block
+// fastest, preferring items that make it drop something. The held item
+// is kept if nothing is better.
+func (s *Simulator) holdBestTool(b Block) error {
+	player := &s.World.CurrentPlayer
+	inventory := player.Inventory
+	block, _ := registry.BlockByID(b.Type)
+	held := inventory.HotbarSlot(player.HeldItemSlot)
+
+	best := held
+	bestHarvests := block.CanHarvestWith(toolOf(inventory.Slot(held)))
+	bestProgress := float32(math.Min(float64(s.digProgress(b, inventory.Slot(held))), 1))
+	for _, slot := range s.usableSlots() {
+		item := inventory.Slot(slot)
+		harvests := block.CanHarvestWith(toolOf(item))
+		progress := float32(math.Min(float64(s.digProgress(b, item)), 1))
+		if harvests && !bestHarvests || harvests == bestHarvests && progress > bestProgress {
+			best, bestHarvests, bestProgress = slot, harvests, progress
+		}
+	}
+
+	return s.holdSlot(best)
+}
+
+// Makes the item in the inventory's slot the held item, selecting it in
+// the hotbar or swapping it into the selected hotbar slot.
+func (s *Simulator) holdSlot(slot int16) error {
+	player := &s.World.CurrentPlayer
+	inventory := player.Inventory
+	switch {
+	case slot == inventory.HotbarSlot(player.HeldItemSlot):
+		return nil
+	case inventory.Region(slot) == HotbarSlots:
+		s.HoldHotbarSlot(slot - inventory.HotbarSlot(0))
+		return nil
+	}
+	return s.SwapWithHotbar(inventory, slot, int(player.HeldItemSlot))
+}
+
+// Selects the hotbar slot, numbered from 0 to 8, as the held item.
+func (s *Simulator) HoldHotbarSlot(i int16) {
+	s.World.CurrentPlayer.HeldItemSlot = i
+	s.send(&protocol.HeldItemChange{SlotID: i})
+}
+
+// Returns the box of the space the block fills.
+func blockBox(v Vector3Int) BoundingBox {
+	return NewBoundingBox(0, 0, 0, 1, 1, 1).Offset(float64(v.X), float64(v.Y), float64(v.Z))
+}
+
+func blockCenter(v Vector3Int) Vector3Float {
+	return Vector3Float{float64(v.X) + 0.5, float64(v.Y) + 0.5, float64(v.Z) + 0.5}
+}
+
+// Returns the face of the block that a line from the eyes to its center
+// goes through.
+func faceTowards(eye Vector3Float, v Vector3Int) protocol.Face {
+	c := blockCenter(v)
+	dx, dy, dz := eye.X-c.X, eye.Y-c.Y, eye.Z-c.Z
+	ax, ay, az := math.Abs(dx), math.Abs(dy), math.Abs(dz)
+	switch {
+	case ay >= ax && ay >= az && dy > 0:
+		return protocol.FaceYPos
+	case ay >= ax && ay >= az:
+		return protocol.FaceYNeg
+	case ax >= az && dx > 0:
+		return protocol.FaceXPos
+	case ax >= az:
+		return protocol.FaceXNeg
+	case dz > 0:
+		return protocol.FaceZPos
+	}
+	return protocol.FaceZNeg
+}
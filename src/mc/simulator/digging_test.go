@@ -0,0 +1,220 @@
+package simulator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	. "github.com/jeffh/goexpect"
+	"mc/protocol"
+	"testing"
+)
+
+var (
+	woodenPickaxe  = protocol.Slot{ID: 270, Count: 1}
+	diamondPickaxe = protocol.Slot{ID: 278, Count: 1}
+	stoneBlock     = Block{Type: 1}
+	dirtBlock      = Block{Type: 3}
+)
+
+// Returns the item with the enchantment, stored as NBT like the server
+// sends it.
+func enchanted(item protocol.Slot, id, level int16) protocol.Slot {
+	var raw bytes.Buffer
+	write := func(v ...interface{}) {
+		for _, x := range v {
+			binary.Write(&raw, binary.BigEndian, x)
+		}
+	}
+	name := func(s string) {
+		write(int16(len(s)), []byte(s))
+	}
+	write(byte(10)) // the root compound
+	name("tag")
+	write(byte(9)) // a list of compounds
+	name("ench")
+	write(byte(10), int32(1))
+	write(byte(2))
+	name("id")
+	write(id)
+	write(byte(2))
+	name("lvl")
+	write(level)
+	write(byte(0), byte(0))
+
+	var gzipped bytes.Buffer
+	w := gzip.NewWriter(&gzipped)
+	w.Write(raw.Bytes())
+	w.Close()
+	item.GzippedNBT = gzipped.Bytes()
+	return item
+}
+
+// Returns a simulator with a player standing on stone, holding the
+// items of the inventory.
+func createStandingSimulator(items map[int]protocol.Slot) (*Simulator, chan interface{}) {
+	s, outbox := createPhysicsSimulator(2.5, 16, 2.5)
+	s.ProcessMessage(&protocol.SetWindowItems{WindowID: 0, Slots: slots(45, items)})
+	tick(s, outbox, 2) // landing
+	return s, outbox
+}
+
+// Ticks the simulator, returning the digging packets sent on each tick.
+func tickDigging(s *Simulator, outbox chan interface{}, ticks int) [][]interface{} {
+	sent := make([][]interface{}, ticks)
+	for i := range sent {
+		s.Tick()
+		sent[i] = sentPackets(outbox, (*protocol.PlayerDigging)(nil), (*protocol.Animation)(nil))
+	}
+	return sent
+}
+
+func TestEnchantmentsAreReadFromItems(t *testing.T) {
+	it := NewIt(t)
+	it.Expects(Enchantments(diamondPickaxe), ToBeEmpty)
+	it.Expects(Enchantments(enchanted(diamondPickaxe, EnchantmentEfficiency, 3)), ToEqual, map[int16]int16{
+		EnchantmentEfficiency: 3,
+	})
+}
+
+func TestBreakTicksDependOnTheTool(t *testing.T) {
+	it := NewIt(t)
+	s, _ := createStandingSimulator(nil)
+	// progress adds up in floats, like in the vanilla client
+	it.Expects(s.BreakTicks(stoneBlock, protocol.EmptySlot), ToEqual, 151)
+	it.Expects(s.BreakTicks(stoneBlock, woodenPickaxe), ToEqual, 23)
+	it.Expects(s.BreakTicks(stoneBlock, diamondPickaxe), ToEqual, 6)
+	it.Expects(s.BreakTicks(stoneBlock, enchanted(diamondPickaxe, EnchantmentEfficiency, 5)), ToEqual, 2)
+	it.Expects(s.BreakTicks(dirtBlock, protocol.EmptySlot), ToEqual, 15)
+	it.Expects(s.BreakTicks(dirtBlock, diamondPickaxe), ToEqual, 15)
+	it.Expects(s.BreakTicks(dirtBlock, enchanted(diamondPickaxe, EnchantmentEfficiency, 5)), ToEqual, 5)
+	it.Expects(s.BreakTicks(Block{Type: 7}, diamondPickaxe), ToEqual, -1)
+	it.Expects(s.BreakTicks(Block{Type: 31}, protocol.EmptySlot), ToEqual, 0)
+
+	s.World.CurrentPlayer.IsOnGround = false
+	it.Expects(s.BreakTicks(stoneBlock, diamondPickaxe), ToEqual, 29)
+
+	s.World.GameMode = protocol.GameModeCreative
+	it.Expects(s.BreakTicks(stoneBlock, protocol.EmptySlot), ToEqual, 0)
+}
+
+func TestBreakTicksBeforeSpawning(t *testing.T) {
+	it := NewIt(t)
+	s := NewSimulator(nil)
+	it.Expects(s.BreakTicks(stoneBlock, diamondPickaxe), ToEqual, -1)
+}
+
+func TestBreakTicksAreSlowerUnderwater(t *testing.T) {
+	it := NewIt(t)
+	s, _ := createStandingSimulator(nil)
+	s.World.SetBlock(2, 17, 2, 9, 0)
+	it.Expects(s.BreakTicks(stoneBlock, diamondPickaxe), ToEqual, 29)
+
+	helmet := enchanted(protocol.Slot{ID: 310, Count: 1}, EnchantmentAquaAffinity, 1)
+	s.ProcessMessage(&protocol.SetSlot{WindowID: 0, Slot: 5, Data: helmet})
+	it.Expects(s.BreakTicks(stoneBlock, diamondPickaxe), ToEqual, 6)
+}
+
+func TestDigSwapsInTheBestTool(t *testing.T) {
+	it := NewIt(t)
+	s, outbox := createStandingSimulator(map[int]protocol.Slot{
+		36: stack(dirt, 5), 39: woodenPickaxe, 20: diamondPickaxe,
+	})
+	it.Expects(s.Dig(Vector3Int{3, 15, 2}), ToBeNil)
+	clicks := sentPackets(outbox, (*protocol.ClickWindow)(nil))
+	it.Expects(clicks, ToBeLengthOf, 1)
+	it.Expects(clicks[0].(*protocol.ClickWindow).Slot, ToEqual, int16(20))
+	it.Expects(clicks[0].(*protocol.ClickWindow).Mode, ToEqual, protocol.ClickModeNumberKey)
+	it.Expects(s.heldItem(), ToEqual, diamondPickaxe)
+
+	// any item breaks dirt as fast as a hand
+	s.StopDigging()
+	it.Expects(s.Dig(Vector3Int{2, 15, 3}), ToBeNil)
+	it.Expects(len(outbox), ToEqual, 0)
+}
+
+func TestDigSelectsToolsInTheHotbar(t *testing.T) {
+	it := NewIt(t)
+	s, outbox := createStandingSimulator(map[int]protocol.Slot{39: woodenPickaxe})
+	it.Expects(s.Dig(Vector3Int{3, 15, 2}), ToBeNil)
+	it.Expects(<-outbox, ToEqual, &protocol.HeldItemChange{SlotID: 3})
+	it.Expects(s.World.CurrentPlayer.HeldItemSlot, ToEqual, int16(3))
+}
+
+func TestDigWaitsForTheBlockToBreak(t *testing.T) {
+	it := NewIt(t)
+	s, outbox := createStandingSimulator(map[int]protocol.Slot{36: diamondPickaxe})
+	events := recordEvents(s, (*DiggingFinishedEvent)(nil), (*DiggingFailedEvent)(nil))
+	it.Expects(s.Dig(Vector3Int{3, 15, 2}), ToBeNil)
+	it.Expects(s.IsDigging(), ToBeTrue)
+
+	sent := tickDigging(s, outbox, 8)
+	it.Expects(sent[0], ToEqual, []interface{}{
+		&protocol.PlayerDigging{Status: protocol.PlayerStartedDigging, X: 3, Y: 15, Z: 2, Face: protocol.FaceYPos},
+		&protocol.Animation{EntityID: 7, Type: protocol.AnimationSwingArm},
+	})
+	for i := 1; i < 6; i++ {
+		it.Expects(sent[i], ToEqual, []interface{}{&protocol.Animation{EntityID: 7, Type: protocol.AnimationSwingArm}})
+	}
+	it.Expects(sent[6][0], ToEqual, &protocol.PlayerDigging{
+		Status: protocol.PlayerFinishedDigging, X: 3, Y: 15, Z: 2, Face: protocol.FaceYPos,
+	})
+	it.Expects(sent[7], ToBeEmpty)
+	it.Expects(*events, ToBeEmpty)
+
+	s.ProcessMessage(&protocol.BlockChange{X: 3, Y: 15, Z: 2, Type: 0})
+	it.Expects(*events, ToEqual, []interface{}{&DiggingFinishedEvent{
+		Position: Vector3Int{3, 15, 2},
+		Block:    Block{Type: 1, Metadata: 2, Skylight: 15, Biome: 4},
+	}})
+	it.Expects(s.IsDigging(), Not(ToBeTrue))
+}
+
+func TestDigFailsWhenTheServerRefuses(t *testing.T) {
+	it := NewIt(t)
+	s, outbox := createStandingSimulator(map[int]protocol.Slot{36: diamondPickaxe})
+	events := recordEvents(s, (*DiggingFinishedEvent)(nil), (*DiggingFailedEvent)(nil))
+	s.Dig(Vector3Int{3, 15, 2})
+	tickDigging(s, outbox, 7)
+	s.ProcessMessage(&protocol.BlockChange{X: 3, Y: 15, Z: 2, Type: 1, Metadata: 2})
+	it.Expects(*events, ToBeLengthOf, 1)
+	it.Expects((*events)[0].(*DiggingFailedEvent).Position, ToEqual, Vector3Int{3, 15, 2})
+	it.Expects(s.IsDigging(), Not(ToBeTrue))
+}
+
+func TestDigBreaksSomeBlocksInstantly(t *testing.T) {
+	it := NewIt(t)
+	s, outbox := createStandingSimulator(nil)
+	events := recordEvents(s, (*DiggingFinishedEvent)(nil), (*DiggingFailedEvent)(nil))
+	s.World.GameMode = protocol.GameModeCreative
+	s.Dig(Vector3Int{3, 15, 2})
+	sent := tickDigging(s, outbox, 2)
+	it.Expects(sent[0], ToBeLengthOf, 2)
+	it.Expects(sent[0][0].(*protocol.PlayerDigging).Status, ToEqual, protocol.PlayerStartedDigging)
+	it.Expects(sent[1], ToBeEmpty)
+
+	s.ProcessMessage(&protocol.BlockChange{X: 3, Y: 15, Z: 2, Type: 0})
+	it.Expects(*events, ToBeLengthOf, 1)
+}
+
+func TestDigCanBeCancelled(t *testing.T) {
+	it := NewIt(t)
+	s, outbox := createStandingSimulator(nil)
+	s.Dig(Vector3Int{3, 15, 2})
+	tickDigging(s, outbox, 3)
+	s.StopDigging()
+	it.Expects(<-outbox, ToEqual, &protocol.PlayerDigging{
+		Status: protocol.PlayerCancelledDigging, X: 3, Y: 15, Z: 2, Face: protocol.FaceYPos,
+	})
+	it.Expects(tickDigging(s, outbox, 1)[0], ToBeEmpty)
+}
+
+func TestDigChecksTheBlock(t *testing.T) {
+	it := NewIt(t)
+	s, _ := createStandingSimulator(nil)
+	it.Expects(s.Dig(Vector3Int{2, 16, 2}), Not(ToBeNil)) // air
+	it.Expects(s.Dig(Vector3Int{9, 15, 2}), Not(ToBeNil)) // out of reach
+	it.Expects(s.Dig(Vector3Int{20, 15, 2}), Not(ToBeNil))
+	s.World.SetBlock(3, 15, 2, 7, 0)
+	it.Expects(s.Dig(Vector3Int{3, 15, 2}), Not(ToBeNil))
+	it.Expects(s.IsDigging(), Not(ToBeTrue))
+}
@@ -0,0 +1,172 @@
+package simulator
+
+import (
+	"fmt"
+	"mc/protocol"
+	"mc/registry"
+)
+
+const (
+	// ticks it takes to eat, which the server counts
+	eatTicks = 32
+	// extra ticks to wait for the server to feed the player
+	eatConfirmTicks = 20
+)
+
+// Emitted when the server feeds the current player the food it ate.
+type AteEvent struct {
+	Item protocol.Slot
+	Food int16 // the player's food afterwards
+}
+
+// Emitted when eating was interrupted or the server didn't feed the
+// current player.
+type EatingFailedEvent struct {
+	Item protocol.Slot
+	Err  error
+}
+
+type eatingState struct {
+	isEating bool
+	item     protocol.Slot
+	ticks    int // since starting
+	delay    int // ticks before auto-eating tries again
+}
+
+// Returns true while the current player is eating.
+func (s *Simulator) IsEating() bool {
+	return s.eating.isEating
+}
+
+// Starts eating the best food in the current player's inventory, making
+// it the held item. The server feeds the player after a while, which
+// emits an AteEvent. Food that may hurt the player is only eaten below
+// HungerThreshold.
+func (s *Simulator) Eat() error {
+	player := &s.World.CurrentPlayer
+	switch {
+	case player.Entity == nil:
+		return fmt.Errorf("The player hasn't spawned")
+	case player.IsDead:
+		return fmt.Errorf("The player is dead")
+	case s.World.GameMode.IsCreative():
+		return fmt.Errorf("The player can't eat in creative mode")
+	case player.Food >= MaxFood:
+		return fmt.Errorf("The player isn't hungry")
+	}
+	slot, ok := s.bestFood()
+	if !ok {
+		return fmt.Errorf("No food in the inventory")
+	}
+	if s.digging.isDigging {
+		s.StopDigging()
+	}
+	if err := s.holdSlot(slot); err != nil {
+		return err
+	}
+	// right clicking with food, without a block
+	item := s.heldItem()
+	s.send(&protocol.PlayerBlockPlacement{
+		X:         -1,
+		Y:         255,
+		Z:         -1,
+		Direction: -1,
+		ItemHeld:  item,
+	})
+	s.eating = eatingState{isEating: true, item: item}
+	return nil
+}
+
+// Stops eating before the food is eaten.
+func (s *Simulator) StopEating() {
+	if !s.eating.isEating {
+		return
+	}
+	s.send(&protocol.PlayerDigging{Status: protocol.PlayerShootArrowOrFinishEating, Face: -1})
+	item := s.eating.item
+	s.stopEating()
+	s.emit(&EatingFailedEvent{Item: item, Err: fmt.Errorf("Stopped eating")})
+}
+
+func (s *Simulator) stopEating() {
+	s.eating = eatingState{delay: s.eating.delay}
+}
+
+// Returns the food an item is, or nil if it can't be eaten.
+func foodOf(item protocol.Slot) *registry.Food {
+	if info, ok := registry.ItemByID(item.ID); ok && !item.IsEmpty() {
+		return info.Food
+	}
+	return nil
+}
+
+// Returns the inventory slot of the food that feeds the player the most.
+// Golden apples are kept for emergencies.
+func (s *Simulator) bestFood() (int16, bool) {
+	player := &s.World.CurrentPlayer
+	inventory := player.Inventory
+	var best int16
+	var bestValue float32
+	for _, slot := range s.usableSlots() {
+		info, ok := registry.ItemByID(inventory.Slot(slot).ID)
+		if !ok || info.Food == nil || info.Name == "golden_apple" {
+			continue
+		}
+		food := info.Food
+		if food.IsHarmful && player.Food >= HungerThreshold {
+			continue
+		}
+		if value := float32(food.Hunger) + food.Saturation(); value > bestValue {
+			best, bestValue = slot, value
+		}
+	}
+	return best, bestValue > 0
+}
+
+// Eats when the best food wouldn't be wasted.
+func (s *Simulator) shouldAutoEat() bool {
+	player := &s.World.CurrentPlayer
+	if !s.AutoEat || s.eating.isEating || s.digging.isDigging ||
+		player.Entity == nil || player.IsDead || s.World.GameMode.IsCreative() {
+		return false
+	}
+	slot, ok := s.bestFood()
+	if !ok {
+		return false
+	}
+	food := foodOf(player.Inventory.Slot(slot))
+	return player.Food+int16(food.Hunger) <= MaxFood || player.Food < HungerThreshold
+}
+
+func (s *Simulator) tickEating() {
+	e := &s.eating
+	if e.delay > 0 {
+		e.delay--
+	}
+	if e.delay == 0 && s.shouldAutoEat() {
+		if err := s.Eat(); err != nil {
+			s.Logger.Printf("Can't eat: %s", err)
+			e.delay = eatConfirmTicks
+		}
+		return
+	}
+	if !e.isEating {
+		return
+	}
+	if e.ticks++; e.ticks > eatTicks+eatConfirmTicks {
+		item := e.item
+		s.stopEating()
+		e.delay = eatConfirmTicks
+		s.emit(&EatingFailedEvent{Item: item, Err: fmt.Errorf("The server didn't feed the player")})
+	}
+}
+
+// Finishes eating when the server raises the player's food.
+func (s *Simulator) ateFood() {
+	if !s.eating.isEating {
+		return
+	}
+	item := s.eating.item
+	s.stopEating()
+	s.emit(&AteEvent{Item: item, Food: s.World.CurrentPlayer.Food})
+}
@@ -0,0 +1,116 @@
+package simulator
+
+import (
+	. "github.com/jeffh/goexpect"
+	"mc/protocol"
+	"testing"
+)
+
+var (
+	apple       = protocol.Slot{ID: 260, Count: 3}
+	bread       = protocol.Slot{ID: 297, Count: 2}
+	rottenFlesh = protocol.Slot{ID: 367, Count: 8}
+	goldenApple = protocol.Slot{ID: 322, Count: 1}
+)
+
+func createHungrySimulator(food int16, items map[int]protocol.Slot) (*Simulator, chan interface{}, *[]interface{}) {
+	s, outbox := createStandingSimulator(items)
+	s.ProcessMessage(&protocol.UpdateHealth{Health: 20, Food: food, Saturation: 0})
+	return s, outbox, recordEvents(s, (*AteEvent)(nil), (*EatingFailedEvent)(nil), (*HungryEvent)(nil), (*LevelUpEvent)(nil))
+}
+
+func TestEatPicksTheBestFood(t *testing.T) {
+	it := NewIt(t)
+	s, outbox, events := createHungrySimulator(10, map[int]protocol.Slot{
+		36: apple, 37: rottenFlesh, 38: goldenApple, 20: bread,
+	})
+	it.Expects(s.Eat(), ToBeNil)
+	it.Expects(s.IsEating(), ToBeTrue)
+	it.Expects(s.heldItem(), ToEqual, bread)
+	it.Expects(sentPackets(outbox, (*protocol.PlayerBlockPlacement)(nil), (*protocol.PlayerDigging)(nil)), ToEqual, []interface{}{&protocol.PlayerBlockPlacement{
+		X: -1, Y: 255, Z: -1, Direction: -1, ItemHeld: bread,
+	}})
+
+	tickDigging(s, outbox, eatTicks)
+	s.ProcessMessage(&protocol.SetSlot{WindowID: 0, Slot: 36, Data: stack(bread, 1)})
+	s.ProcessMessage(&protocol.UpdateHealth{Health: 20, Food: 15, Saturation: 6})
+	it.Expects(s.IsEating(), Not(ToBeTrue))
+	it.Expects(*events, ToEqual, []interface{}{&AteEvent{Item: bread, Food: 15}})
+	it.Expects(s.World.CurrentPlayer.Saturation, ToEqual, float32(6))
+}
+
+func TestEatFailsWithoutTheServer(t *testing.T) {
+	it := NewIt(t)
+	s, outbox, events := createHungrySimulator(10, map[int]protocol.Slot{36: apple})
+	s.Eat()
+	tickDigging(s, outbox, eatTicks+eatConfirmTicks+1)
+	it.Expects(s.IsEating(), Not(ToBeTrue))
+	it.Expects(*events, ToBeLengthOf, 1)
+	it.Expects((*events)[0].(*EatingFailedEvent).Item, ToEqual, apple)
+
+	s.Eat()
+	sentPackets(outbox, (*protocol.PlayerBlockPlacement)(nil), (*protocol.PlayerDigging)(nil))
+	s.StopEating()
+	it.Expects(sentPackets(outbox, (*protocol.PlayerBlockPlacement)(nil), (*protocol.PlayerDigging)(nil)), ToEqual, []interface{}{&protocol.PlayerDigging{
+		Status: protocol.PlayerShootArrowOrFinishEating, Face: -1,
+	}})
+	it.Expects(*events, ToBeLengthOf, 2)
+}
+
+func TestEatChecksThePlayerCanEat(t *testing.T) {
+	it := NewIt(t)
+	s, outbox, _ := createHungrySimulator(20, map[int]protocol.Slot{36: apple})
+	it.Expects(s.Eat(), Not(ToBeNil)) // not hungry
+
+	s.ProcessMessage(&protocol.UpdateHealth{Health: 20, Food: 10})
+	s.ProcessMessage(&protocol.SetSlot{WindowID: 0, Slot: 36, Data: protocol.EmptySlot})
+	s.ProcessMessage(&protocol.SetSlot{WindowID: 0, Slot: 37, Data: rottenFlesh})
+	it.Expects(s.Eat(), Not(ToBeNil)) // only food that hurts
+	s.ProcessMessage(&protocol.UpdateHealth{Health: 20, Food: 5})
+	it.Expects(s.Eat(), ToBeNil)
+	it.Expects(sentPackets(outbox, (*protocol.PlayerBlockPlacement)(nil), (*protocol.PlayerDigging)(nil))[0].(*protocol.PlayerBlockPlacement).ItemHeld, ToEqual, rottenFlesh)
+}
+
+func TestAutoEatWaitsUntilFoodWouldntBeWasted(t *testing.T) {
+	it := NewIt(t)
+	s, outbox, events := createHungrySimulator(16, map[int]protocol.Slot{36: bread})
+	s.AutoEat = true
+	tickDigging(s, outbox, 10)
+	it.Expects(s.IsEating(), Not(ToBeTrue))
+
+	s.ProcessMessage(&protocol.UpdateHealth{Health: 20, Food: 15})
+	s.Tick()
+	it.Expects(s.IsEating(), ToBeTrue)
+	s.ProcessMessage(&protocol.UpdateHealth{Health: 20, Food: 20, Saturation: 6})
+	it.Expects(*events, ToBeLengthOf, 1)
+	s.Tick()
+	it.Expects(s.IsEating(), Not(ToBeTrue))
+}
+
+func TestAutoEatDoesntFightWhileEating(t *testing.T) {
+	it := NewIt(t)
+	s, outbox, _ := createHungrySimulator(4, map[int]protocol.Slot{36: bread, 37: diamondSword})
+	spawnMob(s, 20, protocol.MobZombie, 4.5, 2.5)
+	s.AutoEat = true
+	s.FightHostiles()
+	_, targets := tickCombat(s, outbox, eatTicks)
+	it.Expects(targets, ToBeEmpty)
+	it.Expects(s.heldItem(), ToEqual, bread)
+}
+
+func TestSimulatorTracksFoodAndExperience(t *testing.T) {
+	it := NewIt(t)
+	s, _, events := createHungrySimulator(8, nil)
+	s.ProcessMessage(&protocol.UpdateHealth{Health: 20, Food: 6, Saturation: 0})
+	it.Expects(*events, ToBeEmpty)
+	s.ProcessMessage(&protocol.UpdateHealth{Health: 20, Food: 5, Saturation: 0})
+	s.ProcessMessage(&protocol.UpdateHealth{Health: 20, Food: 4, Saturation: 0})
+	it.Expects(*events, ToEqual, []interface{}{&HungryEvent{Food: 5}})
+	it.Expects(s.World.CurrentPlayer.Food, ToEqual, int16(4))
+
+	*events = (*events)[:0]
+	s.ProcessMessage(&protocol.SetExperience{Percent: 0.5, Level: 0, Total: 8})
+	s.ProcessMessage(&protocol.SetExperience{Percent: 0.25, Level: 1, Total: 19})
+	it.Expects(s.World.CurrentPlayer.Experience, ToEqual, Experience{Level: 1, Total: 19, Progress: 0.25})
+	it.Expects(*events, ToEqual, []interface{}{&LevelUpEvent{Level: 1}})
+}
@@ -0,0 +1,102 @@
+package simulator
+
+import (
+	"mc/protocol"
+)
+
+// Ids of potion effects.
+const (
+	EffectSpeed int8 = iota + 1
+	EffectSlowness
+	EffectHaste
+	EffectMiningFatigue
+	EffectStrength
+	EffectInstantHealth
+	EffectInstantDamage
+	EffectJumpBoost
+	EffectNausea
+	EffectRegeneration
+	EffectResistance
+	EffectFireResistance
+	EffectWaterBreathing
+	EffectInvisibility
+	EffectBlindness
+	EffectNightVision
+	EffectHunger
+	EffectWeakness
+	EffectPoison
+	EffectWither
+	EffectHealthBoost
+	EffectAbsorption
+	EffectSaturation
+)
+
+// A potion effect on an entity.
+type Effect struct {
+	ID        int8
+	Amplifier int8 // the level of the effect, minus one
+	Duration  int  // ticks left
+}
+
+// Emitted when an entity gains or loses an effect, or an effect it has
+// is renewed.
+type EffectChangedEvent struct {
+	Entity  *Entity
+	Effect  Effect
+	Removed bool
+}
+
+// Emitted when the current player is poisoned or withered.
+type PoisonedEvent struct {
+	Effect Effect
+}
+
+// Returns the effect with the given id, if the entity has it.
+func (e *Entity) Effect(id int8) (Effect, bool) {
+	effect, ok := e.Effects[id]
+	return effect, ok
+}
+
+func (s *Simulator) handleEntityEffect(t *protocol.EntityEffect) {
+	e := s.World.EntityByID(t.EntityID)
+	if e == nil {
+		return
+	}
+	_, had := e.Effects[t.EffectID]
+	effect := Effect{ID: t.EffectID, Amplifier: t.Amplifier, Duration: int(t.Duration)}
+	e.Effects[t.EffectID] = effect
+	s.emit(&EffectChangedEvent{Entity: e, Effect: effect})
+	isPoison := effect.ID == EffectPoison || effect.ID == EffectWither
+	if isPoison && !had && e == s.World.CurrentPlayer.Entity {
+		s.emit(&PoisonedEvent{Effect: effect})
+	}
+}
+
+func (s *Simulator) handleRemoveEntityEffect(t *protocol.RemoveEntityEffect) {
+	if e := s.World.EntityByID(t.EntityID); e != nil {
+		s.removeEffect(e, t.EffectID)
+	}
+}
+
+func (s *Simulator) removeEffect(e *Entity, id int8) {
+	effect, ok := e.Effects[id]
+	if !ok {
+		return
+	}
+	delete(e.Effects, id)
+	s.emit(&EffectChangedEvent{Entity: e, Effect: effect, Removed: true})
+}
+
+// Counts down the effects of every entity, removing the ones that wore
+// off. The server also tells when they do.
+func (s *Simulator) tickEffects() {
+	for _, e := range s.World.Entities {
+		for id, effect := range e.Effects {
+			effect.Duration--
+			e.Effects[id] = effect
+			if effect.Duration <= 0 {
+				s.removeEffect(e, id)
+			}
+		}
+	}
+}
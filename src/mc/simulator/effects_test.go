@@ -0,0 +1,71 @@
+package simulator
+
+import (
+	. "github.com/jeffh/goexpect"
+	"mc/protocol"
+	"testing"
+)
+
+func TestSimulatorTracksEffectsOfEveryEntity(t *testing.T) {
+	it := NewIt(t)
+	s, _ := createStandingSimulator(nil)
+	events := recordEvents(s, (*EffectChangedEvent)(nil), (*PoisonedEvent)(nil))
+	zombie := spawnMob(s, 20, protocol.MobZombie, 4.5, 2.5)
+	s.ProcessMessage(&protocol.EntityEffect{EntityID: 20, EffectID: EffectSpeed, Amplifier: 1, Duration: 3})
+	s.ProcessMessage(&protocol.EntityEffect{EntityID: 20, EffectID: EffectFireResistance, Duration: 100})
+
+	speed := Effect{ID: EffectSpeed, Amplifier: 1, Duration: 3}
+	effect, ok := zombie.Effect(EffectSpeed)
+	it.Expects(ok, ToBeTrue)
+	it.Expects(effect, ToEqual, speed)
+	it.Expects(*events, ToBeLengthOf, 2)
+	it.Expects((*events)[0], ToEqual, &EffectChangedEvent{Entity: zombie, Effect: speed})
+
+	for i := 0; i < 3; i++ {
+		s.Tick()
+	}
+	_, ok = zombie.Effect(EffectSpeed)
+	it.Expects(ok, Not(ToBeTrue))
+	it.Expects(zombie.Effects[EffectFireResistance].Duration, ToEqual, 97)
+	it.Expects(*events, ToBeLengthOf, 3)
+	it.Expects((*events)[2], ToEqual, &EffectChangedEvent{Entity: zombie, Effect: Effect{ID: EffectSpeed, Amplifier: 1}, Removed: true})
+
+	s.ProcessMessage(&protocol.RemoveEntityEffect{EntityID: 20, EffectID: EffectFireResistance})
+	it.Expects(zombie.Effects, ToBeEmpty)
+	it.Expects(*events, ToBeLengthOf, 4)
+	s.ProcessMessage(&protocol.RemoveEntityEffect{EntityID: 20, EffectID: EffectFireResistance})
+	it.Expects(*events, ToBeLengthOf, 4)
+}
+
+func TestSimulatorEmitsPoisonedOnce(t *testing.T) {
+	it := NewIt(t)
+	s, _ := createStandingSimulator(nil)
+	poisoned := recordEvents(s, (*PoisonedEvent)(nil))
+	spawnMob(s, 20, protocol.MobSpider, 4.5, 2.5)
+	s.ProcessMessage(&protocol.EntityEffect{EntityID: 20, EffectID: EffectPoison, Duration: 100})
+	s.ProcessMessage(&protocol.EntityEffect{EntityID: 7, EffectID: EffectPoison, Duration: 100})
+	s.ProcessMessage(&protocol.EntityEffect{EntityID: 7, EffectID: EffectPoison, Duration: 200})
+
+	it.Expects(*poisoned, ToEqual, []interface{}{&PoisonedEvent{Effect: Effect{ID: EffectPoison, Duration: 100}}})
+	it.Expects(s.World.CurrentPlayer.Entity.Effects[EffectPoison].Duration, ToEqual, 200)
+}
+
+func TestRespawningClearsEffects(t *testing.T) {
+	it := NewIt(t)
+	s, _, _ := createSimulator()
+	s.ProcessMessage(&protocol.EntityEffect{EntityID: 7, EffectID: EffectWither, Duration: 100})
+	s.ProcessMessage(&protocol.UpdateHealth{Health: 0})
+	s.ProcessMessage(&protocol.Respawn{})
+	it.Expects(s.World.CurrentPlayer.Entity.Effects, ToBeEmpty)
+}
+
+func TestHasteAndFatigueChangeTheBreakTime(t *testing.T) {
+	it := NewIt(t)
+	s, _ := createStandingSimulator(nil)
+	it.Expects(s.BreakTicks(stoneBlock, woodenPickaxe), ToEqual, 23)
+	s.ProcessMessage(&protocol.EntityEffect{EntityID: 7, EffectID: EffectHaste, Amplifier: 1, Duration: 100})
+	it.Expects(s.BreakTicks(stoneBlock, woodenPickaxe), ToEqual, 17)
+	s.ProcessMessage(&protocol.RemoveEntityEffect{EntityID: 7, EffectID: EffectHaste})
+	s.ProcessMessage(&protocol.EntityEffect{EntityID: 7, EffectID: EffectMiningFatigue, Amplifier: 2, Duration: 100})
+	it.Expects(s.BreakTicks(stoneBlock, woodenPickaxe), ToEqual, 57)
+}
@@ -0,0 +1,48 @@
+package simulator
+
+import (
+	"mc/protocol"
+	"nbt"
+)
+
+// Ids of the enchantments the simulator takes into account.
+const (
+	EnchantmentAquaAffinity int16 = 6
+	EnchantmentSharpness    int16 = 16
+	EnchantmentEfficiency   int16 = 32
+)
+
+// Returns the levels of the item's enchantments by id. Items without
+// enchantments, or with NBT data that can't be read, have none.
+func Enchantments(item protocol.Slot) map[int16]int16 {
+	levels := make(map[int16]int16)
+	if len(item.GzippedNBT) == 0 {
+		return levels
+	}
+	r, err := item.NewReader()
+	if err != nil {
+		return levels
+	}
+	tag, err := nbt.NewFile(r).Read()
+	if err != nil {
+		return levels
+	}
+	root, ok := tag.Value.(nbt.Compound)
+	if !ok {
+		return levels
+	}
+	list, ok := root["ench"].Value.(nbt.List)
+	if !ok {
+		return levels
+	}
+	for _, v := range list.Values {
+		ench, ok := v.(nbt.Compound)
+		if !ok {
+			continue
+		}
+		id, _ := ench["id"].Value.(int16)
+		level, _ := ench["lvl"].Value.(int16)
+		levels[id] = level
+	}
+	return levels
+}
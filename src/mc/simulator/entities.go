@@ -0,0 +1,183 @@
+package simulator
+
+import (
+	"mc/geometry"
+	"mc/protocol"
+)
+
+// Converts the protocol's fixed-point (1/32 of a block) positions into
+// blocks.
+func fromFixedPoint(v int32) float64 {
+	return float64(v) / 32
+}
+
+// Converts the protocol's velocities (1/8000 of a block per tick) into
+// blocks per tick.
+func fromVelocity(v int16) float64 {
+	return float64(v) / 8000
+}
+
+func (e *Entity) setFixedPosition(x, y, z int32) {
+	e.Position.Set(fromFixedPoint(x), fromFixedPoint(y), fromFixedPoint(z))
+}
+
+func (e *Entity) setVelocity(x, y, z int16) {
+	e.Velocity.Set(fromVelocity(x), fromVelocity(y), fromVelocity(z))
+}
+
+func (e *Entity) move(dx, dy, dz int8) {
+	e.Position.X += fromFixedPoint(int32(dx))
+	e.Position.Y += fromFixedPoint(int32(dy))
+	e.Position.Z += fromFixedPoint(int32(dz))
+}
+
+func (e *Entity) look(yaw, pitch int8) {
+	e.Facing.Set(geometry.FromByteAngle(yaw), geometry.FromBytePitch(pitch))
+}
+
+func (e *Entity) updateMetadata(metadata []protocol.EntityMetadata) {
+	for _, m := range metadata {
+		e.Metadata[m.ID] = m.Value
+	}
+}
+
+//////////////////////////////////////////////////////////
+
+// Emitted when an entity comes into view.
+type EntitySpawnEvent struct {
+	Entity *Entity
+}
+
+// Emitted when an entity is removed from the world.
+type EntityDestroyEvent struct {
+	Entity *Entity
+}
+
+func (s *Simulator) spawn(id int32, kind EntityKind) *Entity {
+	e := s.World.NewEntityWithID(id)
+	e.Kind = kind
+	return e
+}
+
+// Returns the entity with the given id. Updates for unknown entities
+// are logged and ignored.
+func (s *Simulator) entity(id int32) *Entity {
+	e := s.World.EntityByID(id)
+	if e == nil {
+		s.Logger.Printf("Ignoring update for unknown entity: %d", id)
+	}
+	return e
+}
+
+func (s *Simulator) handleEntityPacket(v interface{}) {
+	switch t := v.(type) {
+	case *protocol.SpawnObject:
+		e := s.spawn(t.EntityID, ObjectEntity)
+		e.setFixedPosition(t.X, t.Y, t.Z)
+		if t.HasVelocity() {
+			e.setVelocity(t.XVelocity, t.YVelocity, t.ZVelocity)
+		}
+		e.look(t.Yaw, t.Pitch)
+		e.OwnerID = t.OwnerEntityID
+		e.Type = t.Type
+		s.emit(&EntitySpawnEvent{e})
+	case *protocol.SpawnMob:
+		e := s.spawn(t.EntityID, MobEntity)
+		e.MobType = t.Type
+		e.setFixedPosition(t.X, t.Y, t.Z)
+		e.setVelocity(t.XVelocity, t.YVelocity, t.ZVelocity)
+		e.look(t.Yaw, t.Pitch)
+		e.HeadYaw = geometry.FromByteAngle(t.HeadPitch)
+		e.updateMetadata(t.Metadata)
+		s.emit(&EntitySpawnEvent{e})
+	case *protocol.SpawnNamedEntity:
+		e := s.spawn(t.EntityID, PlayerEntity)
+		e.Name = t.PlayerName
+		e.setFixedPosition(t.X, t.Y, t.Z)
+		e.look(t.Yaw, t.Pitch)
+		e.HeadYaw = e.Facing.Yaw
+		e.Equipment[0] = protocol.EmptySlot
+		if t.CurrentItem != 0 {
+			e.Equipment[0] = protocol.Slot{ID: t.CurrentItem, Count: 1}
+		}
+		e.updateMetadata(t.Metadata)
+		s.emit(&EntitySpawnEvent{e})
+	case *protocol.SpawnDroppedItem:
+		e := s.spawn(t.EntityID, DroppedItemEntity)
+		e.Item = t.Slot
+		e.setFixedPosition(t.X, t.Y, t.Z)
+		e.look(t.Rotation, t.Pitch)
+		s.emit(&EntitySpawnEvent{e})
+	case *protocol.SpawnPainting:
+		e := s.spawn(t.EntityID, PaintingEntity)
+		e.Name = t.Title
+		// paintings are placed on blocks, so they don't use fixed-point
+		e.Position.Set(float64(t.X), float64(t.Y), float64(t.Z))
+		e.Direction = t.Direction
+		s.emit(&EntitySpawnEvent{e})
+	case *protocol.SpawnExperienceOrb:
+		e := s.spawn(t.EntityID, ExperienceOrbEntity)
+		e.setFixedPosition(t.X, t.Y, t.Z)
+		e.Experience = t.Count
+		s.emit(&EntitySpawnEvent{e})
+
+	case *protocol.EntityRelativeMove:
+		if e := s.entity(t.EntityID); e != nil {
+			e.move(t.DX, t.DY, t.DZ)
+		}
+	case *protocol.EntityLook:
+		if e := s.entity(t.EntityID); e != nil {
+			e.look(t.Yaw, t.Pitch)
+		}
+	case *protocol.EntityLookRelativeMove:
+		if e := s.entity(t.EntityID); e != nil {
+			e.move(t.DX, t.DY, t.DZ)
+			e.look(t.Yaw, t.Pitch)
+		}
+	case *protocol.EntityTeleport:
+		if e := s.entity(t.EntityID); e != nil {
+			e.setFixedPosition(t.X, t.Y, t.Z)
+			e.look(t.Yaw, t.Pitch)
+		}
+	case *protocol.EntityHeadLook:
+		if e := s.entity(t.EntityID); e != nil {
+			e.HeadYaw = geometry.FromByteAngle(int8(t.HeadYaw))
+		}
+	case *protocol.EntityVelocity:
+		if e := s.entity(t.EntityID); e != nil {
+			e.setVelocity(t.X, t.Y, t.Z)
+		}
+	case *protocol.AttachEntity:
+		if e := s.entity(t.EntityID); e != nil {
+			e.VehicleID = t.VehicleID
+		}
+	case *protocol.EntityEquipment:
+		if e := s.entity(t.EntityID); e != nil {
+			if t.Slot >= 0 && int(t.Slot) < len(e.Equipment) {
+				e.Equipment[t.Slot] = t.Item
+			}
+		}
+	case *protocol.SetEntityMetadata:
+		if e := s.entity(t.EntityID); e != nil {
+			e.updateMetadata(t.Metadata)
+		}
+	case *protocol.DestroyEntity:
+		for _, id := range t.EntityIDs {
+			s.destroy(id)
+		}
+	}
+}
+
+func (s *Simulator) destroy(id int32) {
+	e := s.World.EntityByID(id)
+	if e == nil || e == s.World.CurrentPlayer.Entity {
+		return
+	}
+	delete(s.World.Entities, id)
+	for _, other := range s.World.Entities {
+		if other.VehicleID == id {
+			other.VehicleID = NoVehicle
+		}
+	}
+	s.emit(&EntityDestroyEvent{e})
+}
@@ -0,0 +1,121 @@
+package simulator
+
+import (
+	. "github.com/jeffh/goexpect"
+	"mc/protocol"
+	"testing"
+)
+
+func TestSimulatorTracksMobs(t *testing.T) {
+	s, _, events := createSimulator()
+	s.ProcessMessage(&protocol.SpawnMob{
+		EntityID:  20,
+		Type:      protocol.MobZombie,
+		X:         32 * 10,
+		Y:         32*64 + 16,
+		Z:         -32 * 5,
+		Yaw:       64,
+		Pitch:     -64,
+		HeadPitch: -128,
+		XVelocity: 8000,
+		Metadata: []protocol.EntityMetadata{
+			{ID: protocol.EntityFlags, Type: protocol.EntityMetadataByte, Value: int8(1)},
+		},
+	})
+
+	zombie := s.World.EntityByID(20)
+	Expect(t, zombie.Kind, ToEqual, MobEntity)
+	Expect(t, zombie.MobType, ToEqual, protocol.MobType(protocol.MobZombie))
+	Expect(t, zombie.Position, ToEqual, Vector3Float{10, 64.5, -5})
+	Expect(t, zombie.Facing, ToEqual, RotationFloat{90, -90})
+	Expect(t, zombie.HeadYaw, ToEqual, float32(180))
+	Expect(t, zombie.Velocity, ToEqual, Vector3Float{1, 0, 0})
+	Expect(t, zombie.Metadata[protocol.EntityFlags], ToEqual, int8(1))
+	Expect(t, (*events)[0], ToEqual, &EntitySpawnEvent{zombie})
+
+	s.ProcessMessage(&protocol.EntityRelativeMove{EntityID: 20, DX: 16, DY: -32, DZ: 8})
+	Expect(t, zombie.Position, ToEqual, Vector3Float{10.5, 63.5, -4.75})
+
+	s.ProcessMessage(&protocol.EntityLookRelativeMove{EntityID: 20, DX: -16, Yaw: 0, Pitch: 32})
+	Expect(t, zombie.Position, ToEqual, Vector3Float{10, 63.5, -4.75})
+	Expect(t, zombie.Facing, ToEqual, RotationFloat{0, 45})
+
+	s.ProcessMessage(&protocol.EntityLook{EntityID: 20, Yaw: -64, Pitch: 0})
+	Expect(t, zombie.Facing, ToEqual, RotationFloat{270, 0})
+
+	s.ProcessMessage(&protocol.EntityHeadLook{EntityID: 20, HeadYaw: 32})
+	Expect(t, zombie.HeadYaw, ToEqual, float32(45))
+
+	s.ProcessMessage(&protocol.EntityTeleport{EntityID: 20, X: 32, Y: 64, Z: 96})
+	Expect(t, zombie.Position, ToEqual, Vector3Float{1, 2, 3})
+
+	s.ProcessMessage(&protocol.EntityVelocity{EntityID: 20, X: -4000, Y: 800, Z: 0})
+	Expect(t, zombie.Velocity, ToEqual, Vector3Float{-0.5, 0.1, 0})
+
+	s.ProcessMessage(&protocol.SetEntityMetadata{EntityID: 20, Metadata: []protocol.EntityMetadata{
+		{ID: protocol.EntityFlags, Type: protocol.EntityMetadataByte, Value: int8(2)},
+	}})
+	Expect(t, zombie.Metadata[protocol.EntityFlags], ToEqual, int8(2))
+
+	s.ProcessMessage(&protocol.EntityEquipment{EntityID: 20, Slot: 4, Item: protocol.Slot{ID: 298, Count: 1}})
+	Expect(t, zombie.Equipment[4], ToEqual, protocol.Slot{ID: 298, Count: 1})
+}
+
+func TestSimulatorTracksPlayersAndObjects(t *testing.T) {
+	s, _, _ := createSimulator()
+	s.ProcessMessage(&protocol.SpawnNamedEntity{
+		EntityID:    30,
+		PlayerName:  "Steve",
+		X:           64,
+		Y:           32,
+		Z:           0,
+		CurrentItem: 276,
+	})
+	steve := s.World.EntityByID(30)
+	Expect(t, steve.Kind, ToEqual, PlayerEntity)
+	Expect(t, steve.Name, ToEqual, "Steve")
+	Expect(t, steve.Position, ToEqual, Vector3Float{2, 1, 0})
+	Expect(t, steve.Equipment[0].ID, ToEqual, int16(276))
+
+	s.ProcessMessage(&protocol.SpawnNamedEntity{EntityID: 32, PlayerName: "Alex"})
+	Expect(t, s.World.EntityByID(32).Equipment[0], ToEqual, protocol.EmptySlot)
+
+	s.ProcessMessage(&protocol.SpawnObject{
+		EntityID: 31,
+		Type:     protocol.EntityMinecart,
+		X:        32,
+		Y:        32,
+		Z:        32,
+	})
+	s.ProcessMessage(&protocol.SpawnExperienceOrb{EntityID: 32, X: 16, Count: 7})
+	s.ProcessMessage(&protocol.SpawnPainting{EntityID: 33, Title: "Kebab", X: 1, Y: 2, Z: 3, Direction: 2})
+
+	Expect(t, s.World.EntityByID(31).Position, ToEqual, Vector3Float{1, 1, 1})
+	Expect(t, s.World.EntityByID(32).Experience, ToEqual, int16(7))
+	Expect(t, s.World.EntityByID(32).Position, ToEqual, Vector3Float{0.5, 0, 0})
+	Expect(t, s.World.EntityByID(33).Position, ToEqual, Vector3Float{1, 2, 3})
+	Expect(t, s.World.EntityByID(33).Name, ToEqual, "Kebab")
+
+	s.ProcessMessage(&protocol.AttachEntity{EntityID: 30, VehicleID: 31})
+	Expect(t, steve.VehicleID, ToEqual, int32(31))
+	s.ProcessMessage(&protocol.AttachEntity{EntityID: 30, VehicleID: -1})
+	Expect(t, steve.VehicleID, ToEqual, NoVehicle)
+}
+
+func TestSimulatorDestroysEntities(t *testing.T) {
+	s, _, events := createSimulator()
+	s.ProcessMessage(&protocol.SpawnNamedEntity{EntityID: 30, PlayerName: "Steve"})
+	s.ProcessMessage(&protocol.SpawnObject{EntityID: 31, Type: protocol.EntityBoat})
+	s.ProcessMessage(&protocol.AttachEntity{EntityID: 30, VehicleID: 31})
+	boat := s.World.EntityByID(31)
+
+	s.ProcessMessage(&protocol.DestroyEntity{EntityIDs: []int32{31, 7, 99}})
+	Expect(t, s.World.EntityByID(31), ToBeNil)
+	Expect(t, s.World.EntityByID(30).VehicleID, ToEqual, NoVehicle)
+	// the current player is never destroyed
+	Expect(t, s.World.EntityByID(7), ToBe, s.World.CurrentPlayer.Entity)
+	Expect(t, (*events)[2], ToEqual, &EntityDestroyEvent{boat})
+
+	// updates for unknown entities are ignored
+	s.ProcessMessage(&protocol.EntityRelativeMove{EntityID: 31, DX: 1})
+}
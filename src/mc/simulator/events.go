@@ -0,0 +1,53 @@
+package simulator
+
+import (
+	"mc/protocol"
+)
+
+// A function that receives the events emitted by the simulator.
+//
+// Events are pointers to the *Event structs of this package. Use a type
+// switch to pick the ones you're interested in:
+//
+//     sim.Listen(func(event interface{}) {
+//         switch e := event.(type) {
+//         case *simulator.DeathEvent:
+//             fmt.Printf("Died: %s\n", e.LastDamage.Cause)
+//         }
+//     })
+type Listener func(event interface{})
+
+// Adds a listener that receives every event emitted by the simulator.
+// Listeners are called synchronously from ProcessMessage.
+func (s *Simulator) Listen(l Listener) {
+	s.listeners = append(s.listeners, l)
+}
+
+func (s *Simulator) emit(event interface{}) {
+	for _, l := range s.listeners {
+		l(event)
+	}
+}
+
+// Queues a packet to be sent to the server.
+func (s *Simulator) send(packet interface{}) {
+	if s.Outbox == nil {
+		s.Logger.Printf("No outbox to send: %#v", packet)
+		return
+	}
+	s.Outbox <- packet
+}
+
+//////////////////////////////////////////////////////////
+
+// Emitted when the current player's health drops to zero.
+type DeathEvent struct {
+	LastDamage DamageInfo
+	Position   Vector3Float
+}
+
+// Emitted after the server respawns the current player.
+type RespawnEvent struct {
+	Dimension        protocol.GameDimension
+	ChangedDimension bool
+}
@@ -0,0 +1,169 @@
+package simulator
+
+import (
+	"encoding/json"
+	"mc/protocol"
+	"smpm"
+	"strings"
+)
+
+const (
+	MaxHealth = 20
+	MaxFood   = 20
+	// players can't sprint below this much food, so hunger starts to
+	// matter
+	HungerThreshold = 6
+)
+
+// The current player's experience.
+type Experience struct {
+	Level    int16
+	Total    int16
+	Progress float32 // towards the next level, from 0 to 1
+}
+
+// Emitted when the current player's food drops below HungerThreshold.
+type HungryEvent struct {
+	Food int16
+}
+
+// Emitted when the current player gains levels of experience.
+type LevelUpEvent struct {
+	Level int16
+}
+
+// Describes what last hurt the current player.
+type DamageInfo struct {
+	Amount   float32 // health lost by the last hit
+	Cause    string  // the death message key, such as "death.attack.mob"
+	Attacker string  // the name of the killer, if there is one
+	Message  string  // the raw death chat message
+}
+
+// The json structure of a translated chat message.
+type translatedChat struct {
+	Translate string        `json:"translate"`
+	Using     []interface{} `json:"using"`
+}
+
+func (s *Simulator) handleUpdateHealth(t *protocol.UpdateHealth) {
+	player := &s.World.CurrentPlayer
+	damage := player.Health - t.Health
+	if damage > 0 {
+		player.LastDamage.Amount = damage
+	}
+	player.Health = t.Health
+	if damage > 0 && !player.IsDead {
+		s.tookDamage(damage, t.Health)
+	}
+	fed := t.Food > player.Food || t.Saturation > player.Saturation
+	if t.Food < HungerThreshold && player.Food >= HungerThreshold && t.Health > 0 {
+		s.emit(&HungryEvent{Food: t.Food})
+	}
+	player.Food = t.Food
+	player.Saturation = t.Saturation
+	if fed {
+		s.ateFood()
+	}
+	if t.Health <= 0 {
+		s.die()
+	}
+}
+
+func (s *Simulator) handleSetExperience(t *protocol.SetExperience) {
+	player := &s.World.CurrentPlayer
+	leveledUp := t.Level > player.Experience.Level
+	player.Experience = Experience{Level: t.Level, Total: t.Total, Progress: t.Percent}
+	if leveledUp {
+		s.emit(&LevelUpEvent{Level: t.Level})
+	}
+}
+
+func (s *Simulator) handleEntityStatus(t *protocol.EntityStatus) {
+	player := &s.World.CurrentPlayer
+	if player.Entity == nil || t.EntityID != player.Entity.ID {
+		if e := s.World.EntityByID(t.EntityID); e != nil && t.Status == protocol.EntityStatusDead {
+			e.IsDead = true
+		}
+		return
+	}
+	switch t.Status {
+	case protocol.EntityStatusHurt:
+		s.recordAttacker()
+	case protocol.EntityStatusDead:
+		s.die()
+	}
+}
+
+// Parses death messages about the current player, which explain the
+// cause of death.
+func (s *Simulator) handleChatMessage(t *protocol.ChatMessage) {
+	var chat translatedChat
+	if json.Unmarshal([]byte(t.Message), &chat) != nil {
+		return
+	}
+	if !strings.HasPrefix(chat.Translate, "death.") || len(chat.Using) == 0 {
+		return
+	}
+	player := &s.World.CurrentPlayer
+	if name, _ := chat.Using[0].(string); name != player.Name {
+		return
+	}
+	player.LastDamage.Cause = chat.Translate
+	player.LastDamage.Message = t.Message
+	player.LastDamage.Attacker = ""
+	if len(chat.Using) > 1 {
+		player.LastDamage.Attacker, _ = chat.Using[1].(string)
+	}
+}
+
+func (s *Simulator) die() {
+	player := &s.World.CurrentPlayer
+	if player.IsDead {
+		return
+	}
+	player.IsDead = true
+	event := &DeathEvent{LastDamage: player.LastDamage}
+	if player.Entity != nil {
+		event.Position = player.Entity.Position
+	}
+	if player.LastDamage.Attacker != "" {
+		s.Logger.Printf("Died: %s by %s", player.LastDamage.Cause, player.LastDamage.Attacker)
+	} else {
+		s.Logger.Printf("Died: %s", player.LastDamage.Cause)
+	}
+	s.emit(event)
+	if s.AutoRespawn {
+		s.Respawn()
+	}
+}
+
+// Asks the server to respawn the current player.
+func (s *Simulator) Respawn() {
+	s.send(&protocol.ClientStatus{Payload: protocol.ClientStatusRespawn})
+}
+
+func (s *Simulator) handleRespawn(t *protocol.Respawn) {
+	changedDimension := s.World.GameDimension != t.Dimension
+	s.World.GameDimension = t.Dimension
+	s.World.GameDifficulty = t.Difficulty
+	s.World.GameMode = t.GameMode
+	s.World.LevelType = t.LevelType
+	s.World.CurrentPlayer.GameDifficulty = t.Difficulty
+	s.World.RemoveEntitiesExceptCurrentPlayer()
+	if changedDimension {
+		s.World.Columns = make(map[smpm.ColumnPoint]*smpm.ChunkColumn)
+	}
+
+	player := &s.World.CurrentPlayer
+	player.IsDead = false
+	player.LastDamage = DamageInfo{}
+	s.stopEating()
+	if player.Entity != nil {
+		player.Entity.Effects = make(map[int8]Effect)
+	}
+	s.emit(&RespawnEvent{
+		Dimension:        t.Dimension,
+		ChangedDimension: changedDimension,
+	})
+}
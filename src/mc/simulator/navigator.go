@@ -0,0 +1,276 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"mc/geometry"
+	"mc/protocol"
+)
+
+const (
+	// how close to the center of a position counts as reaching it
+	navigationTolerance = 0.3
+	// ticks without moving before the path is planned again
+	navigationStuckTicks = 40
+	// times the player may get stuck between positions before giving up
+	navigationMaxPlans = 4
+	// ticks between clicks on a door that hasn't opened
+	doorClickTicks = 10
+)
+
+// Emitted as the current player reaches each position of its path.
+type NavigationProgressEvent struct {
+	Goal      Vector3Int
+	Position  Vector3Int
+	Remaining int // positions left on the path
+}
+
+// Emitted when the current player reaches its goal.
+type NavigationFinishedEvent struct {
+	Goal Vector3Int
+}
+
+// Emitted when the goal can't be reached, even after planning again.
+type NavigationFailedEvent struct {
+	Goal Vector3Int
+	Err  error
+}
+
+// Walks the current player along a path by changing the simulator's
+// Controls every tick. The path is planned again when a block along it
+// changes or the player gets stuck.
+type Navigator struct {
+	Pathfinder   *Pathfinder
+	Goal         Vector3Int
+	Path         Path
+	IsNavigating bool
+
+	plans        int
+	stuckTicks   int
+	doorTicks    int
+	lastPosition Vector3Float
+}
+
+func NewNavigator(p *Pathfinder) *Navigator {
+	return &Navigator{Pathfinder: p}
+}
+
+// Returns the position of the current player's feet, as used by paths.
+func (s *Simulator) FeetPosition() Vector3Int {
+	p := s.World.CurrentPlayer.Entity.Position
+	// rounding up from slabs and other partial blocks
+	return Vector3Int{
+		int32(math.Floor(p.X)),
+		int32(math.Floor(p.Y + 0.5)),
+		int32(math.Floor(p.Z)),
+	}
+}
+
+// Starts walking the current player to the goal, which is the position
+// of its feet at the destination. Returns an error if there's no path.
+func (s *Simulator) NavigateTo(goal Vector3Int) error {
+	if s.World.CurrentPlayer.Entity == nil {
+		return fmt.Errorf("The player hasn't spawned")
+	}
+	n := s.Navigator
+	n.Goal = goal
+	n.plans = 0
+	if err := s.planPath(); err != nil {
+		n.IsNavigating = false
+		return err
+	}
+	n.IsNavigating = true
+	return nil
+}
+
+// Starts walking the current player to the highest place it can stand
+// at the given coordinates.
+func (s *Simulator) WalkTo(x, z int32) error {
+	y, ok := s.World.SurfaceAt(x, z)
+	if !ok {
+		return fmt.Errorf("Column at (%d, %d) isn't loaded", x, z)
+	}
+	return s.NavigateTo(Vector3Int{x, y, z})
+}
+
+// Stops navigating and releases the controls.
+func (s *Simulator) StopNavigating() {
+	s.Navigator.IsNavigating = false
+	s.Navigator.Path = nil
+	s.Controls = Controls{}
+}
+
+// Returns the height just above the highest block entities collide with
+// at the given coordinates, or false if the column isn't loaded.
+func (w *World) SurfaceAt(x, z int32) (int32, bool) {
+	if w.ColumnAt(x, z) == nil {
+		return 0, false
+	}
+	for y := int32(ChunkColumnHeight - 1); y >= 0; y-- {
+		block, _ := w.collisionBlock(x, y, z)
+		if len(block.BoundingBoxes()) > 0 {
+			return y + 1, true
+		}
+	}
+	return 0, true
+}
+
+func (s *Simulator) planPath() error {
+	n := s.Navigator
+	n.stuckTicks = 0
+	path, err := n.Pathfinder.FindPath(s.FeetPosition(), n.Goal)
+	if err != nil {
+		return err
+	}
+	n.Path = path
+	return nil
+}
+
+// Plans the path again, giving up if there's no path left or the player
+// keeps getting stuck.
+func (s *Simulator) replan(reason string, stuck bool) {
+	n := s.Navigator
+	s.Logger.Printf("Planning path to %v again: %s", n.Goal, reason)
+	err := ErrNoPath
+	if stuck {
+		n.plans++
+	}
+	if n.plans <= navigationMaxPlans {
+		err = s.planPath()
+	}
+	if err != nil {
+		goal := n.Goal
+		s.StopNavigating()
+		s.emit(&NavigationFailedEvent{Goal: goal, Err: err})
+	}
+}
+
+// Plans the path again if the changed block is on it.
+func (s *Simulator) navigationBlockChanged(v Vector3Int) {
+	n := s.Navigator
+	if !n.IsNavigating {
+		return
+	}
+	for _, p := range n.Path {
+		if p.X == v.X && p.Z == v.Z && v.Y >= p.Y-1 && v.Y <= p.Y+1 {
+			s.replan(fmt.Sprintf("block changed at %v", v), false)
+			return
+		}
+	}
+}
+
+func (s *Simulator) tickNavigation() {
+	n := s.Navigator
+	e := s.World.CurrentPlayer.Entity
+	if !n.IsNavigating || e == nil {
+		return
+	}
+
+	for len(n.Path) > 0 && s.hasReached(n.Path[0]) {
+		reached := n.Path[0]
+		n.Path = n.Path[1:]
+		n.plans = 0
+		s.emit(&NavigationProgressEvent{Goal: n.Goal, Position: reached, Remaining: len(n.Path)})
+	}
+	if len(n.Path) == 0 {
+		goal := n.Goal
+		s.StopNavigating()
+		s.emit(&NavigationFinishedEvent{Goal: goal})
+		return
+	}
+
+	moved := e.Position.X != n.lastPosition.X || e.Position.Y != n.lastPosition.Y || e.Position.Z != n.lastPosition.Z
+	n.lastPosition = e.Position
+	if moved {
+		n.stuckTicks = 0
+	} else if n.stuckTicks++; n.stuckTicks > navigationStuckTicks {
+		s.replan("stuck", true)
+		if !n.IsNavigating {
+			return
+		}
+	}
+
+	current, next := s.FeetPosition(), n.Path[0]
+	if s.openDoor(current, next) {
+		s.Controls = Controls{}
+		return
+	}
+	s.steer(current, next)
+}
+
+func (s *Simulator) hasReached(v Vector3Int) bool {
+	p := s.World.CurrentPlayer.Entity.Position
+	return math.Abs(p.X-(float64(v.X)+0.5)) < navigationTolerance &&
+		math.Abs(p.Z-(float64(v.Z)+0.5)) < navigationTolerance &&
+		s.FeetPosition().Y == v.Y
+}
+
+// Points the controls at the next position of the path.
+func (s *Simulator) steer(current, next Vector3Int) {
+	e := s.World.CurrentPlayer.Entity
+	dx := float64(next.X) + 0.5 - e.Position.X
+	dz := float64(next.Z) + 0.5 - e.Position.Z
+	swimming := s.Navigator.Pathfinder.is(current.X, current.Y, current.Z, waterBlocks)
+	ladder, _ := s.World.BlockAt(current.X, current.Y, current.Z)
+	climbing := isOneOf(ladder.Type, climbableBlocks) && next.Y > current.Y
+
+	c := Controls{Forward: 1}
+	if climbing && next.X == current.X && next.Z == current.Z {
+		// climbing needs pushing against the wall the ladder is on
+		dx, dz = ladderWall(ladder.Metadata)
+	} else if math.Abs(dx) < 0.1 && math.Abs(dz) < 0.1 {
+		c.Forward = 0
+	}
+	if c.Forward != 0 {
+		yaw, _ := geometry.LookAt(geometry.Vector{}, geometry.Vector{X: dx, Z: dz})
+		e.Facing.Set(yaw, 0)
+	}
+	c.Jump = (next.Y > current.Y && !climbing) || (swimming && next.Y >= current.Y)
+	s.Controls = c
+}
+
+// Returns the direction of the block a ladder is attached to.
+func ladderWall(metadata byte) (float64, float64) {
+	switch metadata {
+	case 2:
+		return 0, 1
+	case 3:
+		return 0, -1
+	case 4:
+		return 1, 0
+	}
+	return -1, 0
+}
+
+// Clicks on a closed door or gate in the way. Returns true while
+// waiting for it to open.
+func (s *Simulator) openDoor(current, next Vector3Int) bool {
+	n := s.Navigator
+	block, _ := s.World.collisionBlock(next.X, next.Y, next.Z)
+	if !isOneOf(block.Type, openableBlocks) || len(block.BoundingBoxes()) == 0 {
+		n.doorTicks = 0
+		return false
+	}
+	if n.doorTicks%doorClickTicks == 0 {
+		face := protocol.FaceYPos
+		switch {
+		case next.X > current.X:
+			face = protocol.FaceXNeg
+		case next.X < current.X:
+			face = protocol.FaceXPos
+		case next.Z > current.Z:
+			face = protocol.FaceZNeg
+		case next.Z < current.Z:
+			face = protocol.FaceZPos
+		}
+		s.clickBlock(next, face)
+	}
+	n.doorTicks++
+	return true
+}
+
+// Returns the item in the selected hotbar slot.
+func (s *Simulator) heldItem() protocol.Slot {
+	player := &s.World.CurrentPlayer
+	return player.Inventory.Slot(player.Inventory.HotbarSlot(player.HeldItemSlot))
+}
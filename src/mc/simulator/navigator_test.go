@@ -0,0 +1,121 @@
+package simulator
+
+import (
+	. "github.com/jeffh/goexpect"
+	"mc/protocol"
+	"testing"
+)
+
+// Ticks until the navigator stops, returning the packets sent.
+func tickNavigation(s *Simulator, outbox chan interface{}, maxTicks int) []interface{} {
+	packets := make([]interface{}, 0)
+	for i := 0; i < maxTicks && s.Navigator.IsNavigating; i++ {
+		s.Tick()
+		for len(outbox) > 0 {
+			packets = append(packets, <-outbox)
+		}
+	}
+	return packets
+}
+
+func TestNavigatorWalksToTheGoal(t *testing.T) {
+	s, outbox := createPhysicsSimulator(2.5, 16, 2.5)
+	events := recordEvents(s, (*NavigationProgressEvent)(nil), (*NavigationFinishedEvent)(nil), (*NavigationFailedEvent)(nil))
+	Expect(t, s.NavigateTo(Vector3Int{5, 16, 4}), ToBeNil)
+	Expect(t, s.Navigator.Path, ToBeLengthOf, 5)
+
+	tickNavigation(s, outbox, 200)
+	Expect(t, s.Navigator.IsNavigating, Not(ToBeTrue))
+	Expect(t, s.FeetPosition(), ToEqual, Vector3Int{5, 16, 4})
+	Expect(t, s.Controls, ToEqual, Controls{})
+	Expect(t, *events, ToBeLengthOf, 6)
+	Expect(t, (*events)[4], ToEqual, &NavigationProgressEvent{
+		Goal:      Vector3Int{5, 16, 4},
+		Position:  Vector3Int{5, 16, 4},
+		Remaining: 0,
+	})
+	Expect(t, (*events)[5], ToEqual, &NavigationFinishedEvent{Goal: Vector3Int{5, 16, 4}})
+}
+
+func TestNavigatorWalksToTheSurface(t *testing.T) {
+	s, outbox := createPhysicsSimulator(2.5, 16, 2.5)
+	buildWall(s.World, 5, 16, 17, 1)
+	Expect(t, s.WalkTo(5, 2), ToBeNil)
+	tickNavigation(s, outbox, 200)
+	Expect(t, s.FeetPosition(), ToEqual, Vector3Int{5, 17, 2})
+
+	Expect(t, s.WalkTo(100, 2), Not(ToBeNil))
+}
+
+func TestNavigatorReplansWhenBlocksChange(t *testing.T) {
+	s, outbox := createPhysicsSimulator(2.5, 16, 2.5)
+	events := recordEvents(s, (*NavigationProgressEvent)(nil), (*NavigationFinishedEvent)(nil), (*NavigationFailedEvent)(nil))
+	Expect(t, s.NavigateTo(Vector3Int{8, 16, 2}), ToBeNil)
+	tickNavigation(s, outbox, 5)
+
+	for z := int32(0); z < 8; z++ {
+		s.ProcessMessage(&protocol.BlockChange{X: 6, Y: 16, Z: z, Type: 1})
+		s.ProcessMessage(&protocol.BlockChange{X: 6, Y: 17, Z: z, Type: 1})
+	}
+	for _, p := range s.Navigator.Path {
+		Expect(t, p.X == 6 && p.Z < 8, Not(ToBeTrue))
+	}
+
+	tickNavigation(s, outbox, 400)
+	Expect(t, s.FeetPosition(), ToEqual, Vector3Int{8, 16, 2})
+	Expect(t, (*events)[len(*events)-1], ToEqual, &NavigationFinishedEvent{Goal: Vector3Int{8, 16, 2}})
+}
+
+func TestNavigatorFailsWhenTheGoalIsUnreachable(t *testing.T) {
+	s, outbox := createPhysicsSimulator(2.5, 16, 2.5)
+	events := recordEvents(s, (*NavigationProgressEvent)(nil), (*NavigationFinishedEvent)(nil), (*NavigationFailedEvent)(nil))
+	buildWall(s.World, 9, 16, 18, 1)
+	Expect(t, s.NavigateTo(Vector3Int{12, 16, 2}), ToBe, ErrNoPath)
+	Expect(t, s.Navigator.IsNavigating, Not(ToBeTrue))
+
+	Expect(t, s.NavigateTo(Vector3Int{6, 16, 2}), ToBeNil)
+	tickNavigation(s, outbox, 3)
+	buildWall(s.World, 5, 16, 18, 1)
+	s.ProcessMessage(&protocol.BlockChange{X: 5, Y: 16, Z: 2, Type: 1})
+	Expect(t, s.Navigator.IsNavigating, Not(ToBeTrue))
+	Expect(t, (*events)[len(*events)-1], ToEqual, &NavigationFailedEvent{Goal: Vector3Int{6, 16, 2}, Err: ErrNoPath})
+}
+
+func TestNavigatorOpensDoors(t *testing.T) {
+	s, outbox := createPhysicsSimulator(2.5, 16, 2.5)
+	buildWall(s.World, 4, 16, 18, 1)
+	s.World.SetBlock(4, 16, 2, 64, 0)
+	s.World.SetBlock(4, 17, 2, 64, 8)
+	Expect(t, s.NavigateTo(Vector3Int{6, 16, 2}), ToBeNil)
+
+	var click interface{}
+	for _, p := range tickNavigation(s, outbox, 20) {
+		if _, ok := p.(*protocol.PlayerBlockPlacement); ok {
+			click = p
+		}
+	}
+	Expect(t, click, ToEqual, &protocol.PlayerBlockPlacement{
+		X: 4, Y: 16, Z: 2,
+		Direction: int8(protocol.FaceXNeg),
+		ItemHeld:  protocol.EmptySlot,
+		CursorX:   0, CursorY: 8, CursorZ: 8,
+	})
+	Expect(t, s.FeetPosition(), ToEqual, Vector3Int{3, 16, 2})
+
+	// the server opens the door
+	s.ProcessMessage(&protocol.BlockChange{X: 4, Y: 16, Z: 2, Type: 64, Metadata: 4})
+	tickNavigation(s, outbox, 100)
+	Expect(t, s.FeetPosition(), ToEqual, Vector3Int{6, 16, 2})
+}
+
+func TestNavigatorClimbsLadders(t *testing.T) {
+	s, outbox := createPhysicsSimulator(1.5, 16, 2.5)
+	buildWall(s.World, 3, 16, 19, 1)
+	for y := int32(16); y < 19; y++ {
+		s.World.SetBlock(2, y, 2, 65, 4)
+	}
+	Expect(t, s.NavigateTo(Vector3Int{4, 16, 2}), ToBeNil)
+	tickNavigation(s, outbox, 400)
+	Expect(t, s.Navigator.IsNavigating, Not(ToBeTrue))
+	Expect(t, s.FeetPosition(), ToEqual, Vector3Int{4, 16, 2})
+}
@@ -0,0 +1,256 @@
+package simulator
+
+import (
+	"container/heap"
+	"errors"
+)
+
+const (
+	DefaultMaxFall      = 3     // in blocks
+	DefaultMaxPathNodes = 20000 // positions searched before giving up
+)
+
+var ErrNoPath = errors.New("No path to the goal")
+
+var (
+	openableBlocks = blockIDs("wooden_door", "fence_gate")
+	harmfulBlocks  = blockIDs("flowing_lava", "lava", "fire", "cactus", "web")
+	// hurt when touched from the side too
+	pricklyBlocks = blockIDs("cactus")
+)
+
+// The positions of the player's feet along a route, excluding where it
+// starts.
+type Path []Vector3Int
+
+// Finds walkable routes between positions in the world's loaded
+// columns. Routes may step up single blocks, drop down at most MaxFall
+// blocks, climb ladders, swim and pass through doors. They never touch
+// lava, fire, cactus or cobwebs, not even brushing past cactus.
+type Pathfinder struct {
+	World    *World
+	MaxFall  int32
+	MaxNodes int
+}
+
+func NewPathfinder(w *World) *Pathfinder {
+	return &Pathfinder{
+		World:    w,
+		MaxFall:  DefaultMaxFall,
+		MaxNodes: DefaultMaxPathNodes,
+	}
+}
+
+// Returns the shortest path from one position of the player's feet to
+// another, or ErrNoPath.
+func (p *Pathfinder) FindPath(from, to Vector3Int) (Path, error) {
+	if !p.CanStand(to) {
+		return nil, ErrNoPath
+	}
+	start := &pathNode{position: from, estimate: distance(from, to)}
+	nodes := map[Vector3Int]*pathNode{from: start}
+	open := &pathQueue{start}
+
+	for searched := 0; open.Len() > 0 && searched < p.MaxNodes; searched++ {
+		current := heap.Pop(open).(*pathNode)
+		if current.position == to {
+			return current.path(), nil
+		}
+		current.closed = true
+
+		for _, n := range p.neighbors(current.position) {
+			cost := current.cost + n.cost
+			node, seen := nodes[n.position]
+			if seen && (node.closed || cost >= node.cost) {
+				continue
+			}
+			if !seen {
+				node = &pathNode{position: n.position}
+				nodes[n.position] = node
+			}
+			node.parent = current
+			node.cost = cost
+			node.estimate = cost + distance(n.position, to)
+			if seen {
+				heap.Fix(open, node.index)
+			} else {
+				heap.Push(open, node)
+			}
+		}
+	}
+	return nil, ErrNoPath
+}
+
+// Returns true if the player can stand with its feet at the position:
+// its body fits and it is supported by a block, a ladder or water.
+func (p *Pathfinder) CanStand(v Vector3Int) bool {
+	if !p.passable(v.X, v.Y, v.Z) || !p.passable(v.X, v.Y+1, v.Z) || p.touchesPrickly(v) {
+		return false
+	}
+	if p.is(v.X, v.Y, v.Z, climbableBlocks) || p.is(v.X, v.Y, v.Z, waterBlocks) {
+		return true
+	}
+	below, ok := p.World.collisionBlock(v.X, v.Y-1, v.Z)
+	if !ok || isOneOf(below.Type, harmfulBlocks) {
+		return false
+	}
+	// blocks taller than a block, like fences, can't be stood on
+	boxes := below.BoundingBoxes()
+	for _, b := range boxes {
+		if b.Max.Y > 1 {
+			return false
+		}
+	}
+	return len(boxes) > 0
+}
+
+// Returns true if the player's body can pass through the block. Doors
+// and gates are passable, since they can be opened.
+func (p *Pathfinder) passable(x, y, z int32) bool {
+	block, ok := p.World.collisionBlock(x, y, z)
+	if !ok || isOneOf(block.Type, harmfulBlocks) {
+		return false
+	}
+	return len(block.BoundingBoxes()) == 0 || isOneOf(block.Type, openableBlocks)
+}
+
+// Returns true if the player's body at the position is next to a block
+// that hurts when touched, like cactus.
+func (p *Pathfinder) touchesPrickly(v Vector3Int) bool {
+	for _, d := range horizontalDirections {
+		if p.is(v.X+d.X, v.Y, v.Z+d.Z, pricklyBlocks) || p.is(v.X+d.X, v.Y+1, v.Z+d.Z, pricklyBlocks) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Pathfinder) is(x, y, z int32, types []int16) bool {
+	block, ok := p.World.BlockAt(x, y, z)
+	return ok && isOneOf(block.Type, types)
+}
+
+type pathStep struct {
+	position Vector3Int
+	cost     int
+}
+
+var horizontalDirections = []Vector3Int{{1, 0, 0}, {-1, 0, 0}, {0, 0, 1}, {0, 0, -1}}
+
+// Returns the positions reachable in a single move from where the
+// player stands.
+func (p *Pathfinder) neighbors(v Vector3Int) []pathStep {
+	steps := make([]pathStep, 0, 6)
+	swimming := p.is(v.X, v.Y, v.Z, waterBlocks)
+	climbing := p.is(v.X, v.Y, v.Z, climbableBlocks)
+
+	for _, d := range horizontalDirections {
+		next := Vector3Int{v.X + d.X, v.Y, v.Z + d.Z}
+		switch {
+		case p.CanStand(next):
+			steps = append(steps, pathStep{next, p.cost(next)})
+		case p.passable(next.X, next.Y, next.Z) && p.passable(next.X, next.Y+1, next.Z):
+			if p.touchesPrickly(next) {
+				break
+			}
+			// walking off an edge
+			for fall := int32(1); fall <= p.MaxFall; fall++ {
+				below := Vector3Int{next.X, next.Y - fall, next.Z}
+				if !p.passable(below.X, below.Y, below.Z) || p.touchesPrickly(below) {
+					break
+				}
+				if p.CanStand(below) {
+					steps = append(steps, pathStep{below, p.cost(below) + int(fall)})
+					break
+				}
+			}
+		default:
+			// jumping onto the next block needs room above the head
+			up := Vector3Int{next.X, next.Y + 1, next.Z}
+			if p.passable(v.X, v.Y+2, v.Z) && p.CanStand(up) {
+				steps = append(steps, pathStep{up, p.cost(up) + 1})
+			}
+		}
+	}
+
+	up := Vector3Int{v.X, v.Y + 1, v.Z}
+	if (swimming || climbing) && p.CanStand(up) {
+		steps = append(steps, pathStep{up, p.cost(up)})
+	}
+	down := Vector3Int{v.X, v.Y - 1, v.Z}
+	if (p.is(down.X, down.Y, down.Z, waterBlocks) || p.is(down.X, down.Y, down.Z, climbableBlocks)) && p.CanStand(down) {
+		steps = append(steps, pathStep{down, p.cost(down)})
+	}
+	return steps
+}
+
+// Returns the cost of moving into the position. Swimming and opening
+// doors are slower than walking.
+func (p *Pathfinder) cost(v Vector3Int) int {
+	cost := 1
+	if p.is(v.X, v.Y, v.Z, waterBlocks) {
+		cost++
+	}
+	if p.is(v.X, v.Y, v.Z, openableBlocks) {
+		cost++
+	}
+	return cost
+}
+
+func distance(a, b Vector3Int) int {
+	return int(abs(a.X-b.X) + abs(a.Y-b.Y) + abs(a.Z-b.Z))
+}
+
+func abs(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+//////////////////////////////////////////////////////////
+
+type pathNode struct {
+	position Vector3Int
+	parent   *pathNode
+	cost     int // from the start
+	estimate int // of the total cost through this node
+	closed   bool
+	index    int // in the pathQueue
+}
+
+func (n *pathNode) path() Path {
+	path := make(Path, 0)
+	for ; n.parent != nil; n = n.parent {
+		path = append(path, n.position)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// A priority queue of the nodes with the lowest estimate.
+type pathQueue []*pathNode
+
+func (q pathQueue) Len() int           { return len(q) }
+func (q pathQueue) Less(i, j int) bool { return q[i].estimate < q[j].estimate }
+
+func (q pathQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *pathQueue) Push(x interface{}) {
+	n := x.(*pathNode)
+	n.index = len(*q)
+	*q = append(*q, n)
+}
+
+func (q *pathQueue) Pop() interface{} {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
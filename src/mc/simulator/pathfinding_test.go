@@ -0,0 +1,125 @@
+package simulator
+
+import (
+	. "github.com/jeffh/goexpect"
+	"testing"
+)
+
+// Creates a pathfinder over a column whose bottom 16 blocks are stone.
+func createPathfinder() *Pathfinder {
+	s, _, _ := createSimulator()
+	s.ProcessMessage(chunkData(0, 0, 1))
+	return NewPathfinder(s.World)
+}
+
+// Fills blocks along the z axis at the given x, from y up to (but not
+// including) top.
+func buildWall(w *World, x, y, top int32, blockType int16) {
+	for z := int32(0); z < 16; z++ {
+		for h := y; h < top; h++ {
+			w.SetBlock(x, h, z, blockType, 0)
+		}
+	}
+}
+
+func TestPathfinderWalksOnFlatGround(t *testing.T) {
+	p := createPathfinder()
+	path, err := p.FindPath(Vector3Int{2, 16, 2}, Vector3Int{5, 16, 2})
+	Expect(t, err, ToBeNil)
+	Expect(t, path, ToEqual, Path{{3, 16, 2}, {4, 16, 2}, {5, 16, 2}})
+
+	path, err = p.FindPath(Vector3Int{2, 16, 2}, Vector3Int{2, 16, 2})
+	Expect(t, err, ToBeNil)
+	Expect(t, path, ToBeEmpty)
+}
+
+func TestPathfinderStepsUpSingleBlocks(t *testing.T) {
+	p := createPathfinder()
+	buildWall(p.World, 3, 16, 17, 1)
+	path, err := p.FindPath(Vector3Int{2, 16, 2}, Vector3Int{4, 16, 2})
+	Expect(t, err, ToBeNil)
+	Expect(t, path, ToEqual, Path{{3, 17, 2}, {4, 16, 2}})
+
+	// two blocks are too tall
+	buildWall(p.World, 3, 17, 18, 1)
+	_, err = p.FindPath(Vector3Int{2, 16, 2}, Vector3Int{4, 16, 2})
+	Expect(t, err, ToBe, ErrNoPath)
+}
+
+func TestPathfinderLimitsDrops(t *testing.T) {
+	p := createPathfinder()
+	for y := int32(16); y < 20; y++ {
+		p.World.SetBlock(2, y, 2, 1, 0)
+	}
+	_, err := p.FindPath(Vector3Int{2, 20, 2}, Vector3Int{2, 16, 5})
+	Expect(t, err, ToBe, ErrNoPath)
+
+	p.MaxFall = 4
+	path, err := p.FindPath(Vector3Int{2, 20, 2}, Vector3Int{2, 16, 5})
+	Expect(t, err, ToBeNil)
+	Expect(t, path[0], ToEqual, Vector3Int{2, 16, 3})
+}
+
+func TestPathfinderAvoidsLava(t *testing.T) {
+	p := createPathfinder()
+	buildWall(p.World, 3, 15, 16, 11)
+	_, err := p.FindPath(Vector3Int{2, 16, 2}, Vector3Int{4, 16, 2})
+	Expect(t, err, ToBe, ErrNoPath)
+
+	p.World.SetBlock(3, 15, 9, 1, 0)
+	path, err := p.FindPath(Vector3Int{2, 16, 2}, Vector3Int{4, 16, 2})
+	Expect(t, err, ToBeNil)
+	Expect(t, path, ToBeLengthOf, 16)
+}
+
+func TestPathfinderKeepsAwayFromCactus(t *testing.T) {
+	p := createPathfinder()
+	p.World.SetBlock(4, 16, 6, 81, 0)
+	Expect(t, p.CanStand(Vector3Int{4, 16, 5}), Not(ToBeTrue))
+	Expect(t, p.CanStand(Vector3Int{4, 16, 4}), ToBeTrue)
+
+	path, err := p.FindPath(Vector3Int{2, 16, 5}, Vector3Int{6, 16, 5})
+	Expect(t, err, ToBeNil)
+	Expect(t, path, ToBeLengthOf, 6)
+	for _, v := range path {
+		Expect(t, v, Not(ToEqual), Vector3Int{4, 16, 5})
+	}
+}
+
+func TestPathfinderClimbsLadders(t *testing.T) {
+	p := createPathfinder()
+	buildWall(p.World, 3, 16, 19, 1)
+	for y := int32(16); y < 19; y++ {
+		p.World.SetBlock(2, y, 2, 65, 4)
+	}
+	path, err := p.FindPath(Vector3Int{1, 16, 2}, Vector3Int{4, 16, 2})
+	Expect(t, err, ToBeNil)
+	Expect(t, path, ToEqual, Path{{2, 16, 2}, {2, 17, 2}, {2, 18, 2}, {3, 19, 2}, {4, 16, 2}})
+}
+
+func TestPathfinderPassesThroughDoors(t *testing.T) {
+	p := createPathfinder()
+	buildWall(p.World, 3, 16, 18, 1)
+	p.World.SetBlock(3, 16, 2, 64, 0)
+	p.World.SetBlock(3, 17, 2, 64, 8)
+	path, err := p.FindPath(Vector3Int{2, 16, 2}, Vector3Int{4, 16, 2})
+	Expect(t, err, ToBeNil)
+	Expect(t, path, ToEqual, Path{{3, 16, 2}, {4, 16, 2}})
+
+	p.World.SetBlock(3, 16, 2, 71, 0)
+	p.World.SetBlock(3, 17, 2, 71, 8)
+	_, err = p.FindPath(Vector3Int{2, 16, 2}, Vector3Int{4, 16, 2})
+	Expect(t, err, ToBe, ErrNoPath)
+}
+
+func TestPathfinderSwims(t *testing.T) {
+	p := createPathfinder()
+	for y := int32(10); y < 16; y++ {
+		for z := int32(0); z < 16; z++ {
+			p.World.SetBlock(3, y, z, 9, 0)
+		}
+	}
+	path, err := p.FindPath(Vector3Int{2, 16, 2}, Vector3Int{3, 10, 2})
+	Expect(t, err, ToBeNil)
+	Expect(t, path[len(path)-1], ToEqual, Vector3Int{3, 10, 2})
+}
@@ -0,0 +1,380 @@
+package simulator
+
+import (
+	"math"
+	"mc/geometry"
+	"mc/protocol"
+	"mc/registry"
+)
+
+// Vanilla movement constants, in blocks and ticks.
+const (
+	PlayerWidth         = 0.6
+	PlayerHeight        = 1.8
+	PlayerEyeHeight     = 1.62 // the stance is this far above the feet
+	PlayerStepHeight    = 0.5
+	DefaultWalkingSpeed = 0.1
+
+	gravity             = 0.08
+	verticalDrag        = 0.98
+	airFriction         = 0.91
+	defaultSlipperiness = 0.6
+	iceSlipperiness     = 0.98
+	airAcceleration     = 0.02
+	sprintMultiplier    = 1.3
+	sneakMultiplier     = 0.3
+	jumpVelocity        = 0.42
+	sprintJumpBoost     = 0.2
+	liquidAcceleration  = 0.02
+	liquidGravity       = 0.02
+	waterDrag           = 0.8
+	lavaDrag            = 0.5
+	ladderSpeed         = 0.15
+	ladderClimbSpeed    = 0.2
+
+	// positions are resent this often even if the player hasn't moved
+	positionResendTicks = 20
+)
+
+// What the current player is trying to do, like a person holding down
+// keys. Physics applies them every tick.
+type Controls struct {
+	Forward float64 // -1 (backward) to 1 (forward)
+	Strafe  float64 // -1 (right) to 1 (left)
+	Jump    bool
+	Sprint  bool
+	Sneak   bool
+}
+
+func blockIDs(names ...string) []int16 {
+	ids := make([]int16, len(names))
+	for i, name := range names {
+		b, _ := registry.BlockByName(name)
+		ids[i] = b.ID
+	}
+	return ids
+}
+
+func isOneOf(t int16, types []int16) bool {
+	for _, other := range types {
+		if t == other {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	waterBlocks     = blockIDs("flowing_water", "water")
+	lavaBlocks      = blockIDs("flowing_lava", "lava")
+	climbableBlocks = blockIDs("ladder", "vine")
+	iceBlocks       = blockIDs("ice")
+	doorBlocks      = blockIDs("wooden_door", "iron_door")
+)
+
+// Returns the box of a player standing at the given position.
+func PlayerBoundingBox(p Vector3Float) BoundingBox {
+	const r = PlayerWidth / 2
+	return NewBoundingBox(p.X-r, p.Y, p.Z-r, p.X+r, p.Y+PlayerHeight, p.Z+r)
+}
+
+// Tracks what was last told to the server about the current player.
+type physicsState struct {
+	hasPosition    bool // the server has placed the player
+	lastPosition   Vector3Float
+	lastFacing     RotationFloat
+	ticksSinceSent int
+}
+
+// Advances the current player by one tick, then tells the server where
+// it is. Nothing moves until the server has sent the player's position,
+// or while the column the player is in isn't loaded.
+func (s *Simulator) tickPhysics() {
+	player := &s.World.CurrentPlayer
+	e := player.Entity
+	if e == nil || !s.physics.hasPosition || player.IsDead {
+		return
+	}
+	x, z := int32(math.Floor(e.Position.X)), int32(math.Floor(e.Position.Z))
+	if s.World.ColumnAt(x, z) != nil {
+		s.move()
+	}
+	player.Stance = e.Position.Y + PlayerEyeHeight
+	s.sendPosition()
+}
+
+func (s *Simulator) move() {
+	player := &s.World.CurrentPlayer
+	e := player.Entity
+	v := &e.Velocity
+	box := PlayerBoundingBox(e.Position)
+	inWater := s.World.containsBlock(box.Contract(0.001, 0.4, 0.001), waterBlocks...)
+	inLava := s.World.containsBlock(box.Contract(0.1, 0.4, 0.1), lavaBlocks...)
+	onLadder := s.World.containsBlock(NewBoundingBox(e.Position.X, e.Position.Y, e.Position.Z, e.Position.X, e.Position.Y, e.Position.Z), climbableBlocks...)
+
+	forward, strafe := s.Controls.Forward*0.98, s.Controls.Strafe*0.98
+	if s.Controls.Sneak {
+		forward *= sneakMultiplier
+		strafe *= sneakMultiplier
+	}
+	sprinting := s.Controls.Sprint && forward > 0 && !s.Controls.Sneak
+
+	if s.Controls.Jump {
+		if inWater || inLava {
+			v.Y += 0.04
+		} else if player.IsOnGround {
+			v.Y = jumpVelocity
+			if sprinting {
+				yaw := geometry.DegreesToRadians(float64(e.Facing.Yaw))
+				v.X -= math.Sin(yaw) * sprintJumpBoost
+				v.Z += math.Cos(yaw) * sprintJumpBoost
+			}
+		}
+	}
+
+	switch {
+	case inWater || inLava:
+		s.accelerate(strafe, forward, liquidAcceleration)
+		collidedHorizontally := s.collide(v.X, v.Y, v.Z)
+		drag := waterDrag
+		if !inWater {
+			drag = lavaDrag
+		}
+		v.X *= drag
+		v.Y = v.Y*drag - liquidGravity
+		v.Z *= drag
+		// swimming against a wall climbs out of the liquid
+		if collidedHorizontally {
+			v.Y = 0.3
+		}
+	default:
+		friction := airFriction
+		acceleration := airAcceleration
+		if sprinting {
+			acceleration *= sprintMultiplier
+		}
+		if player.IsOnGround {
+			friction = s.slipperinessBelow() * airFriction
+			speed := float64(player.WalkingSpeed)
+			if speed == 0 {
+				speed = DefaultWalkingSpeed
+			}
+			if sprinting {
+				speed *= sprintMultiplier
+			}
+			acceleration = speed * 0.16277136 / (friction * friction * friction)
+		}
+		s.accelerate(strafe, forward, acceleration)
+
+		if onLadder {
+			v.X = math.Max(-ladderSpeed, math.Min(v.X, ladderSpeed))
+			v.Z = math.Max(-ladderSpeed, math.Min(v.Z, ladderSpeed))
+			v.Y = math.Max(v.Y, -ladderSpeed)
+			if s.Controls.Sneak && v.Y < 0 {
+				v.Y = 0
+			}
+		}
+		collidedHorizontally := s.collide(v.X, v.Y, v.Z)
+		if onLadder && collidedHorizontally {
+			v.Y = ladderClimbSpeed
+		}
+
+		// friction may have changed if the player landed
+		if player.IsOnGround {
+			friction = s.slipperinessBelow() * airFriction
+		} else {
+			friction = airFriction
+		}
+		v.X *= friction
+		v.Y = (v.Y - gravity) * verticalDrag
+		v.Z *= friction
+	}
+}
+
+// Adds the controls' movement, relative to where the player is facing,
+// to its velocity.
+func (s *Simulator) accelerate(strafe, forward, acceleration float64) {
+	d := strafe*strafe + forward*forward
+	if d < 1.0e-4 {
+		return
+	}
+	d = acceleration / math.Max(math.Sqrt(d), 1)
+	strafe *= d
+	forward *= d
+	e := s.World.CurrentPlayer.Entity
+	yaw := geometry.DegreesToRadians(float64(e.Facing.Yaw))
+	sin, cos := math.Sin(yaw), math.Cos(yaw)
+	e.Velocity.X += strafe*cos - forward*sin
+	e.Velocity.Z += forward*cos + strafe*sin
+}
+
+func (s *Simulator) slipperinessBelow() float64 {
+	p := s.World.CurrentPlayer.Entity.Position
+	block, _ := s.World.BlockAt(int32(math.Floor(p.X)), int32(math.Floor(p.Y))-1, int32(math.Floor(p.Z)))
+	if isOneOf(block.Type, iceBlocks) {
+		return iceSlipperiness
+	}
+	return defaultSlipperiness
+}
+
+// Moves the current player by up to the given amount, stopping at
+// solid blocks. The player steps up blocks no taller than
+// PlayerStepHeight, and doesn't walk off edges while sneaking. Returns
+// true if the player hit something horizontally.
+func (s *Simulator) collide(dx, dy, dz float64) bool {
+	player := &s.World.CurrentPlayer
+	e := player.Entity
+	start := PlayerBoundingBox(e.Position)
+	wasOnGround := player.IsOnGround
+
+	if s.Controls.Sneak && wasOnGround {
+		dx, dz = s.stayOnEdge(start, dx, dz)
+	}
+	wantX, wantY, wantZ := dx, dy, dz
+
+	box, dx, dy, dz := s.clip(start, dx, dy, dz)
+	onGround := wantY != dy && wantY < 0
+
+	if (wasOnGround || onGround) && (wantX != dx || wantZ != dz) {
+		stepped, sx, sy, sz := s.clip(start, wantX, PlayerStepHeight, wantZ)
+		down := -sy
+		for _, o := range s.World.CollisionBoxes(stepped.Extend(0, down, 0)) {
+			down = stepped.clipY(o, down)
+		}
+		if sx*sx+sz*sz > dx*dx+dz*dz {
+			box, dx, dy, dz = stepped.Offset(0, down, 0), sx, sy+down, sz
+			onGround = true
+		}
+	}
+
+	e.Position.Set(box.Min.X+PlayerWidth/2, box.Min.Y, box.Min.Z+PlayerWidth/2)
+	player.IsOnGround = onGround
+	if wantX != dx {
+		e.Velocity.X = 0
+	}
+	if wantY != dy {
+		e.Velocity.Y = 0
+	}
+	if wantZ != dz {
+		e.Velocity.Z = 0
+	}
+	return wantX != dx || wantZ != dz
+}
+
+// Moves the box along the y, x and then z axes until it hits something.
+// Returns the moved box and how far it moved.
+func (s *Simulator) clip(box BoundingBox, dx, dy, dz float64) (BoundingBox, float64, float64, float64) {
+	obstacles := s.World.CollisionBoxes(box.Extend(dx, dy, dz))
+	for _, o := range obstacles {
+		dy = box.clipY(o, dy)
+	}
+	box = box.Offset(0, dy, 0)
+	for _, o := range obstacles {
+		dx = box.clipX(o, dx)
+	}
+	box = box.Offset(dx, 0, 0)
+	for _, o := range obstacles {
+		dz = box.clipZ(o, dz)
+	}
+	box = box.Offset(0, 0, dz)
+	return box, dx, dy, dz
+}
+
+// Shortens horizontal movement that would leave the player with nothing
+// underneath.
+func (s *Simulator) stayOnEdge(box BoundingBox, dx, dz float64) (float64, float64) {
+	const step = 0.05
+	unsupported := func(dx, dz float64) bool {
+		return len(s.World.CollisionBoxes(box.Offset(dx, -1, dz))) == 0
+	}
+	shorten := func(d float64) float64 {
+		if d < step && d >= -step {
+			return 0
+		} else if d > 0 {
+			return d - step
+		}
+		return d + step
+	}
+	for dx != 0 && unsupported(dx, 0) {
+		dx = shorten(dx)
+	}
+	for dz != 0 && unsupported(0, dz) {
+		dz = shorten(dz)
+	}
+	for dx != 0 && dz != 0 && unsupported(dx, dz) {
+		dx, dz = shorten(dx), shorten(dz)
+	}
+	return dx, dz
+}
+
+// Sends the current player's position and facing, using the smallest
+// packet that describes what changed since the last one.
+func (s *Simulator) sendPosition() {
+	player := &s.World.CurrentPlayer
+	e := player.Entity
+	state := &s.physics
+	state.ticksSinceSent++
+
+	d := Vector3Float{
+		e.Position.X - state.lastPosition.X,
+		e.Position.Y - state.lastPosition.Y,
+		e.Position.Z - state.lastPosition.Z,
+	}
+	moved := d.X*d.X+d.Y*d.Y+d.Z*d.Z > 9e-4 || state.ticksSinceSent >= positionResendTicks
+	looked := e.Facing != state.lastFacing
+
+	switch {
+	case moved && looked:
+		s.send(&protocol.PlayerPositionLookForServer{
+			X:          e.Position.X,
+			Y:          e.Position.Y,
+			Stance:     player.Stance,
+			Z:          e.Position.Z,
+			Yaw:        e.Facing.Yaw,
+			Pitch:      e.Facing.Pitch,
+			IsOnGround: player.IsOnGround,
+		})
+	case moved:
+		s.send(&protocol.PlayerPosition{
+			X:          e.Position.X,
+			Y:          e.Position.Y,
+			Stance:     player.Stance,
+			Z:          e.Position.Z,
+			IsOnGround: player.IsOnGround,
+		})
+	case looked:
+		s.send(&protocol.PlayerLook{
+			Yaw:        e.Facing.Yaw,
+			Pitch:      e.Facing.Pitch,
+			IsOnGround: player.IsOnGround,
+		})
+	default:
+		s.send(&protocol.Player{IsOnGround: player.IsOnGround})
+	}
+	if moved {
+		state.lastPosition = e.Position
+		state.ticksSinceSent = 0
+	}
+	state.lastFacing = e.Facing
+}
+
+// Places the current player where the server says it is. The client
+// must confirm the new position by echoing it back.
+func (s *Simulator) handlePlayerPositionLook(t *protocol.PlayerPositionLookForClient) {
+	player := &s.World.CurrentPlayer
+	if player.Entity == nil {
+		return
+	}
+	e := player.Entity
+	e.Position.Set(t.X, t.Y, t.Z)
+	e.Facing.Set(t.Yaw, t.Pitch)
+	e.Velocity.Set(0, 0, 0)
+	player.Stance = t.Stance
+	player.IsOnGround = t.IsOnGround
+	s.physics = physicsState{
+		hasPosition:  true,
+		lastPosition: e.Position,
+		lastFacing:   e.Facing,
+	}
+	s.send(t.PacketForServer())
+}
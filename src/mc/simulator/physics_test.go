@@ -0,0 +1,170 @@
+package simulator
+
+import (
+	. "github.com/jeffh/goexpect"
+	"math"
+	"mc/protocol"
+	"testing"
+)
+
+// Creates a simulator whose player stands at the given position, above a
+// column whose bottom 16 blocks are stone.
+func createPhysicsSimulator(x, y, z float64) (*Simulator, chan interface{}) {
+	s, outbox, _ := createSimulator()
+	s.ProcessMessage(chunkData(0, 0, 1))
+	s.ProcessMessage(&protocol.PlayerPositionLookForClient{X: x, Y: y, Stance: y + PlayerEyeHeight, Z: z})
+	<-outbox // the position's confirmation
+	return s, outbox
+}
+
+func tick(s *Simulator, outbox chan interface{}, ticks int) {
+	for i := 0; i < ticks; i++ {
+		s.Tick()
+		<-outbox
+	}
+}
+
+func round(v float64) float64 {
+	return math.Floor(v*1000+0.5) / 1000
+}
+
+func TestSimulatorConfirmsTeleports(t *testing.T) {
+	s, outbox, _ := createSimulator()
+	s.Tick()
+	Expect(t, len(outbox), ToEqual, 0) // nothing is sent before the server places the player
+
+	s.ProcessMessage(&protocol.PlayerPositionLookForClient{X: 1, Y: 2, Stance: 3.62, Z: 4, Yaw: 90, IsOnGround: true})
+	Expect(t, <-outbox, ToEqual, &protocol.PlayerPositionLookForServer{X: 1, Y: 2, Stance: 3.62, Z: 4, Yaw: 90, IsOnGround: true})
+	Expect(t, s.World.CurrentPlayer.IsOnGround, ToBeTrue)
+
+	// without the column, the player stays put
+	s.Tick()
+	Expect(t, <-outbox, ToEqual, &protocol.Player{IsOnGround: true})
+	Expect(t, s.World.CurrentPlayer.Entity.Position, ToEqual, Vector3Float{1, 2, 4})
+}
+
+func TestSimulatorFallsOntoTheGround(t *testing.T) {
+	s, outbox := createPhysicsSimulator(8.5, 20, 8.5)
+	tick(s, outbox, 1) // gravity doesn't move the player until the next tick
+	s.Tick()
+	packet := (<-outbox).(*protocol.PlayerPosition)
+	Expect(t, round(packet.Y), ToEqual, 19.922)
+	Expect(t, packet.IsOnGround, Not(ToBeTrue))
+
+	tick(s, outbox, 30)
+	player := s.World.CurrentPlayer
+	Expect(t, player.Entity.Position, ToEqual, Vector3Float{8.5, 16, 8.5})
+	Expect(t, player.Stance, ToEqual, 16+PlayerEyeHeight)
+	Expect(t, player.IsOnGround, ToBeTrue)
+
+	// standing still only reports being on the ground
+	s.Tick()
+	Expect(t, <-outbox, ToEqual, &protocol.Player{IsOnGround: true})
+}
+
+func TestSimulatorWalksWhereThePlayerFaces(t *testing.T) {
+	s, outbox := createPhysicsSimulator(8.5, 16, 2.5)
+	tick(s, outbox, 2)
+	s.Controls.Forward = 1
+	tick(s, outbox, 20)
+
+	p := s.World.CurrentPlayer.Entity.Position
+	Expect(t, p.X, ToEqual, 8.5)
+	Expect(t, p.Y, ToEqual, 16.0)
+	Expect(t, round(p.Z), ToEqual, 6.558)
+
+	// walls stop the player
+	for x := int32(0); x < 16; x++ {
+		s.World.SetBlock(x, 16, 10, 1, 0)
+		s.World.SetBlock(x, 17, 10, 1, 0)
+	}
+	tick(s, outbox, 20)
+	Expect(t, round(s.World.CurrentPlayer.Entity.Position.Z), ToEqual, 9.7)
+	Expect(t, s.World.CurrentPlayer.Entity.Velocity.Z, ToEqual, 0.0)
+}
+
+func TestSimulatorSprintsFasterAndSneaksSlower(t *testing.T) {
+	distance := func(c Controls) float64 {
+		s, outbox := createPhysicsSimulator(8.5, 16, 2.5)
+		tick(s, outbox, 2)
+		s.Controls = c
+		tick(s, outbox, 10)
+		return s.World.CurrentPlayer.Entity.Position.Z - 2.5
+	}
+	walking := distance(Controls{Forward: 1})
+	Expect(t, distance(Controls{Forward: 1, Sprint: true}) > walking, ToBeTrue)
+	Expect(t, distance(Controls{Forward: 1, Sneak: true}) < walking/2, ToBeTrue)
+}
+
+func TestSimulatorStepsUpSlabsAndJumpsOntoBlocks(t *testing.T) {
+	s, outbox := createPhysicsSimulator(8.5, 16, 2.5)
+	for x := int32(0); x < 16; x++ {
+		s.World.SetBlock(x, 16, 5, 44, 0) // stone slab
+		s.World.SetBlock(x, 16, 8, 1, 0)
+		s.World.SetBlock(x, 16, 9, 1, 0)
+	}
+	tick(s, outbox, 2)
+	s.Controls.Forward = 1
+	tick(s, outbox, 15)
+	Expect(t, s.World.CurrentPlayer.Entity.Position.Y, ToEqual, 16.5)
+
+	// a full block is too tall to walk up
+	tick(s, outbox, 20)
+	Expect(t, round(s.World.CurrentPlayer.Entity.Position.Z), ToEqual, 7.7)
+
+	s.Controls.Jump = true
+	tick(s, outbox, 1)
+	s.Controls.Jump = false
+	tick(s, outbox, 15)
+	Expect(t, s.World.CurrentPlayer.Entity.Position.Y, ToEqual, 17.0)
+	Expect(t, s.World.CurrentPlayer.IsOnGround, ToBeTrue)
+}
+
+func TestSimulatorDoesNotWalkOffEdgesWhileSneaking(t *testing.T) {
+	s, outbox := createPhysicsSimulator(8.5, 17, 4.5)
+	for x := int32(0); x < 16; x++ {
+		for z := int32(0); z < 6; z++ {
+			s.World.SetBlock(x, 16, z, 1, 0)
+		}
+	}
+	tick(s, outbox, 2)
+	s.Controls = Controls{Forward: 1, Sneak: true}
+	tick(s, outbox, 40)
+	p := s.World.CurrentPlayer.Entity.Position
+	Expect(t, p.Y, ToEqual, 17.0)
+	Expect(t, p.Z < 6.3, ToBeTrue)
+}
+
+func TestSimulatorSinksSlowlyInWater(t *testing.T) {
+	s, outbox := createPhysicsSimulator(8.5, 19, 8.5)
+	for y := int32(16); y < 20; y++ {
+		s.World.SetBlock(8, y, 8, 9, 0)
+	}
+	tick(s, outbox, 10)
+	sunk := s.World.CurrentPlayer.Entity.Position.Y
+	Expect(t, sunk > 18, ToBeTrue)
+
+	// jumping swims up
+	s.Controls.Jump = true
+	tick(s, outbox, 20)
+	Expect(t, s.World.CurrentPlayer.Entity.Position.Y > sunk, ToBeTrue)
+}
+
+func TestSimulatorClimbsLadders(t *testing.T) {
+	s, outbox := createPhysicsSimulator(8.5, 16, 8.5)
+	for y := int32(16); y < 24; y++ {
+		s.World.SetBlock(8, y, 8, 65, 2)
+		s.World.SetBlock(8, y, 9, 1, 0)
+	}
+	s.Controls.Forward = 1
+	tick(s, outbox, 20)
+	Expect(t, s.World.CurrentPlayer.Entity.Position.Y > 17.5, ToBeTrue)
+}
+
+func TestSimulatorAppliesServerVelocity(t *testing.T) {
+	s, outbox := createPhysicsSimulator(8.5, 16, 8.5)
+	tick(s, outbox, 2)
+	s.ProcessMessage(&protocol.EntityVelocity{EntityID: 7, Y: 3360}) // 0.42 blocks per tick
+	tick(s, outbox, 1)
+	Expect(t, s.World.CurrentPlayer.Entity.Position.Y > 16.3, ToBeTrue)
+}
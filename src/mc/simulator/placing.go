@@ -0,0 +1,247 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"mc/protocol"
+	"mc/recipes"
+	"mc/registry"
+	"sort"
+)
+
+// ticks to wait for the server to confirm a block was placed
+const placeConfirmTicks = 20
+
+// Blocks that placed blocks replace instead of being clicked on.
+var replaceableBlocks = append(blockIDs("air", "tallgrass", "deadbush", "fire", "snow_layer", "vine"),
+	append(waterBlocks, lavaBlocks...)...)
+
+// Emitted when the server confirms a block was placed.
+type BlockPlacedEvent struct {
+	Position Vector3Int
+	Block    Block
+}
+
+// Emitted when the server doesn't place a block.
+type PlacementFailedEvent struct {
+	Position Vector3Int
+	Err      error
+}
+
+// A block placed by the current player that the server hasn't
+// confirmed yet.
+type pendingPlacement struct {
+	position Vector3Int
+	old      Block
+	ticks    int
+}
+
+// Returns the neighbor of the block on the given face.
+func neighbor(v Vector3Int, face protocol.Face) Vector3Int {
+	switch face {
+	case protocol.FaceYNeg:
+		v.Y--
+	case protocol.FaceYPos:
+		v.Y++
+	case protocol.FaceZNeg:
+		v.Z--
+	case protocol.FaceZPos:
+		v.Z++
+	case protocol.FaceXNeg:
+		v.X--
+	case protocol.FaceXPos:
+		v.X++
+	}
+	return v
+}
+
+// Returns the face on the other side of a block.
+func oppositeFace(face protocol.Face) protocol.Face {
+	return face ^ 1
+}
+
+// Returns the center of the block's face.
+func faceCenter(v Vector3Int, face protocol.Face) Vector3Float {
+	c := blockCenter(v)
+	n := neighbor(Vector3Int{}, face)
+	return Vector3Float{c.X + float64(n.X)/2, c.Y + float64(n.Y)/2, c.Z + float64(n.Z)/2}
+}
+
+// Returns true if the point is in front of the block's face, so the
+// face can be seen from there.
+func facesPoint(v Vector3Int, face protocol.Face, p Vector3Float) bool {
+	c := faceCenter(v, face)
+	n := neighbor(Vector3Int{}, face)
+	return (p.X-c.X)*float64(n.X)+(p.Y-c.Y)*float64(n.Y)+(p.Z-c.Z)*float64(n.Z) > 0
+}
+
+// Returns an error if the current player can't click the block's face
+// from where it is.
+func (s *Simulator) checkReach(v Vector3Int, face protocol.Face) error {
+	eye := s.EyePosition()
+	p := faceCenter(v, face)
+	switch {
+	case !facesPoint(v, face, eye):
+		return fmt.Errorf("Face %d of the block at %v is turned away", face, v)
+	case pointDistance(eye, p) > PlayerReach:
+		return fmt.Errorf("Block at %v is out of reach", v)
+	case !s.World.canSee(eye, p, v):
+		return fmt.Errorf("Block at %v can't be seen", v)
+	}
+	return nil
+}
+
+func pointDistance(a, b Vector3Float) float64 {
+	dx, dy, dz := a.X-b.X, a.Y-b.Y, a.Z-b.Z
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
+
+// Right clicks the center of the block's face with the held item,
+// facing it first.
+func (s *Simulator) clickBlock(v Vector3Int, face protocol.Face) {
+	p := faceCenter(v, face)
+	s.lookAt(p)
+	cursor := func(p float64, v int32) int8 {
+		return int8((p - float64(v)) * 16)
+	}
+	s.send(&protocol.PlayerBlockPlacement{
+		X:         v.X,
+		Y:         byte(v.Y),
+		Z:         v.Z,
+		Direction: int8(face),
+		ItemHeld:  s.heldItem(),
+		CursorX:   cursor(p.X, v.X),
+		CursorY:   cursor(p.Y, v.Y),
+		CursorZ:   cursor(p.Z, v.Z),
+	})
+	s.swingArm()
+}
+
+// Uses the held item on the block, like right clicking a door, button,
+// lever or bed. The face that the player can see is clicked.
+func (s *Simulator) UseBlock(v Vector3Int) error {
+	if s.World.CurrentPlayer.Entity == nil {
+		return fmt.Errorf("The player hasn't spawned")
+	}
+	if _, ok := s.World.BlockAt(v.X, v.Y, v.Z); !ok {
+		return fmt.Errorf("Block at %v isn't loaded", v)
+	}
+	face := faceTowards(s.EyePosition(), v)
+	if err := s.checkReach(v, face); err != nil {
+		return err
+	}
+	s.clickBlock(v, face)
+	return nil
+}
+
+// Places the item, which has to be in the player's inventory, at the
+// target position by clicking the face of a solid neighbor. The held
+// item changes to the item if needed. A BlockPlacedEvent is emitted when
+// the server places the block.
+func (s *Simulator) PlaceBlock(target Vector3Int, item recipes.Item) error {
+	player := &s.World.CurrentPlayer
+	e := player.Entity
+	if e == nil {
+		return fmt.Errorf("The player hasn't spawned")
+	}
+	old, ok := s.World.BlockAt(target.X, target.Y, target.Z)
+	if !ok {
+		return fmt.Errorf("Block at %v isn't loaded", target)
+	}
+	if !isOneOf(old.Type, replaceableBlocks) {
+		return fmt.Errorf("Block at %v is in the way", target)
+	}
+	if info, ok := registry.ItemByID(item.ID); ok && info.Block != nil && info.Block.Solid {
+		if PlayerBoundingBox(e.Position).Intersects(blockBox(target)) {
+			return fmt.Errorf("The player is standing at %v", target)
+		}
+	}
+	slot, ok := s.findItem(item)
+	if !ok {
+		return fmt.Errorf("No %s in the inventory", item)
+	}
+
+	clicked, face, err := s.placementFace(target)
+	if err != nil {
+		return err
+	}
+	s.StopEating()
+	if err := s.holdSlot(slot); err != nil {
+		return err
+	}
+	s.clickBlock(clicked, face)
+	if !s.World.GameMode.IsCreative() {
+		held := player.Inventory.HotbarSlot(player.HeldItemSlot)
+		player.Inventory.Slots[held] = withCount(player.Inventory.Slots[held], int(player.Inventory.Slots[held].Count)-1)
+		s.copyFromInventory(held)
+	}
+	s.placing = append(s.placing, &pendingPlacement{position: target, old: old})
+	return nil
+}
+
+// Returns a solid neighbor of the target, and its face that touches the
+// target, that the current player can click. The closest one is picked.
+func (s *Simulator) placementFace(target Vector3Int) (Vector3Int, protocol.Face, error) {
+	eye := s.EyePosition()
+	faces := []protocol.Face{
+		protocol.FaceYNeg, protocol.FaceYPos, protocol.FaceZNeg,
+		protocol.FaceZPos, protocol.FaceXNeg, protocol.FaceXPos,
+	}
+	sort.SliceStable(faces, func(i, j int) bool {
+		return pointDistance(eye, faceCenter(target, faces[i])) < pointDistance(eye, faceCenter(target, faces[j]))
+	})
+	err := fmt.Errorf("Nothing to place a block against at %v", target)
+	for _, f := range faces {
+		clicked := neighbor(target, f)
+		block, _ := s.World.BlockAt(clicked.X, clicked.Y, clicked.Z)
+		if info, ok := registry.BlockByID(block.Type); !ok || !info.Solid || isOneOf(block.Type, replaceableBlocks) {
+			continue
+		}
+		face := oppositeFace(f)
+		if err = s.checkReach(clicked, face); err == nil {
+			return clicked, face, nil
+		}
+	}
+	return Vector3Int{}, 0, err
+}
+
+// Returns the inventory slot holding the item, preferring the held item
+// and then the hotbar.
+func (s *Simulator) findItem(item recipes.Item) (int16, bool) {
+	inventory := s.World.CurrentPlayer.Inventory
+	for _, slot := range s.usableSlots() {
+		if stack := inventory.Slot(slot); !stack.IsEmpty() && item.Matches(itemOf(stack)) {
+			return slot, true
+		}
+	}
+	return 0, false
+}
+
+func (s *Simulator) tickPlacing() {
+	pending := s.placing[:0]
+	for _, p := range s.placing {
+		if p.ticks++; p.ticks > placeConfirmTicks {
+			s.emit(&PlacementFailedEvent{Position: p.position, Err: fmt.Errorf("The server didn't place the block")})
+			continue
+		}
+		pending = append(pending, p)
+	}
+	s.placing = pending
+}
+
+// Confirms placed blocks when the server changes them. The server sends
+// the old block back if it refuses to place one.
+func (s *Simulator) placingBlockChanged(v Vector3Int, updated Block) {
+	for i, p := range s.placing {
+		if p.position != v {
+			continue
+		}
+		s.placing = append(s.placing[:i], s.placing[i+1:]...)
+		if updated.Type == p.old.Type {
+			s.emit(&PlacementFailedEvent{Position: v, Err: fmt.Errorf("The server refused to place the block at %v", v)})
+		} else {
+			s.emit(&BlockPlacedEvent{Position: v, Block: updated})
+		}
+		return
+	}
+}
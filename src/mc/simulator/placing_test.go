@@ -0,0 +1,83 @@
+package simulator
+
+import (
+	. "github.com/jeffh/goexpect"
+	"mc/protocol"
+	"mc/recipes"
+	"testing"
+)
+
+var dirtItem = recipes.Item{ID: 3}
+
+func TestPlaceBlockClicksASolidNeighbor(t *testing.T) {
+	it := NewIt(t)
+	s, outbox := createStandingSimulator(map[int]protocol.Slot{20: stack(dirt, 5)})
+	events := recordEvents(s, (*BlockPlacedEvent)(nil), (*PlacementFailedEvent)(nil))
+	it.Expects(s.PlaceBlock(Vector3Int{3, 16, 2}, dirtItem), ToBeNil)
+
+	sent := sentPackets(outbox, (*protocol.ClickWindow)(nil), (*protocol.PlayerBlockPlacement)(nil))
+	it.Expects(sent, ToBeLengthOf, 2)
+	it.Expects(sent[0].(*protocol.ClickWindow).Mode, ToEqual, protocol.ClickModeNumberKey)
+	it.Expects(sent[1], ToEqual, &protocol.PlayerBlockPlacement{
+		X: 3, Y: 15, Z: 2,
+		Direction: int8(protocol.FaceYPos),
+		ItemHeld:  stack(dirt, 5),
+		CursorX:   8, CursorY: 16, CursorZ: 8,
+	})
+	it.Expects(s.heldItem(), ToEqual, stack(dirt, 4))
+	it.Expects(s.World.CurrentPlayer.Entity.Facing.Pitch > 0, ToBeTrue)
+
+	// the server also resends the clicked block
+	s.ProcessMessage(&protocol.BlockChange{X: 3, Y: 15, Z: 2, Type: 1})
+	it.Expects(*events, ToBeEmpty)
+	s.ProcessMessage(&protocol.BlockChange{X: 3, Y: 16, Z: 2, Type: 3})
+	it.Expects(*events, ToEqual, []interface{}{&BlockPlacedEvent{
+		Position: Vector3Int{3, 16, 2},
+		Block:    Block{Type: 3, Skylight: 15, Biome: 4},
+	}})
+}
+
+func TestPlaceBlockFailsWhenTheServerRefuses(t *testing.T) {
+	it := NewIt(t)
+	s, outbox := createStandingSimulator(map[int]protocol.Slot{36: stack(dirt, 5)})
+	events := recordEvents(s, (*BlockPlacedEvent)(nil), (*PlacementFailedEvent)(nil))
+	s.PlaceBlock(Vector3Int{3, 16, 2}, dirtItem)
+	s.ProcessMessage(&protocol.BlockChange{X: 3, Y: 16, Z: 2, Type: 0})
+	it.Expects(*events, ToBeLengthOf, 1)
+	it.Expects((*events)[0].(*PlacementFailedEvent).Position, ToEqual, Vector3Int{3, 16, 2})
+
+	s.PlaceBlock(Vector3Int{2, 16, 3}, dirtItem)
+	tickDigging(s, outbox, placeConfirmTicks+1)
+	it.Expects(*events, ToBeLengthOf, 2)
+	it.Expects((*events)[1].(*PlacementFailedEvent).Position, ToEqual, Vector3Int{2, 16, 3})
+}
+
+func TestPlaceBlockChecksTheTarget(t *testing.T) {
+	it := NewIt(t)
+	s, outbox := createStandingSimulator(map[int]protocol.Slot{36: stack(dirt, 5)})
+	it.Expects(s.PlaceBlock(Vector3Int{2, 16, 2}, dirtItem), Not(ToBeNil)) // the player is there
+	it.Expects(s.PlaceBlock(Vector3Int{3, 15, 2}, dirtItem), Not(ToBeNil)) // stone is there
+	it.Expects(s.PlaceBlock(Vector3Int{3, 18, 2}, dirtItem), Not(ToBeNil)) // nothing to click
+	it.Expects(s.PlaceBlock(Vector3Int{8, 16, 2}, dirtItem), Not(ToBeNil)) // out of reach
+	it.Expects(s.PlaceBlock(Vector3Int{3, 16, 2}, recipes.Item{ID: 4}), Not(ToBeNil))
+
+	s.World.SetBlock(4, 16, 2, 1, 0)
+	s.World.SetBlock(4, 17, 2, 1, 0)
+	it.Expects(s.PlaceBlock(Vector3Int{5, 16, 2}, dirtItem), Not(ToBeNil)) // behind a wall
+	it.Expects(sentPackets(outbox, (*protocol.PlayerBlockPlacement)(nil)), ToBeEmpty)
+	it.Expects(s.heldItem(), ToEqual, stack(dirt, 5))
+}
+
+func TestUseBlockClicksTheVisibleFace(t *testing.T) {
+	it := NewIt(t)
+	s, outbox := createStandingSimulator(nil)
+	s.World.SetBlock(3, 16, 2, 69, 5) // a lever
+	it.Expects(s.UseBlock(Vector3Int{3, 16, 2}), ToBeNil)
+	it.Expects(sentPackets(outbox, (*protocol.PlayerBlockPlacement)(nil)), ToEqual, []interface{}{&protocol.PlayerBlockPlacement{
+		X: 3, Y: 16, Z: 2,
+		Direction: int8(protocol.FaceYPos),
+		ItemHeld:  protocol.EmptySlot,
+		CursorX:   8, CursorY: 16, CursorZ: 8,
+	}})
+	it.Expects(s.UseBlock(Vector3Int{9, 16, 2}), Not(ToBeNil))
+}
@@ -0,0 +1,225 @@
+package simulator
+
+import (
+	"math"
+	"mc/geometry"
+	"mc/protocol"
+)
+
+// The width and height of entities, in blocks.
+type entitySize struct {
+	width, height float64
+}
+
+var mobSizes = map[protocol.MobType]entitySize{
+	protocol.MobCreeper:      {0.6, 1.8},
+	protocol.MobSkeleton:     {0.6, 1.8},
+	protocol.MobSpider:       {1.4, 0.9},
+	protocol.MobGiantZombie:  {3.6, 10.8},
+	protocol.MobZombie:       {0.6, 1.8},
+	protocol.MobSlime:        {0.6, 0.6}, // times the slime's size
+	protocol.MobGhast:        {4, 4},
+	protocol.MobZombiePigman: {0.6, 1.8},
+	protocol.MobEnterman:     {0.6, 2.9},
+	protocol.MobCaveSpider:   {0.7, 0.5},
+	protocol.MobSilverFish:   {0.3, 0.7},
+	protocol.MobBlaze:        {0.6, 1.8},
+	protocol.MobMagmaCube:    {0.6, 0.6}, // times the cube's size
+	protocol.MobEnderDragon:  {16, 8},
+	protocol.MobWither:       {0.9, 4},
+	protocol.MobBat:          {0.5, 0.9},
+	protocol.MobWitch:        {0.6, 1.8},
+	protocol.MobPig:          {0.9, 0.9},
+	protocol.MobSheep:        {0.9, 1.3},
+	protocol.MobCow:          {0.9, 1.3},
+	protocol.MobChicken:      {0.3, 0.7},
+	protocol.MobSquid:        {0.95, 0.95},
+	protocol.MobWolf:         {0.6, 0.8},
+	protocol.MobMooshroom:    {0.9, 1.3},
+	protocol.MobSnowman:      {0.4, 1.8},
+	protocol.MobOcelot:       {0.6, 0.8},
+	protocol.MobIronGolem:    {1.4, 2.9},
+	protocol.MobVillager:     {0.6, 1.8},
+}
+
+var objectSizes = map[protocol.EntityType]entitySize{
+	protocol.EntityBoat:             {1.5, 0.6},
+	protocol.EntityItemStack:        {0.25, 0.25},
+	protocol.EntityMinecart:         {0.98, 0.7},
+	protocol.EntityMinecartStorage:  {0.98, 0.7},
+	protocol.EntityMinecartPowered:  {0.98, 0.7},
+	protocol.EntityActiveTNT:        {0.98, 0.98},
+	protocol.EntityEnderCrystal:     {2, 2},
+	protocol.EntityArrow:            {0.5, 0.5},
+	protocol.EntityFireball:         {1, 1},
+	protocol.EntityFireCharge:       {0.3125, 0.3125},
+	protocol.EntityWitherSkull:      {0.3125, 0.3125},
+	protocol.EntityFallingObject:    {0.98, 0.98},
+	protocol.EntityFallingDragonEgg: {0.98, 0.98},
+}
+
+// the size of entities missing from the tables above, such as thrown
+// items
+var smallEntity = entitySize{0.25, 0.25}
+
+// the metadata of slimes and magma cubes that holds their size
+const slimeSizeIndex protocol.EntityMetadataIndex = 16
+
+func (e *Entity) size() entitySize {
+	switch e.Kind {
+	case PlayerEntity:
+		return entitySize{PlayerWidth, PlayerHeight}
+	case MobEntity:
+		size, ok := mobSizes[e.MobType]
+		if !ok {
+			return entitySize{0.6, 1.8}
+		}
+		if e.MobType == protocol.MobSlime || e.MobType == protocol.MobMagmaCube {
+			if n, ok := e.Metadata[slimeSizeIndex].(int8); ok && n > 0 {
+				size.width *= float64(n)
+				size.height *= float64(n)
+			}
+		}
+		return size
+	case ObjectEntity:
+		if size, ok := objectSizes[e.Type]; ok {
+			return size
+		}
+	case ExperienceOrbEntity:
+		return entitySize{0.5, 0.5}
+	case PaintingEntity:
+		return entitySize{0.5, 0.5}
+	}
+	return smallEntity
+}
+
+// Returns the box an entity fills, which is centered on its position
+// horizontally and starts at its feet.
+func EntityBoundingBox(e *Entity) BoundingBox {
+	size := e.size()
+	r := size.width / 2
+	p := e.Position
+	return NewBoundingBox(p.X-r, p.Y, p.Z-r, p.X+r, p.Y+size.height, p.Z+r)
+}
+
+func (b BoundingBox) box() geometry.Box {
+	return geometry.Box{Min: geometry.Vector(b.Min), Max: geometry.Vector(b.Max)}
+}
+
+// Returns the boxes of the block that can be pointed at, relative to the
+// block's position. Blocks that can be walked through, apart from air,
+// fire and liquids, are treated as full blocks.
+func (b Block) SelectionBoxes() []BoundingBox {
+	if boxes := b.BoundingBoxes(); len(boxes) > 0 {
+		return boxes
+	}
+	if b.Type == 0 || isOneOf(b.Type, unselectableBlocks) {
+		return nil
+	}
+	return []BoundingBox{NewBoundingBox(0, 0, 0, 1, 1, 1)}
+}
+
+var unselectableBlocks = append(blockIDs("fire"), append(waterBlocks, lavaBlocks...)...)
+
+// The block a line of sight hits.
+type BlockHit struct {
+	Position Vector3Int
+	Block    Block
+	Face     protocol.Face // the face that is hit
+	Point    Vector3Float  // where on the face
+	Distance float64
+}
+
+// Returns the block under the crosshair of someone at the given point
+// facing the given way, if there's one within reach.
+func (w *World) RaycastBlock(from Vector3Float, facing RotationFloat, reach float64) (BlockHit, bool) {
+	return w.raycast(from, geometry.Direction(facing.Yaw, facing.Pitch), reach, Block.SelectionBoxes)
+}
+
+// Returns the first block along the ray whose boxes the ray passes
+// through. Blocks that aren't loaded stop the ray like full blocks.
+func (w *World) raycast(from Vector3Float, dir geometry.Vector, reach float64, boxes func(Block) []BoundingBox) (BlockHit, bool) {
+	origin := geometry.Vector(from)
+	var hit BlockHit
+	found := false
+	geometry.Traverse(origin, dir, reach, func(p geometry.Point, _ float64) bool {
+		v := Vector3Int(p)
+		block, ok := w.collisionBlock(v.X, v.Y, v.Z)
+		candidates := boxes(block)
+		if !ok {
+			candidates = []BoundingBox{NewBoundingBox(0, 0, 0, 1, 1, 1)}
+		}
+		best := math.Inf(1)
+		for _, b := range candidates {
+			b = b.Offset(float64(v.X), float64(v.Y), float64(v.Z))
+			if d, face, ok := b.box().Intersect(origin, dir, reach); ok && d < best {
+				best = d
+				hit = BlockHit{Position: v, Block: block, Face: face, Distance: d}
+			}
+		}
+		if found = !math.IsInf(best, 1); found {
+			hit.Point = Vector3Float(origin.Add(dir.Scale(hit.Distance)))
+		}
+		return !found
+	})
+	return hit, found
+}
+
+// Returns the nearest entity under the crosshair of someone at the given
+// point facing the given way, if there's one within reach, along with
+// how far away it is. Entities behind blocks can't be hit, and the
+// ignored entity, usually the one looking, is passed through.
+func (w *World) RaycastEntity(from Vector3Float, facing RotationFloat, reach float64, ignore *Entity) (*Entity, float64, bool) {
+	origin := geometry.Vector(from)
+	dir := geometry.Direction(facing.Yaw, facing.Pitch)
+	if hit, ok := w.raycast(from, dir, reach, Block.SelectionBoxes); ok {
+		reach = hit.Distance
+	}
+	var nearest *Entity
+	best := math.Inf(1)
+	for _, e := range w.Entities {
+		if e == ignore || e.IsDead {
+			continue
+		}
+		if d, _, ok := EntityBoundingBox(e).box().Intersect(origin, dir, reach); ok && d < best {
+			nearest, best = e, d
+		}
+	}
+	return nearest, best, nearest != nil
+}
+
+// Returns the block under the current player's crosshair, if it's close
+// enough to be dug or clicked.
+func (s *Simulator) TargetBlock() (BlockHit, bool) {
+	e := s.World.CurrentPlayer.Entity
+	if e == nil {
+		return BlockHit{}, false
+	}
+	return s.World.RaycastBlock(s.EyePosition(), e.Facing, PlayerReach)
+}
+
+// Returns the entity under the current player's crosshair, if it's close
+// enough to be hit.
+func (s *Simulator) TargetEntity() (*Entity, bool) {
+	e := s.World.CurrentPlayer.Entity
+	if e == nil {
+		return nil, false
+	}
+	target, _, ok := s.World.RaycastEntity(s.EyePosition(), e.Facing, AttackReach, e)
+	return target, ok
+}
+
+// Turns the current player to face the point.
+func (s *Simulator) lookAt(p Vector3Float) {
+	yaw, pitch := geometry.LookAt(geometry.Vector(s.EyePosition()), geometry.Vector(p))
+	s.World.CurrentPlayer.Entity.Facing.Set(yaw, pitch)
+}
+
+// Returns true if nothing solid is between the points, apart from the
+// block being looked at.
+func (w *World) canSee(from, to Vector3Float, target Vector3Int) bool {
+	d := geometry.Vector(to).Sub(geometry.Vector(from))
+	// a little past the point, so a face on the way is hit
+	hit, ok := w.raycast(from, d.Normalize(), d.Length()+0.01, Block.BoundingBoxes)
+	return !ok || hit.Position == target
+}
@@ -0,0 +1,110 @@
+package simulator
+
+import (
+	. "github.com/jeffh/goexpect"
+	"mc/protocol"
+	"testing"
+)
+
+var (
+	lookingDown = RotationFloat{Yaw: 0, Pitch: 90}
+	lookingEast = RotationFloat{Yaw: -90, Pitch: 0}
+)
+
+func TestRaycastBlockFindsTheFaceLookedAt(t *testing.T) {
+	it := NewIt(t)
+	s, _ := createStandingSimulator(nil)
+	eye := s.EyePosition()
+	hit, ok := s.World.RaycastBlock(eye, lookingDown, PlayerReach)
+	it.Expects(ok, ToBeTrue)
+	it.Expects(hit.Position, ToEqual, Vector3Int{2, 15, 2})
+	it.Expects(hit.Face, ToEqual, protocol.FaceYPos)
+	it.Expects(hit.Point, ToEqual, Vector3Float{2.5, 16, 2.5})
+	it.Expects(round(hit.Distance), ToEqual, PlayerEyeHeight)
+
+	_, ok = s.World.RaycastBlock(eye, lookingEast, PlayerReach)
+	it.Expects(ok, Not(ToBeTrue))
+
+	s.World.SetBlock(4, 17, 2, 1, 0)
+	hit, ok = s.World.RaycastBlock(eye, lookingEast, PlayerReach)
+	it.Expects(ok, ToBeTrue)
+	it.Expects(hit.Position, ToEqual, Vector3Int{4, 17, 2})
+	it.Expects(hit.Face, ToEqual, protocol.FaceXNeg)
+	it.Expects(hit.Distance, ToEqual, 1.5)
+	_, ok = s.World.RaycastBlock(eye, lookingEast, 1.4)
+	it.Expects(ok, Not(ToBeTrue))
+}
+
+func TestBlocksWithoutCollisionCanBeSelected(t *testing.T) {
+	it := NewIt(t)
+	it.Expects(Block{Type: 31}.SelectionBoxes(), ToBeLengthOf, 1) // tall grass
+	it.Expects(Block{Type: 44}.SelectionBoxes(), ToEqual, []BoundingBox{NewBoundingBox(0, 0, 0, 1, 0.5, 1)})
+	it.Expects(Block{Type: 0}.SelectionBoxes(), ToBeEmpty)
+	it.Expects(Block{Type: 9}.SelectionBoxes(), ToBeEmpty)
+
+	s, _ := createStandingSimulator(nil)
+	s.World.SetBlock(4, 17, 2, 31, 1)
+	eye := s.EyePosition()
+	hit, _ := s.World.RaycastBlock(eye, lookingEast, PlayerReach)
+	it.Expects(hit.Position, ToEqual, Vector3Int{4, 17, 2})
+	it.Expects(s.World.canSee(eye, Vector3Float{5, 17.5, 2.5}, Vector3Int{5, 17, 2}), ToBeTrue)
+}
+
+func TestEntitiesHaveBoundingBoxesOfTheirSize(t *testing.T) {
+	it := NewIt(t)
+	spider := &Entity{Kind: MobEntity, MobType: protocol.MobSpider}
+	it.Expects(EntityBoundingBox(spider), ToEqual, NewBoundingBox(-0.7, 0, -0.7, 0.7, 0.9, 0.7))
+	slime := &Entity{Kind: MobEntity, MobType: protocol.MobSlime, Position: Vector3Float{1, 2, 3},
+		Metadata: map[protocol.EntityMetadataIndex]interface{}{slimeSizeIndex: int8(4)}}
+	box := EntityBoundingBox(slime)
+	it.Expects(round(box.Max.X-box.Min.X), ToEqual, 2.4)
+	it.Expects(round(box.Max.Y-box.Min.Y), ToEqual, 2.4)
+	player := &Entity{Kind: PlayerEntity}
+	it.Expects(EntityBoundingBox(player), ToEqual, PlayerBoundingBox(Vector3Float{}))
+}
+
+func TestRaycastEntityFindsTheNearestVisibleEntity(t *testing.T) {
+	it := NewIt(t)
+	s, _ := createStandingSimulator(nil)
+	zombie := spawnMob(s, 20, protocol.MobZombie, 4.5, 2.5)
+	pig := spawnMob(s, 21, protocol.MobPig, 6.5, 2.5)
+	me := s.World.CurrentPlayer.Entity
+	eye := s.EyePosition()
+	lookingDownEast := RotationFloat{Yaw: -90, Pitch: 20}
+
+	e, _, _ := s.World.RaycastEntity(eye, lookingEast, 10, nil)
+	it.Expects(e, ToEqual, me) // from inside
+
+	e, distance, ok := s.World.RaycastEntity(eye, lookingEast, 10, me)
+	it.Expects(ok, ToBeTrue)
+	it.Expects(e, ToEqual, zombie)
+	it.Expects(round(distance), ToEqual, 1.7)
+	_, _, ok = s.World.RaycastEntity(eye, lookingEast, 1.5, me)
+	it.Expects(ok, Not(ToBeTrue))
+
+	zombie.IsDead = true
+	e, _, ok = s.World.RaycastEntity(eye, lookingDownEast, 10, me)
+	it.Expects(ok, ToBeTrue)
+	it.Expects(e, ToEqual, pig)
+
+	s.World.SetBlock(5, 16, 2, 1, 0)
+	_, _, ok = s.World.RaycastEntity(eye, lookingDownEast, 10, me)
+	it.Expects(ok, Not(ToBeTrue))
+	zombie.IsDead = false
+
+	_, ok = s.TargetEntity()
+	it.Expects(ok, Not(ToBeTrue)) // facing away
+	s.lookAt(entityCenter(zombie))
+	e, _ = s.TargetEntity()
+	it.Expects(e, ToEqual, zombie)
+}
+
+func TestDigNeedsTheBlockInSight(t *testing.T) {
+	it := NewIt(t)
+	s, _ := createStandingSimulator(nil)
+	s.World.SetBlock(3, 17, 2, 1, 0)
+	it.Expects(s.Dig(Vector3Int{4, 16, 2}), Not(ToBeNil))
+	it.Expects(s.IsDigging(), Not(ToBeTrue))
+	it.Expects(s.Dig(Vector3Int{3, 17, 2}), ToBeNil)
+	it.Expects(s.digging.face, ToEqual, protocol.FaceXNeg)
+}
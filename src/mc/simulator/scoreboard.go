@@ -0,0 +1,249 @@
+package simulator
+
+import (
+	"mc/protocol"
+	"sort"
+)
+
+// The most scores the sidebar shows.
+const SidebarSize = 15
+
+// the bit of a team's friendly fire setting that shows invisible
+// teammates
+const seeInvisibleTeammatesFlag = 0x2
+
+// A scoreboard objective, which keeps a score for each player.
+type Objective struct {
+	Name        string
+	DisplayName string
+	Scores      map[string]int32 // by player name
+}
+
+// A player's score for an objective.
+type Score struct {
+	Player string
+	Value  int32
+}
+
+// A team of players, which the server may show differently and stop
+// from hurting each other.
+type Team struct {
+	Name                   string
+	DisplayName            string
+	Prefix, Suffix         string // shown around the names of the team's players
+	FriendlyFire           bool   // players of the team can hurt each other
+	SeesInvisibleTeammates bool
+	Players                map[string]bool
+}
+
+// Returns the player's name the way the team shows it, such as in chat.
+func (t *Team) FormatName(player string) string {
+	return t.Prefix + player + t.Suffix
+}
+
+// Emitted when an objective is added, renamed or removed.
+type ObjectiveChangedEvent struct {
+	Objective *Objective
+	Removed   bool
+}
+
+// Emitted when a player's score changes. Removed scores have a Value of
+// 0.
+type ScoreChangedEvent struct {
+	Objective *Objective
+	Score     Score
+	Removed   bool
+}
+
+// Emitted when an objective is shown in, or removed from, a position of
+// the screen. Objective is nil if nothing is shown there anymore.
+type DisplayedObjectiveChangedEvent struct {
+	Position  protocol.ScoreboardPosition
+	Objective *Objective
+}
+
+// Emitted when a team is added, updated or removed, or players join or
+// leave it.
+type TeamChangedEvent struct {
+	Team         *Team
+	Removed      bool
+	Joined, Left []string // the players that joined or left the team
+}
+
+// Returns the objective with the given name, or nil if there's none.
+func (w *World) Objective(name string) *Objective {
+	return w.Objectives[name]
+}
+
+// Returns the objective shown at the position of the screen, or nil if
+// there's none.
+func (w *World) DisplayedObjective(position protocol.ScoreboardPosition) *Objective {
+	return w.Objectives[w.DisplayedObjectives[position]]
+}
+
+// Returns the player's score for the objective, if there's one.
+func (w *World) Score(objective, player string) (int32, bool) {
+	o := w.Objectives[objective]
+	if o == nil {
+		return 0, false
+	}
+	v, ok := o.Scores[player]
+	return v, ok
+}
+
+// Returns the scores shown in the sidebar, highest first, like the
+// vanilla client does. Servers often put text in the sidebar as fake
+// player names.
+func (w *World) Sidebar() []Score {
+	o := w.DisplayedObjective(protocol.ScoreboardPositionSidebar)
+	if o == nil {
+		return nil
+	}
+	scores := o.SortedScores()
+	if len(scores) > SidebarSize {
+		scores = scores[:SidebarSize]
+	}
+	return scores
+}
+
+// Returns the objective's scores, highest first. Equal scores are sorted
+// by player name.
+func (o *Objective) SortedScores() []Score {
+	scores := make([]Score, 0, len(o.Scores))
+	for player, v := range o.Scores {
+		scores = append(scores, Score{Player: player, Value: v})
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Value != scores[j].Value {
+			return scores[i].Value > scores[j].Value
+		}
+		return scores[i].Player < scores[j].Player
+	})
+	return scores
+}
+
+// Returns the team the player is on, or nil if the player isn't on one.
+func (w *World) TeamOf(player string) *Team {
+	for _, t := range w.Teams {
+		if t.Players[player] {
+			return t
+		}
+	}
+	return nil
+}
+
+// Returns true if the players are on the same team.
+func (w *World) AreTeammates(a, b string) bool {
+	t := w.TeamOf(a)
+	return t != nil && t.Players[b]
+}
+
+// Returns true if the player is a teammate that the current player can't
+// hurt.
+func (w *World) IsProtectedTeammate(player string) bool {
+	t := w.TeamOf(w.CurrentPlayer.Name)
+	return t != nil && t.Players[player] && !t.FriendlyFire
+}
+
+//////////////////////////////////////////////////////////
+
+func (s *Simulator) handleScoreboardObjective(t *protocol.ScoreboardObjective) {
+	w := s.World
+	o := w.Objectives[t.Name]
+	switch t.Type {
+	case protocol.ScoreboardTypeCreate, protocol.ScoreboardTypeUpdate:
+		if o == nil {
+			o = &Objective{Name: t.Name, Scores: make(map[string]int32)}
+			w.Objectives[t.Name] = o
+		}
+		o.DisplayName = t.Value
+		s.emit(&ObjectiveChangedEvent{Objective: o})
+	case protocol.ScoreboardTypeDelete:
+		if o == nil {
+			return
+		}
+		delete(w.Objectives, t.Name)
+		for position, name := range w.DisplayedObjectives {
+			if name == t.Name {
+				delete(w.DisplayedObjectives, position)
+				s.emit(&DisplayedObjectiveChangedEvent{Position: position})
+			}
+		}
+		s.emit(&ObjectiveChangedEvent{Objective: o, Removed: true})
+	}
+}
+
+// Removing a score removes the player's scores for every objective.
+func (s *Simulator) handleUpdateScore(t *protocol.UpdateScore) {
+	w := s.World
+	if t.Type == protocol.ScoreTypeDelete {
+		for _, o := range w.Objectives {
+			if _, ok := o.Scores[t.ItemName]; ok {
+				delete(o.Scores, t.ItemName)
+				s.emit(&ScoreChangedEvent{Objective: o, Score: Score{Player: t.ItemName}, Removed: true})
+			}
+		}
+		return
+	}
+	o := w.Objectives[t.ScoreName]
+	if o == nil {
+		s.Logger.Printf("Score for unknown objective %q", t.ScoreName)
+		return
+	}
+	o.Scores[t.ItemName] = t.Value
+	s.emit(&ScoreChangedEvent{Objective: o, Score: Score{Player: t.ItemName, Value: t.Value}})
+}
+
+func (s *Simulator) handleDisplayScoreboard(t *protocol.DisplayScoreboard) {
+	w := s.World
+	if t.Name == "" {
+		delete(w.DisplayedObjectives, t.Position)
+	} else {
+		w.DisplayedObjectives[t.Position] = t.Name
+	}
+	s.emit(&DisplayedObjectiveChangedEvent{Position: t.Position, Objective: w.Objectives[t.Name]})
+}
+
+func (s *Simulator) handleTeams(t *protocol.Teams) {
+	w := s.World
+	team := w.Teams[t.ID]
+	if team == nil && t.Type != protocol.TeamCreate {
+		s.Logger.Printf("Update of unknown team %q", t.ID)
+		return
+	}
+	event := &TeamChangedEvent{Team: team}
+	switch t.Type {
+	case protocol.TeamCreate:
+		team = &Team{Name: t.ID, Players: make(map[string]bool)}
+		w.Teams[t.ID] = team
+		event.Team = team
+		fallthrough
+	case protocol.TeamUpdate:
+		team.DisplayName = t.Name
+		team.Prefix = t.Prefix
+		team.Suffix = t.Suffix
+		team.FriendlyFire = t.FriendlyFire&protocol.TeamFriendlyFireOn != 0
+		team.SeesInvisibleTeammates = t.FriendlyFire&seeInvisibleTeammatesFlag != 0
+	case protocol.TeamDelete:
+		delete(w.Teams, t.ID)
+		event.Removed = true
+	}
+	switch t.Type {
+	case protocol.TeamCreate, protocol.TeamPlayerAdd:
+		for _, player := range t.PlayersDelta {
+			// players are on one team at a time
+			if old := w.TeamOf(player); old != nil && old != team {
+				delete(old.Players, player)
+				s.emit(&TeamChangedEvent{Team: old, Left: []string{player}})
+			}
+			team.Players[player] = true
+		}
+		event.Joined = t.PlayersDelta
+	case protocol.TeamPlayerDelete:
+		for _, player := range t.PlayersDelta {
+			delete(team.Players, player)
+		}
+		event.Left = t.PlayersDelta
+	}
+	s.emit(event)
+}
@@ -0,0 +1,149 @@
+package simulator
+
+import (
+	. "github.com/jeffh/goexpect"
+	"mc/protocol"
+	"testing"
+)
+
+func createScoreboardSimulator() (*Simulator, *[]interface{}) {
+	s, _, events := createSimulator()
+	*events = (*events)[:0]
+	return s, events
+}
+
+func TestSimulatorTracksObjectivesAndScores(t *testing.T) {
+	it := NewIt(t)
+	s, events := createScoreboardSimulator()
+	s.ProcessMessage(&protocol.ScoreboardObjective{Name: "kills", Value: "Kills", Type: protocol.ScoreboardTypeCreate})
+	s.ProcessMessage(&protocol.UpdateScore{ItemName: "Steve", ScoreName: "kills", Value: 3})
+	s.ProcessMessage(&protocol.UpdateScore{ItemName: "Alex", ScoreName: "kills", Value: 5})
+	s.ProcessMessage(&protocol.UpdateScore{ItemName: "Steve", ScoreName: "kills", Value: 4})
+
+	kills := s.World.Objective("kills")
+	it.Expects(kills.DisplayName, ToEqual, "Kills")
+	it.Expects(kills.SortedScores(), ToEqual, []Score{{"Alex", 5}, {"Steve", 4}})
+	score, ok := s.World.Score("kills", "Steve")
+	it.Expects(ok, ToBeTrue)
+	it.Expects(score, ToEqual, int32(4))
+	_, ok = s.World.Score("deaths", "Steve")
+	it.Expects(ok, Not(ToBeTrue))
+	it.Expects(*events, ToEqual, []interface{}{
+		&ObjectiveChangedEvent{Objective: kills},
+		&ScoreChangedEvent{Objective: kills, Score: Score{"Steve", 3}},
+		&ScoreChangedEvent{Objective: kills, Score: Score{"Alex", 5}},
+		&ScoreChangedEvent{Objective: kills, Score: Score{"Steve", 4}},
+	})
+
+	s.ProcessMessage(&protocol.ScoreboardObjective{Name: "kills", Value: "Frags", Type: protocol.ScoreboardTypeUpdate})
+	it.Expects(s.World.Objective("kills"), ToEqual, kills)
+	it.Expects(kills.DisplayName, ToEqual, "Frags")
+	it.Expects(kills.Scores, ToBeLengthOf, 2)
+}
+
+func TestSimulatorRemovesAllScoresOfAPlayer(t *testing.T) {
+	it := NewIt(t)
+	s, events := createScoreboardSimulator()
+	s.ProcessMessage(&protocol.ScoreboardObjective{Name: "kills", Type: protocol.ScoreboardTypeCreate})
+	s.ProcessMessage(&protocol.ScoreboardObjective{Name: "deaths", Type: protocol.ScoreboardTypeCreate})
+	s.ProcessMessage(&protocol.UpdateScore{ItemName: "Steve", ScoreName: "kills", Value: 3})
+	s.ProcessMessage(&protocol.UpdateScore{ItemName: "Steve", ScoreName: "deaths", Value: 1})
+	s.ProcessMessage(&protocol.UpdateScore{ItemName: "Alex", ScoreName: "kills", Value: 2})
+	*events = (*events)[:0]
+
+	s.ProcessMessage(&protocol.UpdateScore{ItemName: "Steve", Type: protocol.ScoreTypeDelete})
+	it.Expects(s.World.Objective("kills").Scores, ToEqual, map[string]int32{"Alex": 2})
+	it.Expects(s.World.Objective("deaths").Scores, ToBeEmpty)
+	it.Expects(*events, ToBeLengthOf, 2)
+	it.Expects((*events)[0].(*ScoreChangedEvent).Removed, ToBeTrue)
+}
+
+func TestSimulatorShowsObjectivesInTheSidebar(t *testing.T) {
+	it := NewIt(t)
+	s, events := createScoreboardSimulator()
+	it.Expects(s.World.Sidebar(), ToBeEmpty)
+	s.ProcessMessage(&protocol.ScoreboardObjective{Name: "game", Value: "Minigame", Type: protocol.ScoreboardTypeCreate})
+	for i, line := range []string{"Red: 3", "Blue: 2", "Time left", "a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l", "m"} {
+		s.ProcessMessage(&protocol.UpdateScore{ItemName: line, ScoreName: "game", Value: int32(20 - i)})
+	}
+	*events = (*events)[:0]
+	s.ProcessMessage(&protocol.DisplayScoreboard{Position: protocol.ScoreboardPositionSidebar, Name: "game"})
+
+	game := s.World.Objective("game")
+	it.Expects(s.World.DisplayedObjective(protocol.ScoreboardPositionSidebar), ToEqual, game)
+	it.Expects(s.World.DisplayedObjective(protocol.ScoreboardPositionList), ToBeNil)
+	sidebar := s.World.Sidebar()
+	it.Expects(sidebar, ToBeLengthOf, SidebarSize)
+	it.Expects(sidebar[:3], ToEqual, []Score{{"Red: 3", 20}, {"Blue: 2", 19}, {"Time left", 18}})
+	it.Expects(*events, ToEqual, []interface{}{
+		&DisplayedObjectiveChangedEvent{Position: protocol.ScoreboardPositionSidebar, Objective: game},
+	})
+
+	s.ProcessMessage(&protocol.ScoreboardObjective{Name: "game", Type: protocol.ScoreboardTypeDelete})
+	it.Expects(s.World.Sidebar(), ToBeEmpty)
+	it.Expects(*events, ToEqual, []interface{}{
+		&DisplayedObjectiveChangedEvent{Position: protocol.ScoreboardPositionSidebar, Objective: game},
+		&DisplayedObjectiveChangedEvent{Position: protocol.ScoreboardPositionSidebar},
+		&ObjectiveChangedEvent{Objective: game, Removed: true},
+	})
+}
+
+func TestSimulatorTracksTeams(t *testing.T) {
+	it := NewIt(t)
+	s, events := createScoreboardSimulator()
+	s.ProcessMessage(&protocol.Teams{ID: "red", Type: protocol.TeamCreate, Name: "Red", Prefix: "§c", Suffix: "§r",
+		FriendlyFire: protocol.TeamFriendlyFireShowFriendlyInvisible, PlayersDelta: []string{"MCBot", "Steve"}})
+	s.ProcessMessage(&protocol.Teams{ID: "blue", Type: protocol.TeamCreate, Name: "Blue", PlayersDelta: []string{"Alex"}})
+
+	red := s.World.Teams["red"]
+	it.Expects(red, ToEqual, &Team{
+		Name:                   "red",
+		DisplayName:            "Red",
+		Prefix:                 "§c",
+		Suffix:                 "§r",
+		FriendlyFire:           true,
+		SeesInvisibleTeammates: true,
+		Players:                map[string]bool{"MCBot": true, "Steve": true},
+	})
+	it.Expects(red.FormatName("Steve"), ToEqual, "§cSteve§r")
+	it.Expects(s.World.TeamOf("Steve"), ToEqual, red)
+	it.Expects(s.World.TeamOf("Notch"), ToBeNil)
+	it.Expects(s.World.AreTeammates("MCBot", "Steve"), ToBeTrue)
+	it.Expects(s.World.AreTeammates("MCBot", "Alex"), Not(ToBeTrue))
+	it.Expects(s.World.IsProtectedTeammate("Steve"), Not(ToBeTrue))
+
+	s.ProcessMessage(&protocol.Teams{ID: "red", Type: protocol.TeamUpdate, Name: "Reds", FriendlyFire: protocol.TeamFriendlyFireOff})
+	it.Expects(red.DisplayName, ToEqual, "Reds")
+	it.Expects(red.Prefix, ToEqual, "")
+	it.Expects(s.World.IsProtectedTeammate("Steve"), ToBeTrue)
+
+	*events = (*events)[:0]
+	blue := s.World.Teams["blue"]
+	s.ProcessMessage(&protocol.Teams{ID: "blue", Type: protocol.TeamPlayerAdd, PlayersDelta: []string{"Steve"}})
+	it.Expects(s.World.TeamOf("Steve"), ToEqual, blue)
+	it.Expects(*events, ToEqual, []interface{}{
+		&TeamChangedEvent{Team: red, Left: []string{"Steve"}},
+		&TeamChangedEvent{Team: blue, Joined: []string{"Steve"}},
+	})
+
+	s.ProcessMessage(&protocol.Teams{ID: "blue", Type: protocol.TeamPlayerDelete, PlayersDelta: []string{"Alex"}})
+	it.Expects(blue.Players, ToEqual, map[string]bool{"Steve": true})
+	s.ProcessMessage(&protocol.Teams{ID: "blue", Type: protocol.TeamDelete})
+	it.Expects(s.World.Teams, ToEqual, map[string]*Team{"red": red})
+	it.Expects((*events)[len(*events)-1], ToEqual, &TeamChangedEvent{Team: blue, Removed: true})
+}
+
+func TestCombatSparesTeammatesWithoutFriendlyFire(t *testing.T) {
+	it := NewIt(t)
+	s, outbox := createStandingSimulator(nil)
+	spawnPlayer(s, 31, "Steve", 2.5, 4)
+	s.ProcessMessage(&protocol.Teams{ID: "red", Type: protocol.TeamCreate, PlayersDelta: []string{"MCBot", "Steve"}})
+	s.FightAttackers()
+	s.ProcessMessage(&protocol.UpdateHealth{Health: 17})
+	_, targets := tickCombat(s, outbox, 20)
+	it.Expects(targets, ToBeEmpty)
+
+	s.ProcessMessage(&protocol.Teams{ID: "red", Type: protocol.TeamUpdate, FriendlyFire: protocol.TeamFriendlyFireOn})
+	_, targets = tickCombat(s, outbox, 20)
+	it.Expects(targets, Not(ToBeEmpty))
+}
@@ -2,9 +2,6 @@ package simulator
 
 import (
 	"ax"
-	"bytes"
-	"compress/zlib"
-	"fmt"
 	"mc/protocol"
 	"smpm"
 )
@@ -40,24 +37,72 @@ func (w *MapChunkBulkWrapper) IsGroundUpContinuous() bool {
 type Simulator struct {
 	World  *World
 	Logger ax.WrapLogger
+	Outbox chan<- interface{} // where packets to the server are sent
+
+	// Immediately asks to respawn when the current player dies.
+	AutoRespawn bool
+	// Eats food from the inventory when the current player is hungry.
+	AutoEat bool
+
+	// How the current player moves on each Tick.
+	Controls Controls
+	// Walks the current player to a goal by changing the Controls.
+	Navigator *Navigator
+	// Fights other entities.
+	Combat *Combat
+
+	listeners []Listener
+	physics   physicsState
+	digging   diggingState
+	placing   []*pendingPlacement
+	eating    eatingState
 }
 
 func NewSimulator(logger ax.Logger) *Simulator {
+	world := NewWorld()
 	return &Simulator{
-		World:  NewWorld(),
-		Logger: ax.Wrap(ax.Use(logger), ax.NewPrefixLogger("[simulator] ")),
+		World:     world,
+		Logger:    ax.Wrap(ax.Use(logger), ax.NewPrefixLogger("[simulator] ")),
+		Navigator: NewNavigator(NewPathfinder(world)),
+		Combat:    NewCombat(),
 	}
 }
 
+// Advances the simulation by one game tick. Call it every 50ms.
+func (s *Simulator) Tick() {
+	s.tickEffects()
+	s.tickEating()
+	s.tickCombat()
+	s.tickNavigation()
+	s.tickPhysics()
+	s.tickDigging()
+	s.tickPlacing()
+}
+
 func (s *Simulator) ProcessMessage(v interface{}) {
 	switch t := v.(type) {
 	case *protocol.LoginRequest:
-		s.World.CurrentPlayer.Entity = s.World.NewEntityWithID(t.EntityID)
+		s.World.CurrentPlayer.Entity = s.spawn(t.EntityID, PlayerEntity)
+		s.World.CurrentPlayer.Entity.Name = s.World.CurrentPlayer.Name
 		s.World.LevelType = t.LevelType
 		s.World.GameMode = t.GameMode
 		s.World.GameDimension = t.Dimension
 		s.World.GameDifficulty = t.Difficulty
 		s.World.CurrentPlayer.GameDifficulty = t.Difficulty
+	case *protocol.Respawn:
+		s.handleRespawn(t)
+	case *protocol.UpdateHealth:
+		s.handleUpdateHealth(t)
+	case *protocol.EntityStatus:
+		s.handleEntityStatus(t)
+	case *protocol.SetExperience:
+		s.handleSetExperience(t)
+	case *protocol.EntityEffect:
+		s.handleEntityEffect(t)
+	case *protocol.RemoveEntityEffect:
+		s.handleRemoveEntityEffect(t)
+	case *protocol.ChatMessage:
+		s.handleChatMessage(t)
 	case *protocol.SpawnPosition:
 		s.World.CurrentPlayer.Entity.Position.Set(float64(t.X), float64(t.Y), float64(t.Z))
 	case *protocol.PlayerAbilities:
@@ -88,40 +133,43 @@ func (s *Simulator) ProcessMessage(v interface{}) {
 			Ping:   t.Ping,
 		}
 	case *protocol.PlayerPositionLookForClient:
-		s.World.CurrentPlayer.Entity.Position.Set(t.X, t.Y, t.Z)
-		s.World.CurrentPlayer.Entity.Facing.Set(t.Yaw, t.Pitch)
-		s.World.CurrentPlayer.Stance = t.Stance
-		s.World.CurrentPlayer.IsFlying = !t.IsOnGround
+		s.handlePlayerPositionLook(t)
+	case *protocol.ScoreboardObjective:
+		s.handleScoreboardObjective(t)
+	case *protocol.UpdateScore:
+		s.handleUpdateScore(t)
+	case *protocol.DisplayScoreboard:
+		s.handleDisplayScoreboard(t)
+	case *protocol.Teams:
+		s.handleTeams(t)
+	case *protocol.OpenWindow:
+		s.handleOpenWindow(t)
+	case *protocol.CloseWindow:
+		s.handleCloseWindow(t)
 	case *protocol.SetWindowItems:
-		if t.WindowID == protocol.WindowTypeInventory {
-			s.World.CurrentPlayer.Inventory = t.Slots
-		} else {
-			panic(fmt.Errorf("Unknown window id: %d", t.WindowID))
-		}
+		s.handleSetWindowItems(t)
 	case *protocol.SetSlot:
-		if t.IsHeld() {
-			// not sure what to do about this...
-		}
+		s.handleSetSlot(t)
+	case *protocol.UpdateWindowProperty:
+		s.handleUpdateWindowProperty(t)
+	case *protocol.ConfirmTransaction:
+		s.handleConfirmTransaction(t)
 	case *protocol.MapChunkBulk:
-		buffer := bytes.NewBuffer(t.CompressedData)
-		reader, err := zlib.NewReader(buffer)
-		if err != nil {
-			panic(err)
-		}
-		file := smpm.NewFile(reader, &MapChunkBulkWrapper{MapChunkBulk: t}, s.Logger.WrappedLogger())
-		columns, err := file.Parse()
-		if err != nil {
-			panic(err)
-		}
-		fmt.Printf("Columns: %d\n", len(columns))
-	case *protocol.SpawnObject:
-		entity := s.World.NewEntityWithID(t.EntityID)
-		entity.Position.Set(float64(t.X), float64(t.Y), float64(t.Z))
-		if t.HasVelocity() {
-			entity.Velocity.Set(float64(t.XVelocity), float64(t.YVelocity), float64(t.ZVelocity))
-		}
-		entity.Facing.Set(float32(t.Yaw), float32(t.Pitch))
-		entity.OwnerID = t.OwnerEntityID
-		entity.Type = t.Type
+		s.handleMapChunkBulk(t)
+	case *protocol.ChunkData:
+		s.handleChunkData(t)
+	case *protocol.BlockChange:
+		s.handleBlockChange(t)
+	case *protocol.MultiBlockChange:
+		s.handleMultiBlockChange(t)
+	case *protocol.Explosion:
+		s.handleExplosion(t)
+	case *protocol.SpawnObject, *protocol.SpawnMob, *protocol.SpawnNamedEntity,
+		*protocol.SpawnDroppedItem, *protocol.SpawnPainting, *protocol.SpawnExperienceOrb,
+		*protocol.EntityRelativeMove, *protocol.EntityLook, *protocol.EntityLookRelativeMove,
+		*protocol.EntityTeleport, *protocol.EntityHeadLook, *protocol.EntityVelocity,
+		*protocol.AttachEntity, *protocol.EntityEquipment, *protocol.SetEntityMetadata,
+		*protocol.DestroyEntity:
+		s.handleEntityPacket(t)
 	}
 }
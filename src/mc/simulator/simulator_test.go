@@ -0,0 +1,121 @@
+package simulator
+
+import (
+	. "github.com/jeffh/goexpect"
+	"mc/protocol"
+	"reflect"
+	"testing"
+)
+
+func createSimulator() (*Simulator, chan interface{}, *[]interface{}) {
+	outbox := make(chan interface{}, 100)
+	events := make([]interface{}, 0)
+	s := NewSimulator(nil)
+	s.Outbox = outbox
+	s.Listen(func(event interface{}) {
+		events = append(events, event)
+	})
+	s.World.CurrentPlayer.Name = "MCBot"
+	s.ProcessMessage(&protocol.LoginRequest{EntityID: 7})
+	s.ProcessMessage(&protocol.UpdateHealth{Health: 20})
+	return s, outbox, &events
+}
+
+// Returns true if the value has the type of one of the examples, which
+// are typed nils like (*DiedEvent)(nil).
+func isOneOfTypes(v interface{}, examples []interface{}) bool {
+	for _, e := range examples {
+		if reflect.TypeOf(v) == reflect.TypeOf(e) {
+			return true
+		}
+	}
+	return false
+}
+
+// Records the events the simulator emits of the given types, like
+// (*DiedEvent)(nil).
+func recordEvents(s *Simulator, types ...interface{}) *[]interface{} {
+	events := make([]interface{}, 0)
+	s.Listen(func(event interface{}) {
+		if isOneOfTypes(event, types) {
+			events = append(events, event)
+		}
+	})
+	return &events
+}
+
+// Empties the outbox, returning the packets of the given types, like
+// (*protocol.ClickWindow)(nil).
+func sentPackets(outbox chan interface{}, types ...interface{}) []interface{} {
+	sent := make([]interface{}, 0)
+	for len(outbox) > 0 {
+		if p := <-outbox; isOneOfTypes(p, types) {
+			sent = append(sent, p)
+		}
+	}
+	return sent
+}
+
+func TestSimulatorEmitsDeathWithTheLastDamage(t *testing.T) {
+	s, outbox, events := createSimulator()
+	s.ProcessMessage(&protocol.UpdateHealth{Health: 6})
+	s.ProcessMessage(&protocol.ChatMessage{
+		Message: `{"translate":"death.attack.mob","using":["MCBot","Zombie"]}`,
+	})
+	s.ProcessMessage(&protocol.UpdateHealth{Health: 0})
+
+	Expect(t, s.World.CurrentPlayer.IsDead, ToBeTrue)
+	Expect(t, *events, ToBeLengthOf, 3)
+	Expect(t, (*events)[1], ToEqual, &DamageTakenEvent{Amount: 6, Health: 0})
+	death := (*events)[2].(*DeathEvent)
+	Expect(t, death.LastDamage, ToEqual, DamageInfo{
+		Amount:   6,
+		Cause:    "death.attack.mob",
+		Attacker: "Zombie",
+		Message:  `{"translate":"death.attack.mob","using":["MCBot","Zombie"]}`,
+	})
+	// not auto-respawning by default
+	Expect(t, len(outbox), ToEqual, 0)
+}
+
+func TestSimulatorIgnoresOtherPlayersDeaths(t *testing.T) {
+	s, _, events := createSimulator()
+	s.ProcessMessage(&protocol.ChatMessage{
+		Message: `{"translate":"death.attack.mob","using":["Steve","Zombie"]}`,
+	})
+	s.ProcessMessage(&protocol.EntityStatus{EntityID: 8, Status: protocol.EntityStatusDead})
+	Expect(t, s.World.CurrentPlayer.LastDamage, ToEqual, DamageInfo{})
+	Expect(t, *events, ToBeEmpty)
+}
+
+func TestSimulatorOnlyDiesOnce(t *testing.T) {
+	s, _, events := createSimulator()
+	s.ProcessMessage(&protocol.EntityStatus{EntityID: 7, Status: protocol.EntityStatusDead})
+	s.ProcessMessage(&protocol.UpdateHealth{Health: 0})
+	Expect(t, *events, ToBeLengthOf, 1)
+}
+
+func TestSimulatorCanAutoRespawn(t *testing.T) {
+	s, outbox, events := createSimulator()
+	s.AutoRespawn = true
+	s.ProcessMessage(&protocol.UpdateHealth{Health: 0})
+	Expect(t, <-outbox, ToEqual, &protocol.ClientStatus{Payload: protocol.ClientStatusRespawn})
+
+	s.World.NewEntityWithID(99)
+	s.ProcessMessage(&protocol.Respawn{
+		Dimension:  protocol.GameDimensionNether,
+		Difficulty: protocol.GameDifficultyHard,
+		GameMode:   protocol.GameModeCreative,
+		LevelType:  protocol.FlatLevelType,
+	})
+	Expect(t, s.World.CurrentPlayer.IsDead, Not(ToBeTrue))
+	Expect(t, s.World.GameDimension, ToEqual, protocol.GameDimensionNether)
+	Expect(t, s.World.GameDifficulty, ToEqual, protocol.GameDifficultyHard)
+	Expect(t, s.World.GameMode, ToEqual, protocol.GameModeCreative)
+	Expect(t, s.World.LevelType, ToEqual, protocol.LevelType(protocol.FlatLevelType))
+	Expect(t, s.World.Entities, ToEqual, map[int32]*Entity{7: s.World.CurrentPlayer.Entity})
+	Expect(t, (*events)[2], ToEqual, &RespawnEvent{
+		Dimension:        protocol.GameDimensionNether,
+		ChangedDimension: true,
+	})
+}
@@ -0,0 +1,307 @@
+package simulator
+
+import (
+	"mc/protocol"
+)
+
+// The window ID of the current player's inventory, which is always open.
+const InventoryWindowID int8 = 0
+
+// Slots of the player's inventory that every window ends with.
+const (
+	MainInventorySize = 27
+	HotbarSize        = 9
+)
+
+// A group of a window's slots that serve the same purpose.
+type SlotRegion int
+
+const (
+	NoSlotRegion        SlotRegion = iota // for slots outside of the window
+	ContainerSlots                        // of the opened block, horse or villager
+	CraftingResultSlots                   // where crafted items are taken from
+	CraftingSlots                         // the crafting grid
+	ArmorSlots                            // helmet, chestplate, leggings and boots
+	MainInventorySlots
+	HotbarSlots
+)
+
+func (r SlotRegion) String() string {
+	switch r {
+	case ContainerSlots:
+		return "container"
+	case CraftingResultSlots:
+		return "crafting result"
+	case CraftingSlots:
+		return "crafting"
+	case ArmorSlots:
+		return "armor"
+	case MainInventorySlots:
+		return "main inventory"
+	case HotbarSlots:
+		return "hotbar"
+	}
+	return "none"
+}
+
+// The slots of windows that come before the player's inventory, by
+// window type. Chests and horses say how many they have when opened.
+var containerSizes = map[protocol.WindowType]int{
+	protocol.WindowTypeWorkbench:        10,
+	protocol.WindowTypeFurnance:         3,
+	protocol.WindowTypeDispenser:        9,
+	protocol.WindowTypeEnchantmentTable: 1,
+	protocol.WindowTypeBrewingStand:     4,
+	protocol.WindowTypeTrade:            3,
+	protocol.WindowTypeBeacon:           1,
+	protocol.WindowTypeAnvil:            3,
+	protocol.WindowTypeHopper:           5,
+	protocol.WindowTypeDropper:          9,
+}
+
+// An inventory window, such as the player's inventory or an opened
+// chest. Its slots are the container's slots followed by the player's
+// main inventory and hotbar.
+type Window struct {
+	ID               int8
+	Type             protocol.WindowType // unused by the inventory
+	Title            string
+	UseProvidedTitle bool
+	EntityID         int32 // of the horse, for horse windows
+	Slots            []protocol.Slot
+	Properties       map[int16]int16 // such as furnace progress or enchantment levels
+
+	actionNumber int16   // of the last click
+	dragSlots    []int16 // while dragging
+	dragEvenly   bool
+}
+
+// Creates the current player's inventory window with every slot empty.
+func NewInventoryWindow() *Window {
+	return newWindow(InventoryWindowID, 0, 9)
+}
+
+func newWindow(id int8, t protocol.WindowType, containerSize int) *Window {
+	w := &Window{
+		ID:         id,
+		Type:       t,
+		Slots:      make([]protocol.Slot, containerSize+MainInventorySize+HotbarSize),
+		Properties: make(map[int16]int16),
+	}
+	for i := range w.Slots {
+		w.Slots[i] = protocol.EmptySlot
+	}
+	return w
+}
+
+func (w *Window) IsInventory() bool {
+	return w.ID == InventoryWindowID
+}
+
+// Returns the number of slots before the player's main inventory. For
+// the inventory window, these are the crafting and armor slots.
+func (w *Window) ContainerSize() int {
+	return len(w.Slots) - MainInventorySize - HotbarSize
+}
+
+// Returns the item in the slot, or an empty slot if the window doesn't
+// have it.
+func (w *Window) Slot(i int16) protocol.Slot {
+	if i < 0 || int(i) >= len(w.Slots) {
+		return protocol.EmptySlot
+	}
+	return w.Slots[i]
+}
+
+// Returns which region of the window the slot belongs to.
+func (w *Window) Region(i int16) SlotRegion {
+	size := w.ContainerSize()
+	switch {
+	case i < 0 || int(i) >= len(w.Slots):
+		return NoSlotRegion
+	case int(i) >= size+MainInventorySize:
+		return HotbarSlots
+	case int(i) >= size:
+		return MainInventorySlots
+	}
+
+	crafting := w.IsInventory() || w.Type == protocol.WindowTypeWorkbench
+	switch {
+	case crafting && i == 0:
+		return CraftingResultSlots
+	case w.IsInventory() && i >= 5:
+		return ArmorSlots
+	case crafting:
+		return CraftingSlots
+	}
+	return ContainerSlots
+}
+
+// Returns the indices of the window's slots in the region, in order.
+func (w *Window) SlotsIn(r SlotRegion) []int16 {
+	slots := make([]int16, 0)
+	for i := range w.Slots {
+		if w.Region(int16(i)) == r {
+			slots = append(slots, int16(i))
+		}
+	}
+	return slots
+}
+
+// Returns the index of the window's slot for the hotbar slot, numbered
+// from 0 to 8.
+func (w *Window) HotbarSlot(i int16) int16 {
+	return int16(w.ContainerSize()+MainInventorySize) + i
+}
+
+// Returns the inventory window's index of a main inventory or hotbar
+// slot of this window.
+func (w *Window) InventorySlot(i int16) (int16, bool) {
+	switch w.Region(i) {
+	case MainInventorySlots, HotbarSlots:
+		return i - int16(w.ContainerSize()) + 9, true
+	}
+	return 0, false
+}
+
+//////////////////////////////////////////////////////////
+
+// Emitted when the server opens a window, before its items are sent.
+type WindowOpenedEvent struct {
+	Window *Window
+}
+
+// Emitted when a window other than the inventory closes.
+type WindowClosedEvent struct {
+	Window *Window
+}
+
+// Emitted when the server sends all of a window's items.
+type WindowItemsEvent struct {
+	Window *Window
+}
+
+// Emitted when the server changes a single slot of a window.
+type SlotChangedEvent struct {
+	Window   *Window
+	Slot     int16
+	Old, New protocol.Slot
+}
+
+//////////////////////////////////////////////////////////
+
+// Returns the window with the given ID, if it's open.
+func (s *Simulator) Window(id int8) *Window {
+	player := &s.World.CurrentPlayer
+	if id == InventoryWindowID {
+		return player.Inventory
+	}
+	if player.Window != nil && player.Window.ID == id {
+		return player.Window
+	}
+	return nil
+}
+
+// Closes the opened window, or the crafting grid of the inventory if
+// no other window is open.
+func (s *Simulator) CloseWindow() {
+	id := InventoryWindowID
+	if w := s.World.CurrentPlayer.Window; w != nil {
+		id = w.ID
+	}
+	s.send(&protocol.CloseWindow{WindowID: id})
+	s.closeWindow()
+}
+
+func (s *Simulator) closeWindow() {
+	player := &s.World.CurrentPlayer
+	// the server drops the held item
+	player.Cursor = protocol.EmptySlot
+	if w := player.Window; w != nil {
+		player.Window = nil
+		s.emit(&WindowClosedEvent{Window: w})
+	}
+}
+
+func (s *Simulator) handleOpenWindow(t *protocol.OpenWindow) {
+	size, ok := containerSizes[t.InventoryType]
+	if !ok {
+		size = int(t.NumSlots)
+	}
+	w := newWindow(t.WindowID, t.InventoryType, size)
+	w.Title = t.Title
+	w.UseProvidedTitle = t.UseProvidedTitle
+	w.EntityID = t.EntityID
+	if s.World.CurrentPlayer.Window != nil {
+		s.closeWindow()
+	}
+	s.World.CurrentPlayer.Window = w
+	s.emit(&WindowOpenedEvent{Window: w})
+}
+
+func (s *Simulator) handleCloseWindow(t *protocol.CloseWindow) {
+	if w := s.World.CurrentPlayer.Window; w != nil && w.ID == t.WindowID {
+		s.closeWindow()
+	}
+}
+
+func (s *Simulator) handleSetWindowItems(t *protocol.SetWindowItems) {
+	w := s.Window(t.WindowID)
+	if w == nil {
+		s.Logger.Printf("Ignoring items of window %d, which isn't open", t.WindowID)
+		return
+	}
+	// the server knows how big the window is better than we do
+	if len(t.Slots) != len(w.Slots) {
+		w.Slots = make([]protocol.Slot, len(t.Slots))
+	}
+	copy(w.Slots, t.Slots)
+	s.syncInventory(w)
+	s.emit(&WindowItemsEvent{Window: w})
+}
+
+func (s *Simulator) handleSetSlot(t *protocol.SetSlot) {
+	if t.IsHeld() {
+		s.World.CurrentPlayer.Cursor = t.Data
+		return
+	}
+	w := s.Window(t.WindowID)
+	if w == nil || t.Slot < 0 || int(t.Slot) >= len(w.Slots) {
+		s.Logger.Printf("Ignoring slot %d of window %d, which isn't open", t.Slot, t.WindowID)
+		return
+	}
+	old := w.Slots[t.Slot]
+	w.Slots[t.Slot] = t.Data
+	if w.IsInventory() {
+		s.copyFromInventory(t.Slot)
+	} else {
+		s.copyToInventory(w, t.Slot)
+	}
+	s.emit(&SlotChangedEvent{Window: w, Slot: t.Slot, Old: old, New: t.Data})
+}
+
+func (s *Simulator) handleUpdateWindowProperty(t *protocol.UpdateWindowProperty) {
+	if w := s.Window(t.WindowID); w != nil {
+		w.Properties[t.Property] = t.Value
+	}
+}
+
+// Keeps the inventory window in sync with the player's slots of the
+// opened window.
+func (s *Simulator) copyToInventory(w *Window, i int16) {
+	if j, ok := w.InventorySlot(i); ok && int(j) < len(s.World.CurrentPlayer.Inventory.Slots) {
+		s.World.CurrentPlayer.Inventory.Slots[j] = w.Slots[i]
+	}
+}
+
+// Keeps the opened window in sync with the player's inventory.
+func (s *Simulator) copyFromInventory(j int16) {
+	w := s.World.CurrentPlayer.Window
+	inventory := s.World.CurrentPlayer.Inventory
+	if w == nil || inventory.Region(j) != MainInventorySlots && inventory.Region(j) != HotbarSlots {
+		return
+	}
+	if i := j - 9 + int16(w.ContainerSize()); int(i) < len(w.Slots) {
+		w.Slots[i] = inventory.Slots[j]
+	}
+}
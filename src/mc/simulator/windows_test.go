@@ -0,0 +1,142 @@
+package simulator
+
+import (
+	. "github.com/jeffh/goexpect"
+	"mc/protocol"
+	"testing"
+)
+
+func slots(size int, items map[int]protocol.Slot) []protocol.Slot {
+	s := make([]protocol.Slot, size)
+	for i := range s {
+		s[i] = protocol.EmptySlot
+	}
+	for i, item := range items {
+		s[i] = item
+	}
+	return s
+}
+
+func TestWindowRegionsOfTheInventory(t *testing.T) {
+	w := NewInventoryWindow()
+	Expect(t, w.Slots, ToBeLengthOf, 45)
+	Expect(t, w.Region(0), ToEqual, CraftingResultSlots)
+	Expect(t, w.SlotsIn(CraftingSlots), ToEqual, []int16{1, 2, 3, 4})
+	Expect(t, w.SlotsIn(ArmorSlots), ToEqual, []int16{5, 6, 7, 8})
+	Expect(t, w.Region(9), ToEqual, MainInventorySlots)
+	Expect(t, w.Region(35), ToEqual, MainInventorySlots)
+	Expect(t, w.Region(36), ToEqual, HotbarSlots)
+	Expect(t, w.Region(44), ToEqual, HotbarSlots)
+	Expect(t, w.Region(45), ToEqual, NoSlotRegion)
+	Expect(t, w.Region(-999), ToEqual, NoSlotRegion)
+}
+
+func TestWindowRegionsOfContainers(t *testing.T) {
+	s, _, _ := createSimulator()
+	s.ProcessMessage(&protocol.OpenWindow{WindowID: 1, InventoryType: protocol.WindowTypeWorkbench, NumSlots: 9})
+	w := s.World.CurrentPlayer.Window
+	Expect(t, w.Slots, ToBeLengthOf, 46)
+	Expect(t, w.Region(0), ToEqual, CraftingResultSlots)
+	Expect(t, w.SlotsIn(CraftingSlots), ToBeLengthOf, 9)
+	Expect(t, w.SlotsIn(ArmorSlots), ToBeEmpty)
+	Expect(t, w.Region(10), ToEqual, MainInventorySlots)
+	Expect(t, w.Region(37), ToEqual, HotbarSlots)
+
+	s.ProcessMessage(&protocol.OpenWindow{WindowID: 2, InventoryType: protocol.WindowTypeChest, NumSlots: 54})
+	w = s.World.CurrentPlayer.Window
+	Expect(t, w.Slots, ToBeLengthOf, 90)
+	Expect(t, w.SlotsIn(ContainerSlots), ToBeLengthOf, 54)
+	j, ok := w.InventorySlot(54)
+	Expect(t, ok, ToBeTrue)
+	Expect(t, j, ToEqual, int16(9))
+	j, ok = w.InventorySlot(89)
+	Expect(t, j, ToEqual, int16(44))
+	_, ok = w.InventorySlot(53)
+	Expect(t, ok, Not(ToBeTrue))
+}
+
+func TestSimulatorOpensWindows(t *testing.T) {
+	s, _, events := createSimulator()
+	s.ProcessMessage(&protocol.OpenWindow{
+		WindowID:      3,
+		InventoryType: protocol.WindowTypeHorse,
+		Title:         "Horse",
+		NumSlots:      2,
+		EntityID:      42,
+	})
+	w := s.World.CurrentPlayer.Window
+	Expect(t, w.ID, ToEqual, int8(3))
+	Expect(t, w.Type, ToEqual, protocol.WindowTypeHorse)
+	Expect(t, w.EntityID, ToEqual, int32(42))
+	Expect(t, w.SlotsIn(ContainerSlots), ToEqual, []int16{0, 1})
+	Expect(t, s.Window(3), ToBe, w)
+	Expect(t, s.Window(4), ToBeNil)
+	Expect(t, (*events)[0], ToEqual, &WindowOpenedEvent{Window: w})
+
+	s.ProcessMessage(&protocol.CloseWindow{WindowID: 3})
+	Expect(t, s.World.CurrentPlayer.Window, ToBeNil)
+	Expect(t, (*events)[1], ToEqual, &WindowClosedEvent{Window: w})
+}
+
+func TestSimulatorTracksChestItems(t *testing.T) {
+	s, _, events := createSimulator()
+	stone := protocol.Slot{ID: 1, Count: 64}
+	dirt := protocol.Slot{ID: 3, Count: 12}
+	s.ProcessMessage(&protocol.OpenWindow{WindowID: 2, InventoryType: protocol.WindowTypeChest, Title: "Chest", NumSlots: 27})
+	s.ProcessMessage(&protocol.SetWindowItems{
+		WindowID: 2,
+		Slots:    slots(63, map[int]protocol.Slot{0: stone, 27: dirt}),
+	})
+
+	w := s.World.CurrentPlayer.Window
+	Expect(t, w.Slot(0), ToEqual, stone)
+	Expect(t, w.Slot(27), ToEqual, dirt)
+	// the player's part of the window is also the inventory
+	Expect(t, s.World.CurrentPlayer.Inventory.Slot(9), ToEqual, dirt)
+	Expect(t, (*events)[1], ToEqual, &WindowItemsEvent{Window: w})
+
+	s.ProcessMessage(&protocol.SetSlot{WindowID: 2, Slot: 62, Data: stone})
+	Expect(t, s.World.CurrentPlayer.Inventory.Slot(44), ToEqual, stone)
+	Expect(t, (*events)[2], ToEqual, &SlotChangedEvent{Window: w, Slot: 62, Old: protocol.EmptySlot, New: stone})
+
+	s.ProcessMessage(&protocol.SetSlot{WindowID: 0, Slot: 36, Data: dirt})
+	Expect(t, w.Slot(54), ToEqual, dirt)
+}
+
+func TestSimulatorTracksTheInventory(t *testing.T) {
+	s, _, _ := createSimulator()
+	sword := protocol.Slot{ID: 276, Count: 1}
+	s.ProcessMessage(&protocol.SetWindowItems{
+		WindowID: 0,
+		Slots:    slots(45, map[int]protocol.Slot{37: sword}),
+	})
+	s.ProcessMessage(&protocol.HeldItemChange{SlotID: 1})
+	Expect(t, s.heldItem(), ToEqual, sword)
+}
+
+func TestSimulatorTracksTheCursor(t *testing.T) {
+	s, outbox, _ := createSimulator()
+	Expect(t, s.World.CurrentPlayer.Cursor, ToEqual, protocol.EmptySlot)
+	s.ProcessMessage(&protocol.SetSlot{WindowID: -1, Slot: -1, Data: protocol.Slot{ID: 1, Count: 3}})
+	Expect(t, s.World.CurrentPlayer.Cursor, ToEqual, protocol.Slot{ID: 1, Count: 3})
+
+	s.ProcessMessage(&protocol.OpenWindow{WindowID: 5, InventoryType: protocol.WindowTypeFurnance, NumSlots: 3})
+	s.CloseWindow()
+	Expect(t, <-outbox, ToEqual, &protocol.CloseWindow{WindowID: 5})
+	Expect(t, s.World.CurrentPlayer.Window, ToBeNil)
+	Expect(t, s.World.CurrentPlayer.Cursor, ToEqual, protocol.EmptySlot)
+}
+
+func TestSimulatorIgnoresItemsOfClosedWindows(t *testing.T) {
+	s, _, events := createSimulator()
+	s.ProcessMessage(&protocol.SetWindowItems{WindowID: 9, Slots: slots(63, nil)})
+	s.ProcessMessage(&protocol.SetSlot{WindowID: 9, Slot: 3, Data: protocol.Slot{ID: 1, Count: 1}})
+	Expect(t, *events, ToBeEmpty)
+}
+
+func TestSimulatorTracksWindowProperties(t *testing.T) {
+	s, _, _ := createSimulator()
+	s.ProcessMessage(&protocol.OpenWindow{WindowID: 5, InventoryType: protocol.WindowTypeFurnance, NumSlots: 3})
+	s.ProcessMessage(&protocol.UpdateWindowProperty{WindowID: 5, Property: 0, Value: 120})
+	Expect(t, s.World.CurrentPlayer.Window.Properties[0], ToEqual, int16(120))
+}
@@ -2,6 +2,7 @@ package simulator
 
 import (
 	"mc/protocol"
+	"smpm"
 )
 
 type Vector3Int struct {
@@ -42,13 +43,42 @@ func (r *RotationFloat) Set(yaw, pitch float32) {
 	r.Pitch = pitch
 }
 
+// What spawned an entity. This determines which of the entity's fields
+// are used.
+type EntityKind int
+
+const (
+	ObjectEntity EntityKind = iota // vehicles, projectiles, items, etc.
+	MobEntity
+	PlayerEntity
+	DroppedItemEntity
+	PaintingEntity
+	ExperienceOrbEntity
+)
+
+// The VehicleID of entities that aren't riding anything.
+const NoVehicle int32 = -1
+
 type Entity struct {
 	ID       int32
+	Kind     EntityKind
 	OwnerID  int32
-	Type     protocol.EntityType
-	Position Vector3Float
-	Velocity Vector3Float
-	Facing   RotationFloat
+	Type     protocol.EntityType // for objects
+	MobType  protocol.MobType    // for mobs
+	Name     string              // the name of players, or the title of paintings
+	Position Vector3Float        // in blocks
+	Velocity Vector3Float        // in blocks per tick
+	Facing   RotationFloat       // in degrees
+	HeadYaw  float32             // in degrees
+
+	VehicleID  int32
+	Equipment  [5]protocol.Slot // held item, then boots, leggings, chestplate and helmet
+	Metadata   map[protocol.EntityMetadataIndex]interface{}
+	Item       protocol.Slot   // for dropped items
+	Direction  int32           // for paintings
+	Experience int16           // for experience orbs
+	IsDead     bool            // while its death animation plays
+	Effects    map[int8]Effect // potion effects by id
 }
 
 type Player struct {
@@ -63,29 +93,41 @@ type CurrentPlayer struct {
 	Stance                    float64
 	HeldItemSlot              int16
 	GameDifficulty            protocol.GameDifficulty
-	Inventory                 []protocol.Slot
+	Inventory                 *Window       // always open
+	Window                    *Window       // the opened container, if any
+	Cursor                    protocol.Slot // the item held by the mouse
 	FlyingSpeed, WalkingSpeed float32
 	IsGhost                   bool // fly mode
 	IsGod                     bool // god mode
-	IsFlying                  bool // is in midair
+	IsOnGround                bool
+	Health                    float32
+	Food                      int16
+	Saturation                float32
+	Experience                Experience
+	IsDead                    bool
+	LastDamage                DamageInfo
 }
 
 type Block struct {
-	Type     byte
-	Metadata []byte // needs type
-	Light    []byte // needs type
-	Skylight []byte // needs type
-	Add      []byte // needs type
-	Biome    []byte // needs type
+	Type     int16 // including the add bits
+	Metadata byte
+	Light    byte
+	Skylight byte
+	Biome    byte
 }
 
 type World struct {
 	CurrentPlayer CurrentPlayer // information about the user-controlled player
 	Players       map[string]Player
 	Entities      map[int32]*Entity
+	Columns       map[smpm.ColumnPoint]*smpm.ChunkColumn
 	AgeOfWorld    int64
 	TimeOfDay     int64
 
+	Objectives          map[string]*Objective                  // by name
+	DisplayedObjectives map[protocol.ScoreboardPosition]string // objective names
+	Teams               map[string]*Team                       // by name
+
 	LevelType        protocol.LevelType // default, flat, or largeBiomes
 	GameMode         protocol.GameMode
 	GameState        protocol.GameState
@@ -97,15 +139,28 @@ type World struct {
 }
 
 func NewWorld() *World {
-	return &World{
+	w := &World{
 		Players:   make(map[string]Player, 0),
 		Entities:  make(map[int32]*Entity, 0),
+		Columns:   make(map[smpm.ColumnPoint]*smpm.ChunkColumn),
 		LevelType: protocol.DefaultLevelType,
+
+		Objectives:          make(map[string]*Objective),
+		DisplayedObjectives: make(map[protocol.ScoreboardPosition]string),
+		Teams:               make(map[string]*Team),
 	}
+	w.CurrentPlayer.Inventory = NewInventoryWindow()
+	w.CurrentPlayer.Cursor = protocol.EmptySlot
+	return w
 }
 
 func (w *World) NewEntityWithID(id int32) *Entity {
-	e := &Entity{ID: id}
+	e := &Entity{
+		ID:        id,
+		VehicleID: NoVehicle,
+		Metadata:  make(map[protocol.EntityMetadataIndex]interface{}),
+		Effects:   make(map[int8]Effect),
+	}
 	w.Entities[e.ID] = e
 	return e
 }
@@ -113,3 +168,13 @@ func (w *World) NewEntityWithID(id int32) *Entity {
 func (w *World) EntityByID(id int32) *Entity {
 	return w.Entities[id]
 }
+
+// Forgets all entities, except the current player's. The server
+// resends the entities nearby after respawning.
+func (w *World) RemoveEntitiesExceptCurrentPlayer() {
+	for id, e := range w.Entities {
+		if e != w.CurrentPlayer.Entity {
+			delete(w.Entities, id)
+		}
+	}
+}
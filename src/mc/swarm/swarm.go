@@ -0,0 +1,379 @@
+// Runs many minecraft clients (bots) in a single process.
+//
+// A Swarm launches bots from a Template, staggering their connections
+// to avoid flooding the server. Each bot is tracked individually and
+// the whole swarm can be queried with Status() or commanded with
+// Broadcast() and Command().
+package swarm
+
+import (
+	"ax"
+	"fmt"
+	"io"
+	"mc"
+	"mc/protocol"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// A function that opens a connection to the given address.
+type Dialer func(address string) (io.ReadWriteCloser, error)
+
+// How long the default Dialer waits for a TCP connection.
+var DialTimeout = 10 * time.Second
+
+func dialTCP(address string) (io.ReadWriteCloser, error) {
+	return net.DialTimeout("tcp", address, DialTimeout)
+}
+
+// Describes how to create each bot in the swarm.
+type Template struct {
+	Host string
+	Port int32
+
+	// Formatted with the bot's index (starting at 0) to produce its
+	// username. eg - "Bot%03d"
+	UsernamePattern string
+	Count           int
+	Stagger         time.Duration // delay between each bot connecting
+	Encrypted       bool
+	MessageBuffer   int
+
+	// The logger shared by all bots. Each bot prefixes its messages
+	// with its username. It's recommended to wrap it with
+	// ax.NewLockedLogger().
+	Logger ax.Logger
+
+	// Optional. Defaults to a TCP connection.
+	Dial Dialer
+	// Optional. Called after the bot's client is created, but before it
+	// connects. Useful for adding middlewares.
+	Setup func(b *Bot)
+	// Optional. Called for every packet the bot receives.
+	Handler func(b *Bot, packet interface{})
+	// Optional. Returns the account used by an Encrypted bot. Defaults
+	// to the bot's username without a password, which only works on
+	// offline-mode servers.
+	Credentials func(b *Bot) *mc.Credentials
+}
+
+func (t *Template) address() string {
+	return net.JoinHostPort(t.Host, strconv.Itoa(int(t.Port)))
+}
+
+//////////////////////////////////////////////////////////
+
+// The connection state of a bot.
+type BotState int
+
+const (
+	BotWaiting BotState = iota // waiting for its turn to connect
+	BotConnecting
+	BotOnline
+	BotDisconnected
+)
+
+func (s BotState) String() string {
+	switch s {
+	case BotWaiting:
+		return "waiting"
+	case BotConnecting:
+		return "connecting"
+	case BotOnline:
+		return "online"
+	case BotDisconnected:
+		return "disconnected"
+	}
+	return "unknown"
+}
+
+// A snapshot of a bot's state.
+type BotStatus struct {
+	Index       int
+	Username    string
+	State       BotState
+	LastError   error
+	Latency     time.Duration // as reported by the server's player list
+	ConnectedAt time.Time
+}
+
+// A single client in the swarm.
+type Bot struct {
+	Index    int
+	Username string
+	Client   *mc.Client
+	Logger   ax.Logger
+
+	mutex       sync.Mutex
+	state       BotState
+	lastError   error
+	latency     time.Duration
+	connectedAt time.Time
+}
+
+func (b *Bot) setState(state BotState) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.state = state
+	if state == BotOnline {
+		b.connectedAt = time.Now()
+	}
+}
+
+func (b *Bot) setError(err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.lastError = err
+}
+
+func (b *Bot) setLatency(latency time.Duration) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.latency = latency
+}
+
+// Returns a snapshot of the bot's state.
+func (b *Bot) Status() BotStatus {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return BotStatus{
+		Index:       b.Index,
+		Username:    b.Username,
+		State:       b.state,
+		LastError:   b.lastError,
+		Latency:     b.latency,
+		ConnectedAt: b.connectedAt,
+	}
+}
+
+// Queues a packet to be sent by the bot. Returns false if the bot is
+// not online or its outbox is full.
+func (b *Bot) Send(packet interface{}) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.state != BotOnline {
+		return false
+	}
+	select {
+	case b.Client.Outbox <- packet:
+		return true
+	default:
+		b.Logger.Printf("Outbox full, dropping %#v", packet)
+		return false
+	}
+}
+
+//////////////////////////////////////////////////////////
+
+// An aggregated snapshot of all the bots in a swarm.
+type Status struct {
+	Bots           []BotStatus
+	Counts         map[BotState]int
+	AverageLatency time.Duration // of online bots
+}
+
+// Manages the lifecycle of many bots.
+type Swarm struct {
+	Template Template
+
+	bots     []*Bot
+	quit     chan bool
+	quitOnce sync.Once
+	wg       sync.WaitGroup
+
+	mutex   sync.Mutex
+	started bool
+}
+
+// Creates a swarm of bots from the template. No bots connect until
+// Start() is called.
+func New(t Template) *Swarm {
+	if t.Dial == nil {
+		t.Dial = dialTCP
+	}
+	bots := make([]*Bot, t.Count)
+	for i := range bots {
+		username := fmt.Sprintf(t.UsernamePattern, i)
+		bots[i] = &Bot{
+			Index:    i,
+			Username: username,
+			Logger:   ax.Wrap(ax.Use(t.Logger), ax.NewPrefixLogger("["+username+"] ")),
+		}
+	}
+	return &Swarm{
+		Template: t,
+		bots:     bots,
+		quit:     make(chan bool),
+	}
+}
+
+// Returns all the bots in the swarm.
+func (s *Swarm) Bots() []*Bot {
+	return s.bots
+}
+
+// Starts connecting the bots. Each bot waits Stagger longer than the
+// previous bot before connecting. Start does not block.
+func (s *Swarm) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.started {
+		panic("Swarm has already been started")
+	}
+	s.started = true
+	for _, bot := range s.bots {
+		s.wg.Add(1)
+		go s.run(bot, time.Duration(bot.Index)*s.Template.Stagger)
+	}
+}
+
+// Disconnects all the bots and waits for them to finish. It is safe to
+// call Stop more than once.
+func (s *Swarm) Stop() {
+	s.quitOnce.Do(func() {
+		close(s.quit)
+	})
+	s.wg.Wait()
+}
+
+func (s *Swarm) run(b *Bot, delay time.Duration) {
+	defer s.wg.Done()
+	defer b.setState(BotDisconnected)
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-s.quit:
+			return
+		}
+	}
+
+	b.setState(BotConnecting)
+	b.Logger.Printf("Connecting to %s", s.Template.address())
+	stream, err := s.Template.Dial(s.Template.address())
+	if err != nil {
+		b.Logger.Printf("Failed to dial: %s", err)
+		b.setError(err)
+		return
+	}
+
+	b.Client = mc.NewClient(stream, s.Template.MessageBuffer, b.Logger)
+	defer b.Client.Close()
+
+	// closing the client unblocks logging in if the server never answers
+	done := make(chan bool)
+	defer close(done)
+	go func() {
+		select {
+		case <-s.quit:
+			b.Client.Close()
+		case <-done:
+		}
+	}()
+	if s.Template.Setup != nil {
+		s.Template.Setup(b)
+	}
+
+	var login *protocol.LoginRequest
+	if s.Template.Encrypted {
+		login, err = b.Client.ConnectEncrypted(s.Template.Host, s.Template.Port, s.credentials(b))
+	} else {
+		login, err = b.Client.ConnectUnencrypted(s.Template.Host, s.Template.Port, b.Username)
+	}
+	if err != nil {
+		b.setError(err)
+		return
+	}
+
+	go b.Client.ProcessInbox()
+	go b.Client.ProcessOutbox()
+	b.setState(BotOnline)
+	s.handle(b, login)
+
+	for {
+		select {
+		case p := <-b.Client.Inbox:
+			s.handle(b, p)
+		case <-b.Client.Exit:
+			b.Logger.Printf("Connection closed")
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+func (s *Swarm) credentials(b *Bot) *mc.Credentials {
+	if s.Template.Credentials != nil {
+		return s.Template.Credentials(b)
+	}
+	return &mc.Credentials{Username: b.Username}
+}
+
+func (s *Swarm) handle(b *Bot, packet interface{}) {
+	switch t := packet.(type) {
+	case *protocol.KeepAlive:
+		if b.Client.AutoKeepAlive {
+			b.Send(t)
+		}
+	case *protocol.PlayerListItem:
+		if t.Name == b.Username {
+			b.setLatency(time.Duration(t.Ping) * time.Millisecond)
+		}
+	case *protocol.Disconnect:
+		b.setError(fmt.Errorf("Disconnected: %s", t.Reason))
+	}
+	if s.Template.Handler != nil {
+		s.Template.Handler(b, packet)
+	}
+}
+
+// Sends the packet to every online bot that the filter accepts. A nil
+// filter accepts all bots.
+//
+// Returns the number of bots the packet was queued for.
+func (s *Swarm) BroadcastTo(filter func(b *Bot) bool, packet interface{}) int {
+	sent := 0
+	for _, b := range s.bots {
+		if filter != nil && !filter(b) {
+			continue
+		}
+		if b.Send(packet) {
+			sent++
+		}
+	}
+	return sent
+}
+
+// Sends the packet to every online bot.
+func (s *Swarm) Broadcast(packet interface{}) int {
+	return s.BroadcastTo(nil, packet)
+}
+
+// Makes every online bot say the given chat message or command.
+func (s *Swarm) Command(text string) int {
+	return s.Broadcast(&protocol.ChatMessage{Message: text})
+}
+
+// Returns a snapshot of every bot's state.
+func (s *Swarm) Status() Status {
+	status := Status{
+		Bots:   make([]BotStatus, len(s.bots)),
+		Counts: make(map[BotState]int),
+	}
+	var totalLatency time.Duration
+	for i, b := range s.bots {
+		bs := b.Status()
+		status.Bots[i] = bs
+		status.Counts[bs.State]++
+		if bs.State == BotOnline {
+			totalLatency += bs.Latency
+		}
+	}
+	if online := status.Counts[BotOnline]; online > 0 {
+		status.AverageLatency = totalLatency / time.Duration(online)
+	}
+	return status
+}
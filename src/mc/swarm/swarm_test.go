@@ -0,0 +1,174 @@
+package swarm
+
+import (
+	"errors"
+	. "github.com/jeffh/goexpect"
+	"io"
+	"mc/protocol"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// A fake offline mode server that accepts a login and then reports the
+// player's ping. Every chat message it receives afterwards is recorded.
+type fakeServer struct {
+	mutex    sync.Mutex
+	received map[string][]interface{}
+}
+
+func newFakeServer() *fakeServer {
+	return &fakeServer{received: make(map[string][]interface{})}
+}
+
+func (s *fakeServer) Dial(address string) (io.ReadWriteCloser, error) {
+	client, server := net.Pipe()
+	go s.serve(server)
+	return client, nil
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := protocol.NewReader(conn, protocol.ServerPacketMapper, nil, nil)
+	w := protocol.NewWriter(conn, protocol.ServerPacketMapper, nil, nil)
+	p, err := r.ReadPacket()
+	if err != nil {
+		return
+	}
+	handshake := p.(*protocol.Handshake)
+	// net.Pipe is synchronous, so write while the client is sending
+	// its login packets.
+	go func() {
+		w.WritePacket(&protocol.LoginRequest{EntityID: 1})
+		w.WritePacket(&protocol.PlayerListItem{Name: handshake.Username, Online: true, Ping: 42})
+	}()
+	for {
+		p, err := r.ReadPacket()
+		if err != nil {
+			return
+		}
+		if _, ok := p.(*protocol.ChatMessage); ok {
+			s.mutex.Lock()
+			s.received[handshake.Username] = append(s.received[handshake.Username], p)
+			s.mutex.Unlock()
+		}
+	}
+}
+
+func (s *fakeServer) Received(username string) []interface{} {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.received[username]
+}
+
+func eventually(fn func() bool) bool {
+	for i := 0; i < 200; i++ {
+		if fn() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return false
+}
+
+func TestSwarmNamesBotsFromTheTemplate(t *testing.T) {
+	s := New(Template{UsernamePattern: "Bot%02d", Count: 3})
+	Expect(t, s.Bots(), ToBeLengthOf, 3)
+	Expect(t, s.Bots()[0].Username, ToEqual, "Bot00")
+	Expect(t, s.Bots()[2].Username, ToEqual, "Bot02")
+	Expect(t, s.Status().Counts[BotWaiting], ToEqual, 3)
+}
+
+func TestSwarmConnectsBroadcastsAndStops(t *testing.T) {
+	server := newFakeServer()
+	s := New(Template{
+		Host:            "localhost",
+		Port:            25565,
+		UsernamePattern: "Bot%d",
+		Count:           3,
+		Stagger:         time.Millisecond,
+		MessageBuffer:   10,
+		Dial:            server.Dial,
+	})
+	s.Start()
+
+	Expect(t, eventually(func() bool {
+		status := s.Status()
+		return status.Counts[BotOnline] == 3 && status.AverageLatency == 42*time.Millisecond
+	}), ToBeTrue)
+
+	sent := s.BroadcastTo(func(b *Bot) bool { return b.Index > 0 }, &protocol.ChatMessage{Message: "hello"})
+	Expect(t, sent, ToEqual, 2)
+	Expect(t, eventually(func() bool {
+		return len(server.Received("Bot1")) == 1 && len(server.Received("Bot2")) == 1
+	}), ToBeTrue)
+	Expect(t, server.Received("Bot1")[0], ToEqual, &protocol.ChatMessage{Message: "hello"})
+	Expect(t, server.Received("Bot0"), ToBeEmpty)
+
+	s.Stop()
+	status := s.Status()
+	Expect(t, status.Counts[BotDisconnected], ToEqual, 3)
+	Expect(t, s.Command("/list"), ToEqual, 0)
+}
+
+func TestSwarmRecordsDialErrors(t *testing.T) {
+	failure := errors.New("connection refused")
+	s := New(Template{
+		UsernamePattern: "Bot%d",
+		Count:           1,
+		Dial: func(address string) (io.ReadWriteCloser, error) {
+			return nil, failure
+		},
+	})
+	s.Start()
+	Expect(t, eventually(func() bool {
+		return s.Status().Counts[BotDisconnected] == 1
+	}), ToBeTrue)
+	s.Stop()
+	Expect(t, s.Bots()[0].Status().LastError, ToEqual, failure)
+
+	// stopping again does nothing
+	s.Stop()
+}
+
+func TestSwarmStopsBotsThatAreLoggingIn(t *testing.T) {
+	s := New(Template{
+		UsernamePattern: "Bot%d",
+		Count:           1,
+		// the server never reads the handshake
+		Dial: func(address string) (io.ReadWriteCloser, error) {
+			client, _ := net.Pipe()
+			return client, nil
+		},
+	})
+	s.Start()
+	Expect(t, eventually(func() bool {
+		return s.Status().Counts[BotConnecting] == 1
+	}), ToBeTrue)
+
+	stopped := make(chan bool)
+	go func() {
+		s.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop is blocked on a bot that is logging in")
+	}
+	Expect(t, s.Status().Counts[BotDisconnected], ToEqual, 1)
+}
+
+func TestSwarmStartsOnlyOnce(t *testing.T) {
+	s := New(Template{UsernamePattern: "Bot%d", Count: 1, Dial: newFakeServer().Dial})
+	panics := make(chan bool, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			defer func() { panics <- recover() != nil }()
+			s.Start()
+		}()
+	}
+	Expect(t, <-panics != <-panics, ToBeTrue)
+	s.Stop()
+}
@@ -59,3 +59,40 @@ type ChunkColumn struct {
 	Biome    [ChunkBiomeSize]byte // 16x16 of the biome for each X, Z coordinate
 	Metadata *ChunkColumnMetadata
 }
+
+// Returns the index of a block in a chunk's stores. Each coordinate is
+// relative to the chunk (0 - 15).
+func BlockIndex(x, y, z int) int {
+	return y<<8 | z<<4 | x
+}
+
+// Returns the value at the given index of a half-byte store, such as
+// Chunk.Metadata.
+func Nibble(data []byte, index int) byte {
+	b := data[index/halfDataDivisor]
+	if index%halfDataDivisor == 0 {
+		return b & LowBits
+	}
+	return (b & HighBits) >> 4
+}
+
+// Changes the value at the given index of a half-byte store.
+func SetNibble(data []byte, index int, value byte) {
+	i := index / halfDataDivisor
+	if index%halfDataDivisor == 0 {
+		data[i] = (data[i] & HighBits) | (value & LowBits)
+	} else {
+		data[i] = (data[i] & LowBits) | (value << 4)
+	}
+}
+
+// Returns true if the server sent the chunk at the given index (0 is
+// the bottom). Missing chunks are empty.
+func (c *ChunkColumn) HasChunk(i int) bool {
+	return c.Metadata != nil && c.Metadata.PrimaryBitmap&(1<<uint(i)) != 0
+}
+
+// Returns the biome of the given column relative coordinates (0 - 15).
+func (c *ChunkColumn) BiomeAt(x, z int) byte {
+	return c.Biome[z<<4|x]
+}
@@ -0,0 +1,26 @@
+package smpm
+
+import (
+	. "github.com/jeffh/goexpect"
+	"testing"
+)
+
+func TestNibblesArePackedLowBitsFirst(t *testing.T) {
+	it := NewIt(t)
+	data := []byte{0x21, 0x43}
+	it.Expects(Nibble(data, 0), ToEqual, byte(1))
+	it.Expects(Nibble(data, 1), ToEqual, byte(2))
+	it.Expects(Nibble(data, 3), ToEqual, byte(4))
+
+	SetNibble(data, 1, 0xf)
+	SetNibble(data, 2, 0x7)
+	it.Expects(data, ToEqual, []byte{0xf1, 0x47})
+}
+
+func TestBlockIndexOrdersByYThenZThenX(t *testing.T) {
+	it := NewIt(t)
+	it.Expects(BlockIndex(1, 0, 0), ToEqual, 1)
+	it.Expects(BlockIndex(0, 0, 1), ToEqual, 16)
+	it.Expects(BlockIndex(0, 1, 0), ToEqual, 256)
+	it.Expects(BlockIndex(15, 15, 15), ToEqual, ChunkSize-1)
+}